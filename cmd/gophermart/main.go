@@ -9,53 +9,80 @@ import (
 	"os"
 	"os/signal"
 	"sync"
-	"time"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/NStegura/gophermart/internal/monitoring/logger"
 	"github.com/NStegura/gophermart/internal/monitoring/tracer"
 
 	"github.com/NStegura/gophermart/internal/clients/accrual"
 	"github.com/NStegura/gophermart/internal/services/jobs/accrualsync"
+	"github.com/NStegura/gophermart/internal/services/jobs/eventretention"
+	"github.com/NStegura/gophermart/internal/services/jobs/holdexpiry"
+	"github.com/NStegura/gophermart/internal/services/jobs/outboxrelay"
+	"github.com/NStegura/gophermart/internal/services/jobs/reconciliation"
+	"github.com/NStegura/gophermart/internal/services/jobs/tierrecalc"
+	"github.com/NStegura/gophermart/internal/services/jobs/webhookdelivery"
 
 	"github.com/NStegura/gophermart/internal/app/gophermartapi"
+	"github.com/NStegura/gophermart/internal/app/gophermartgrpc"
+	"github.com/NStegura/gophermart/internal/config"
 	"github.com/NStegura/gophermart/internal/repo"
 	"github.com/NStegura/gophermart/internal/services/auth"
 	"github.com/NStegura/gophermart/internal/services/business"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+	"github.com/NStegura/gophermart/internal/services/userevents"
+	"github.com/NStegura/gophermart/internal/services/withdrawrules"
+	"github.com/NStegura/gophermart/internal/storage"
+	"github.com/NStegura/gophermart/internal/storage/memory"
 )
 
 const (
-	timeoutShutdown = time.Second * 10
-	rateLimit       = 5
-	frequency       = time.Duration(15) * time.Second
-	serviceName     = "Gophermart"
+	serviceName = "Gophermart"
 )
 
+// newStorage opens the storage of the configured driver, Postgres is migrated first.
+func newStorage(ctx context.Context, cfg *config.Config, logg *logrus.Logger) (storage.Storage, error) {
+	if cfg.Database.Driver == config.DatabaseDriverMemory {
+		logg.Warn("the data is kept in memory, it is lost on shutdown")
+		return memory.New(logg), nil
+	}
+
+	db, err := repo.New(
+		ctx,
+		cfg.Database.URI,
+		int32(cfg.Database.MaxConns),
+		logg,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repo: %w", err)
+	}
+	return db, nil
+}
+
 func runApp() error {
 	ctx, cancelCtx := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancelCtx()
 
-	config := gophermartapi.NewConfig()
-	err := config.ParseFlags()
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	logg, err := logger.Init(config.LogLevel)
+	logg, err := logger.Init(cfg.LogLevel)
 	if err != nil {
 		return fmt.Errorf("failed to init logger: %w", err)
 	}
 
-	_, err = tracer.Init(ctx, config.TracerURL, serviceName)
+	_, err = tracer.Init(ctx, cfg.TracerURL, serviceName)
 	if err != nil {
 		return fmt.Errorf("failed to init tracer: %w", err)
 	}
 
-	db, err := repo.New(
-		ctx,
-		config.DatabaseDSN,
-		logg,
-	)
+	db, err := newStorage(ctx, cfg, logg)
 	if err != nil {
-		return fmt.Errorf("failed to create repo: %w", err)
+		return err
 	}
 
 	wg := &sync.WaitGroup{}
@@ -72,26 +99,143 @@ func runApp() error {
 		db.Shutdown(ctx)
 	}()
 
+	eventsHub := userevents.New(cfg.Events.Retry, db, logg)
+	publisher := events.NewOutbox()
+
+	bl := business.New(db, publisher, business.Config{
+		TierWindow:             cfg.Tiers.Window,
+		HoldTTL:                cfg.Holds.TTL,
+		TransferDailyLimit:     cfg.Transfers.DailyLimit,
+		TransferDailyCount:     cfg.Transfers.DailyCount,
+		ReferralMaxPerReferrer: cfg.Referrals.MaxPerReferrer,
+		WithdrawLimits: withdrawrules.Limits{
+			MinSum:      cfg.Withdrawals.MinSum,
+			MaxSum:      cfg.Withdrawals.MaxSum,
+			DailyCap:    cfg.Withdrawals.DailyCap,
+			MonthlyCap:  cfg.Withdrawals.MonthlyCap,
+			HourlyCount: cfg.Withdrawals.HourlyCount,
+			CoolingOff:  cfg.Withdrawals.CoolingOff,
+		},
+	}, logg)
+	authService := auth.New(cfg.Auth.SecretKey, cfg.Auth.VerificationKeys, cfg.Auth.TokenTTL, cfg.Auth.BcryptCost, logg)
+
+	var limiter gophermartapi.RateLimiter = ratelimit.NewMemory()
+	if cfg.HTTP.RateLimit.Store == config.RateLimitStorePostgres {
+		limiter = ratelimit.NewPostgres(db, logg)
+	}
+
+	trustedProxies, err := cfg.HTTP.Proxies()
+	if err != nil {
+		return fmt.Errorf("failed to parse trusted proxies: %w", err)
+	}
 	server := gophermartapi.New(
-		config.RunAddress,
-		business.New(db, logg),
-		auth.New(config.SecretKey, logg),
+		gophermartapi.Config{
+			Address:         cfg.HTTP.Address,
+			AdminKey:        cfg.HTTP.AdminKey,
+			RespTimeout:     cfg.HTTP.ResponseTimeout,
+			CompressMinSize: cfg.HTTP.CompressMinSize,
+			RateLimits: gophermartapi.RateLimits{
+				Auth:   cfg.HTTP.RateLimit.Auth,
+				API:    cfg.HTTP.RateLimit.API,
+				Orders: cfg.HTTP.RateLimit.Orders,
+			},
+			TrustedProxies: trustedProxies,
+		},
+		bl,
+		authService,
+		eventsHub,
+		limiter,
+		logg,
+	)
+
+	grpcServer := gophermartgrpc.New(
+		cfg.GRPC.Address,
+		bl,
+		authService,
+		limiter,
+		cfg.HTTP.RateLimit.Auth,
 		logg,
 	)
 
-	accrualCli, err := accrual.New(config.AccrualAddr, logg)
+	wg.Add(1)
+	go func() {
+		defer logg.Info("closed grpc server")
+		defer wg.Done()
+		<-ctx.Done()
+
+		grpcServer.Stop()
+	}()
+
+	accrualCli, err := accrual.New(cfg.Accrual.Address, logg)
 	if err != nil {
 		return fmt.Errorf("failed to init accrualCli: %w", err)
 	}
 
 	accrualJob := accrualsync.New(
-		frequency,
-		rateLimit,
+		cfg.Accrual.SyncFrequency,
+		cfg.Accrual.SyncWorkers,
+		accrualsync.ReferralBonus{Referrer: cfg.Referrals.ReferrerBonus, Referee: cfg.Referrals.RefereeBonus},
 		db,
+		publisher,
 		accrualCli,
 		logg,
 	)
 
+	tierJob := tierrecalc.New(
+		cfg.Tiers.RecalcFrequency,
+		cfg.Tiers.Window,
+		db,
+		logg,
+	)
+
+	holdJob := holdexpiry.New(
+		cfg.Holds.ExpiryFrequency,
+		db,
+		logg,
+	)
+
+	webhookJob := webhookdelivery.New(
+		cfg.Webhooks.Frequency,
+		cfg.Webhooks.Timeout,
+		db,
+		logg,
+	)
+
+	sinks := []events.Sink{events.NewLogSink(logg)}
+	if cfg.Events.File != "" {
+		sinks = append(sinks, events.NewFileSink(cfg.Events.File))
+	}
+	if cfg.Events.URL != "" {
+		sinks = append(sinks, events.NewHTTPSink(cfg.Events.URL, cfg.Events.Timeout))
+	}
+	outboxJob := outboxrelay.New(
+		cfg.Outbox.Frequency,
+		cfg.Outbox.Retention,
+		cfg.Events.Timeout,
+		sinks,
+		db,
+		logg,
+	)
+	eventRetentionJob := eventretention.New(
+		cfg.Events.CleanupFrequency,
+		cfg.Events.Retention,
+		db,
+		logg,
+	)
+	reconciliationJob := reconciliation.New(
+		cfg.Reconciliation.Frequency,
+		cfg.Reconciliation.Mode,
+		cfg.Reconciliation.Tolerance,
+		db,
+		logg,
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reloadOnHangup(ctx, logg, authService, accrualJob)
+	}()
+
 	componentsErrs := make(chan error, 1)
 	go func(errs chan<- error) {
 		if err = server.Start(); err != nil {
@@ -102,12 +246,60 @@ func runApp() error {
 		}
 	}(componentsErrs)
 
+	go func(errs chan<- error) {
+		if err = grpcServer.Start(); err != nil {
+			errs <- fmt.Errorf("grpc server has failed: %w", err)
+		}
+	}(componentsErrs)
+
 	go func(errs chan<- error) {
 		if err = accrualJob.Start(ctx); err != nil {
 			errs <- fmt.Errorf("accrualJob has failed: %w", err)
 		}
 	}(componentsErrs)
 
+	go func(errs chan<- error) {
+		if err = tierJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("tierJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = holdJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("holdJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = webhookJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("webhookJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = outboxJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("outboxJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = eventRetentionJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("eventRetentionJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = reconciliationJob.Start(ctx); err != nil {
+			errs <- fmt.Errorf("reconciliationJob has failed: %w", err)
+		}
+	}(componentsErrs)
+
+	go func(errs chan<- error) {
+		if err = eventsHub.Start(ctx); err != nil {
+			errs <- fmt.Errorf("eventsHub has failed: %w", err)
+		}
+	}(componentsErrs)
+
 	select {
 	case <-ctx.Done():
 	case err := <-componentsErrs:
@@ -116,7 +308,7 @@ func runApp() error {
 	}
 
 	go func() {
-		ctx, cancelCtx := context.WithTimeout(context.Background(), timeoutShutdown)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancelCtx()
 
 		<-ctx.Done()
@@ -126,6 +318,34 @@ func runApp() error {
 	return nil
 }
 
+// reloadOnHangup loads the config again on SIGHUP and applies its reloadable settings,
+// an invalid config is logged and the current settings are kept.
+func reloadOnHangup(ctx context.Context, logg *logrus.Logger, authService *auth.Service, accrualJob *accrualsync.Job) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		}
+
+		cfg, err := config.Load(os.Args[1:])
+		if err != nil {
+			logg.Errorf("failed to reload config, keeping the current one: %s", err)
+			continue
+		}
+		level, _ := logrus.ParseLevel(cfg.LogLevel) // validated by Load
+		logg.SetLevel(level)
+		accrualJob.SetSchedule(cfg.Accrual.SyncFrequency, cfg.Accrual.SyncWorkers)
+		authService.SetKeys(cfg.Auth.SecretKey, cfg.Auth.VerificationKeys)
+		logg.Infof("config reloaded: log level %s, accrual sync every %s by %d workers, %d verification keys",
+			level, cfg.Accrual.SyncFrequency, cfg.Accrual.SyncWorkers, len(cfg.Auth.VerificationKeys))
+	}
+}
+
 func main() {
 	if err := runApp(); err != nil {
 		log.Fatal(err)
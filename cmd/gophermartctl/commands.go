@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/utils"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/jobs/reconciliation"
+)
+
+const defaultRequeueAge = time.Hour
+
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("o", outputTable, "output, table or json")
+}
+
+// userFlags select the user by login or id.
+type userFlags struct {
+	login string
+	id    int64
+}
+
+func newUserFlags(fs *flag.FlagSet) *userFlags {
+	u := &userFlags{}
+	fs.StringVar(&u.login, "login", "", "user login")
+	fs.Int64Var(&u.id, "id", 0, "user id")
+	return u
+}
+
+func (u *userFlags) find(ctx context.Context, a *app, fs *flag.FlagSet) (domenModels.User, error) {
+	var (
+		user domenModels.User
+		err  error
+	)
+	switch {
+	case (u.login == "") == (u.id == 0):
+		fs.Usage()
+		return user, errUsage
+	case u.login != "":
+		user, err = a.business.GetUserByLogin(ctx, u.login)
+	default:
+		user, err = a.business.GetUserByID(ctx, u.id)
+	}
+	if err != nil {
+		return user, fmt.Errorf("failed to find user, %w", err)
+	}
+	return user, nil
+}
+
+func migrate(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if err := a.db.Migrate(ctx, fs.Arg(0), fs.Args()[1:]...); err != nil {
+		return fmt.Errorf("failed to run migrate %s, %w", fs.Arg(0), err)
+	}
+	return nil
+}
+
+func createUser(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	login := fs.String("login", "", "user login")
+	referralCode := fs.String("referral-code", "", "referral code of the referrer")
+	format := outputFlag(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *login == "" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	password, err := readPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if err = utils.CheckCredentials(*login, password); err != nil {
+		return fmt.Errorf("invalid credentials, %w", err)
+	}
+
+	hash, err := a.auth.GeneratePasswordHash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password, %w", err)
+	}
+	id, err := a.business.CreateUser(ctx, *login, hash, *referralCode)
+	if err != nil {
+		return fmt.Errorf("failed to create user, %w", err)
+	}
+	user, err := a.business.GetUserByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get created user, %w", err)
+	}
+
+	view := newUserView(user)
+	return output(os.Stdout, *format, view, view.table())
+}
+
+// readPassword reads the password from the first line of r, so it stays out of the arguments
+// and the shell history.
+func readPassword(r io.Reader) (string, error) {
+	password, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password, %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return "", errors.New("password must be passed on stdin")
+	}
+	return password, nil
+}
+
+func showUser(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	selector := newUserFlags(fs)
+	format := outputFlag(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	user, err := selector.find(ctx, a, fs)
+	if err != nil {
+		return err
+	}
+	orders, err := a.business.GetOrders(ctx, user.ID, domenModels.ListFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to get orders, %w", err)
+	}
+
+	result := struct {
+		User   userView    `json:"user"`
+		Orders []orderView `json:"orders"`
+	}{newUserView(user), newOrderViews(orders)}
+	return output(os.Stdout, *format, result, result.User.table(), ordersTable(result.Orders))
+}
+
+func resyncOrder(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	number := fs.String("order", "", "order number")
+	format := outputFlag(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+	orderID, err := strconv.ParseInt(*number, 10, 64)
+	if err != nil || !utils.Valid(orderID) {
+		return fmt.Errorf("order number %q is not valid", *number)
+	}
+
+	accrualOrder, err := a.accrualJob.SyncOrder(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to resync order, %w", err)
+	}
+
+	result := struct {
+		Number  string  `json:"number"`
+		Status  string  `json:"status"`
+		Accrual float64 `json:"accrual"`
+	}{*number, accrualOrder.Status, accrualOrder.Accrual}
+	return output(os.Stdout, *format, result, table{
+		header: []string{"NUMBER", "ACCRUAL STATUS", "ACCRUAL"},
+		rows:   [][]string{{result.Number, result.Status, formatPoints(result.Accrual)}},
+	})
+}
+
+func adjustBalance(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	selector := newUserFlags(fs)
+	amount := fs.Float64("amount", 0, "points to credit, negative to debit")
+	reason := fs.String("reason", "", "reason, shown in the user statement")
+	format := outputFlag(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *amount == 0 || *reason == "" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	user, err := selector.find(ctx, a, fs)
+	if err != nil {
+		return err
+	}
+	user, err = a.business.AdjustBalance(ctx, user.ID, *amount, *reason)
+	if err != nil {
+		return fmt.Errorf("failed to adjust balance, %w", err)
+	}
+
+	view := newUserView(user)
+	return output(os.Stdout, *format, view, view.table())
+}
+
+func requeueOrders(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	status := fs.String("status", "PROCESSING", "status of the stuck orders, PROCESSING, NEW or INVALID")
+	olderThan := fs.Duration("older-than", defaultRequeueAge, "time since the last accrual check")
+	format := outputFlag(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	orders, err := a.business.RequeueOrders(ctx, *status, *olderThan)
+	if err != nil {
+		return fmt.Errorf("failed to requeue orders, %w", err)
+	}
+
+	views := newOrderViews(orders)
+	return output(os.Stdout, *format, views, ordersTable(views))
+}
+
+func reconcile(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	mode := fs.String("mode", a.cfg.Reconciliation.Mode, "report, dry-run to show the corrections or apply to make them")
+	tolerance := fs.Float64("tolerance", a.cfg.Reconciliation.Tolerance, "ignored balance difference")
+	format := fs.String("o", outputJSON, "output, json or table")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkOutput(*format); err != nil {
+		return err
+	}
+
+	job := reconciliation.New(a.cfg.Reconciliation.Frequency, *mode, *tolerance, a.db, a.logger)
+	report, err := job.Reconcile(ctx, *mode)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile balances, %w", err)
+	}
+	tables := []table{mismatchesTable(report)}
+	if *mode != reconciliation.ModeReport {
+		tables = append(tables, correctionsTable(report))
+	}
+	if err = output(os.Stdout, *format, report, tables...); err != nil {
+		return err
+	}
+	// the exit code tells the scripts the balances are not fixed
+	for _, c := range report.Corrections {
+		if c.Error != "" {
+			return errors.New("failed to apply some corrections")
+		}
+	}
+	if *mode != reconciliation.ModeApply && len(report.Mismatches) > 0 {
+		return fmt.Errorf("found %d balance mismatches", len(report.Mismatches))
+	}
+	return nil
+}
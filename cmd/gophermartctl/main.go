@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/clients/accrual"
+	"github.com/NStegura/gophermart/internal/config"
+	"github.com/NStegura/gophermart/internal/monitoring/logger"
+	"github.com/NStegura/gophermart/internal/repo"
+	"github.com/NStegura/gophermart/internal/services/auth"
+	"github.com/NStegura/gophermart/internal/services/business"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/jobs/accrualsync"
+	"github.com/NStegura/gophermart/internal/services/withdrawrules"
+)
+
+// exitUsage is the exit code of bad arguments, like flag.Parse uses.
+const exitUsage = 2
+
+// errUsage is returned by the commands on bad arguments, the usage is already printed.
+var errUsage = errors.New("usage")
+
+// app is what the commands work with, it is configured like the server.
+type app struct {
+	db         *repo.DB
+	business   *business.Business
+	auth       *auth.Service
+	accrualJob *accrualsync.Job
+	cfg        *config.Config
+	logger     *logrus.Logger
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error
+}
+
+var commands = map[string]command{
+	"migrate": {
+		usage: "migrate up|down|status|version|redo|reset|up-to VERSION|down-to VERSION",
+		run:   migrate,
+	},
+	"create-user": {
+		usage: "create-user -login LOGIN [-referral-code CODE] [-o table|json] < PASSWORD, reads the password from stdin",
+		run:   createUser,
+	},
+	"user": {
+		usage: "user -login LOGIN | -id ID [-o table|json], the user balance and orders",
+		run:   showUser,
+	},
+	"resync-order": {
+		usage: "resync-order -order NUMBER [-o table|json], checks the order in the accrual system now",
+		run:   resyncOrder,
+	},
+	"adjust-balance": {
+		usage: "adjust-balance -login LOGIN | -id ID -amount AMOUNT -reason REASON [-o table|json]",
+		run:   adjustBalance,
+	},
+	"requeue-orders": {
+		usage: "requeue-orders [-status PROCESSING|NEW|INVALID] [-older-than 1h] [-o table|json]",
+		run:   requeueOrders,
+	},
+	"reconcile": {
+		usage: "reconcile [-mode report|dry-run|apply] [-tolerance POINTS] [-o json|table], checks balances by the ledger",
+		run:   reconcile,
+	},
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("usage: gophermartctl [gophermart flags] COMMAND [command flags]\n\n")
+	b.WriteString("The config is read like by gophermart: -c file, env and flags.\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+func runApp() error {
+	ctx, cancelCtx := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelCtx()
+
+	cfg, args, err := config.LoadArgs(os.Args[1:])
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(args) == 0 {
+		usage()
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage()
+		return errUsage
+	}
+
+	logg, err := logger.Init(cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+	// the command output goes to stdout, the log is kept for problems
+	if logg.GetLevel() > logrus.WarnLevel {
+		logg.SetLevel(logrus.WarnLevel)
+	}
+
+	a, err := newApp(ctx, cfg, logg)
+	if err != nil {
+		return err
+	}
+	defer a.db.Shutdown(ctx)
+
+	return cmd.run(ctx, a, newFlagSet(args[0], cmd.usage), args[1:])
+}
+
+func newApp(ctx context.Context, cfg *config.Config, logg *logrus.Logger) (*app, error) {
+	if cfg.Database.Driver != config.DatabaseDriverPostgres {
+		return nil, errors.New("gophermartctl works with the postgres database only")
+	}
+	db, err := repo.Connect(ctx, cfg.Database.URI, int32(cfg.Database.MaxConns), logg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repo: %w", err)
+	}
+	publisher := events.NewOutbox()
+
+	accrualCli, err := accrual.New(cfg.Accrual.Address, logg)
+	if err != nil {
+		db.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to init accrualCli: %w", err)
+	}
+
+	return &app{
+		db: db,
+		business: business.New(db, publisher, business.Config{
+			TierWindow:             cfg.Tiers.Window,
+			HoldTTL:                cfg.Holds.TTL,
+			TransferDailyLimit:     cfg.Transfers.DailyLimit,
+			TransferDailyCount:     cfg.Transfers.DailyCount,
+			ReferralMaxPerReferrer: cfg.Referrals.MaxPerReferrer,
+			WithdrawLimits: withdrawrules.Limits{
+				MinSum:      cfg.Withdrawals.MinSum,
+				MaxSum:      cfg.Withdrawals.MaxSum,
+				DailyCap:    cfg.Withdrawals.DailyCap,
+				MonthlyCap:  cfg.Withdrawals.MonthlyCap,
+				HourlyCount: cfg.Withdrawals.HourlyCount,
+				CoolingOff:  cfg.Withdrawals.CoolingOff,
+			},
+		}, logg),
+		auth: auth.New(cfg.Auth.SecretKey, cfg.Auth.VerificationKeys, cfg.Auth.TokenTTL, cfg.Auth.BcryptCost, logg),
+		accrualJob: accrualsync.New(
+			cfg.Accrual.SyncFrequency,
+			cfg.Accrual.SyncWorkers,
+			accrualsync.ReferralBonus{Referrer: cfg.Referrals.ReferrerBonus, Referee: cfg.Referrals.RefereeBonus},
+			db,
+			publisher,
+			accrualCli,
+			logg,
+		),
+		cfg:    cfg,
+		logger: logg,
+	}, nil
+}
+
+// newFlagSet is the flag set of a command, its usage is printed on a parse error.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gophermartctl %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func main() {
+	if err := runApp(); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitUsage)
+		}
+		log.Fatal(err)
+	}
+}
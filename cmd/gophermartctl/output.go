@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/jobs/reconciliation"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+
+	tablePadding    = 2
+	pointsPrecision = 2
+)
+
+// table is the table view of a command result, JSON prints the result itself.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', 0)
+	if _, err := fmt.Fprintln(tw, strings.Join(t.header, "\t")); err != nil {
+		return fmt.Errorf("failed to write table, %w", err)
+	}
+	for _, row := range t.rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return fmt.Errorf("failed to write table, %w", err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table, %w", err)
+	}
+	return nil
+}
+
+// output writes the result as JSON or as the tables separated by blank lines.
+func output(w io.Writer, format string, result any, tables ...table) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("failed to write json, %w", err)
+		}
+		return nil
+	}
+	for i, t := range tables {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return fmt.Errorf("failed to write table, %w", err)
+			}
+		}
+		if err := t.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkOutput(format string) error {
+	if format != outputTable && format != outputJSON {
+		return fmt.Errorf("output must be %s or %s", outputTable, outputJSON)
+	}
+	return nil
+}
+
+type userView struct {
+	ID           int64     `json:"id"`
+	Login        string    `json:"login"`
+	Balance      float64   `json:"balance"`
+	Withdrawn    float64   `json:"withdrawn"`
+	Held         float64   `json:"held"`
+	Tier         string    `json:"tier"`
+	Blocked      bool      `json:"blocked"`
+	ReferralCode string    `json:"referral_code"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newUserView(u domenModels.User) userView {
+	return userView{
+		ID:           u.ID,
+		Login:        u.Login,
+		Balance:      u.Balance,
+		Withdrawn:    u.Withdrawn,
+		Held:         u.Held,
+		Tier:         u.Tier,
+		Blocked:      u.Blocked,
+		ReferralCode: u.ReferralCode,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func (u userView) table() table {
+	return table{
+		header: []string{"ID", "LOGIN", "BALANCE", "WITHDRAWN", "HELD", "TIER", "BLOCKED", "CREATED"},
+		rows: [][]string{{
+			strconv.FormatInt(u.ID, 10),
+			u.Login,
+			formatPoints(u.Balance),
+			formatPoints(u.Withdrawn),
+			formatPoints(u.Held),
+			u.Tier,
+			strconv.FormatBool(u.Blocked),
+			u.CreatedAt.Format(time.RFC3339),
+		}},
+	}
+}
+
+type orderView struct {
+	Number      string     `json:"number"`
+	Status      string     `json:"status"`
+	Accrual     float64    `json:"accrual"`
+	UploadedAt  time.Time  `json:"uploaded_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	CheckedAt   time.Time  `json:"checked_at"`
+}
+
+func newOrderViews(orders []domenModels.Order) []orderView {
+	views := make([]orderView, 0, len(orders))
+	for _, o := range orders {
+		views = append(views, orderView{
+			Number:      strconv.FormatInt(o.Number, 10),
+			Status:      o.Status,
+			Accrual:     o.Accrual,
+			UploadedAt:  o.UploadedAt,
+			ProcessedAt: o.ProcessedAt,
+			CheckedAt:   o.CheckedAt,
+		})
+	}
+	return views
+}
+
+func ordersTable(orders []orderView) table {
+	t := table{header: []string{"NUMBER", "STATUS", "ACCRUAL", "UPLOADED", "PROCESSED", "CHECKED"}}
+	for _, o := range orders {
+		processedAt := "-"
+		if o.ProcessedAt != nil {
+			processedAt = o.ProcessedAt.Format(time.RFC3339)
+		}
+		t.rows = append(t.rows, []string{
+			o.Number,
+			o.Status,
+			formatPoints(o.Accrual),
+			o.UploadedAt.Format(time.RFC3339),
+			processedAt,
+			o.CheckedAt.Format(time.RFC3339),
+		})
+	}
+	return t
+}
+
+func formatPoints(v float64) string {
+	return strconv.FormatFloat(v, 'f', pointsPrecision, 64)
+}
+
+func mismatchesTable(r reconciliation.Report) table {
+	t := table{header: []string{"USER ID", "LOGIN", "BALANCE", "EXPECTED", "DIFF", "WITHDRAWN", "EXPECTED", "DIFF"}}
+	for _, m := range r.Mismatches {
+		t.rows = append(t.rows, []string{
+			strconv.FormatInt(m.UserID, 10),
+			m.Login,
+			formatPoints(m.Balance),
+			formatPoints(m.ExpectedBalance),
+			formatPoints(m.BalanceDiff),
+			formatPoints(m.Withdrawn),
+			formatPoints(m.ExpectedWithdrawn),
+			formatPoints(m.WithdrawnDiff),
+		})
+	}
+	return t
+}
+
+func correctionsTable(r reconciliation.Report) table {
+	t := table{header: []string{"USER ID", "BALANCE", "", "WITHDRAWN", "", "APPLIED", "ERROR"}}
+	for _, c := range r.Corrections {
+		t.rows = append(t.rows, []string{
+			strconv.FormatInt(c.UserID, 10),
+			formatPoints(c.BalanceFrom),
+			"-> " + formatPoints(c.BalanceTo),
+			formatPoints(c.WithdrawnFrom),
+			"-> " + formatPoints(c.WithdrawnTo),
+			strconv.FormatBool(c.Applied),
+			c.Error,
+		})
+	}
+	return t
+}
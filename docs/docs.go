@@ -15,325 +15,1661 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/user/balance": {
+        "/api/admin/campaigns": {
             "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "AdminKeyAuth": []
                     }
                 ],
-                "description": "get user balance",
+                "description": "list all promotional campaigns",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Get balance",
+                "summary": "Get campaigns",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Balance"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Campaign"
+                            }
                         }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
-            }
-        },
-        "/api/user/balance/withdraw": {
+            },
             "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "AdminKeyAuth": []
                     }
                 ],
-                "description": "create user withdraw",
+                "description": "create a promotional campaign, bonuses apply to orders uploaded during the campaign",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Create withdraw",
+                "summary": "Create campaign",
                 "parameters": [
                     {
-                        "description": "User withdraw data",
+                        "description": "Campaign",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawIn"
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Campaign"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Campaign"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
-                    "402": {
-                        "description": "Payment Required"
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "422": {
-                        "description": "Unprocessable Entity"
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/user/login": {
+        "/api/admin/campaigns/{id}/end": {
             "post": {
-                "description": "login",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
                 ],
+                "description": "stop the campaign now, orders uploaded afterwards get no campaign bonus",
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login",
+                "summary": "End campaign",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.User"
-                        }
+                        "type": "integer",
+                        "description": "Campaign id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "headers": {
-                            "Authorization": {
-                                "type": "string",
-                                "description": "Use this header in other endpoints"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/user/orders": {
+        "/api/user/balance": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get order list by user",
+                "description": "get user balance and loyalty tier",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Get order list",
+                "summary": "Get balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Order"
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Balance"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user data"
                             }
                         }
                     },
-                    "204": {
-                        "description": "No Content"
+                    "304": {
+                        "description": "Not Modified"
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/api/user/balance/holds": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "\"register\" user order",
+                "description": "reserve points for an order until the hold is captured or released",
                 "consumes": [
-                    "text/plain"
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Create order",
+                "summary": "Create hold",
                 "parameters": [
                     {
-                        "description": "Order id",
-                        "name": "string",
+                        "description": "Order and sum to hold",
+                        "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawIn"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "202": {
-                        "description": "Accepted"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.HoldOut"
+                        }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
-                    "409": {
-                        "description": "Conflict"
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "422": {
-                        "description": "Unprocessable Entity"
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/user/register": {
+        "/api/user/balance/holds/{id}/capture": {
             "post": {
-                "description": "register",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "withdraw the held points",
                 "tags": [
-                    "auth"
+                    "user"
                 ],
-                "summary": "Register",
+                "summary": "Capture hold",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.User"
-                        }
+                        "type": "integer",
+                        "description": "Hold id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "headers": {
-                            "Authorization": {
-                                "type": "string",
-                                "description": "Use this header in other endpoints"
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "409": {
-                        "description": "Conflict"
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/api/user/withdrawals": {
-            "get": {
+        "/api/user/balance/holds/{id}/release": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get user withdraw list",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "return the held points to the available balance",
                 "tags": [
                     "user"
                 ],
-                "summary": "Get withdraw list",
+                "summary": "Release hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawOut"
-                            }
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized"
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/ping": {
-            "get": {
-                "description": "check service",
+        "/api/user/balance/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "transfer points to another user",
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "tech"
+                    "user"
+                ],
+                "summary": "Create transfer",
+                "parameters": [
+                    {
+                        "description": "Recipient login and sum",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.TransferIn"
+                        }
+                    }
                 ],
-                "summary": "Get ping",
                 "responses": {
                     "200": {
                         "description": "OK"
-                    }
-                }
-            }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/balance/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create user withdraw",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create withdraw",
+                "parameters": [
+                    {
+                        "description": "User withdraw data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawIn"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/login": {
+            "post": {
+                "description": "login",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "Authorization": {
+                                "type": "string",
+                                "description": "Use this header in other endpoints"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/orders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get order list by user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get order list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated NEW, PROCESSING, INVALID, PROCESSED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Uploaded at or after, RFC 3339 time or date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, a date includes the whole day",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimal accrual",
+                        "name": "min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximal accrual",
+                        "name": "max",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by upload time",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Order"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the user data"
+                            }
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "\"register\" user order",
+                "consumes": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create order",
+                "parameters": [
+                    {
+                        "description": "Order id",
+                        "name": "string",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/orders/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "upload up to 1000 orders at once, as a JSON array or a text/plain list with one number per line\nevery number gets its own result: ACCEPTED, ALREADY_UPLOADED, CONFLICT (another user) or INVALID",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create orders batch",
+                "parameters": [
+                    {
+                        "description": "Order numbers",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/orders/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "server-sent events on order status, accrual and balance changes\nevent \"order\": {\"number\", \"status\", \"accrual\"}\nevent \"balance\": {\"current\", \"withdrawn\", \"held\", \"available\"}\nafter a reconnect with Last-Event-ID an event committed late may be sent again, dedupe by id",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Stream user events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/api/user/orders/{number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the user order with every status transition recorded by the accrual sync",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/referrals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get user referral code and invited users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get referrals",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Referrals"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/register": {
+            "post": {
+                "description": "register",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "headers": {
+                            "Authorization": {
+                                "type": "string",
+                                "description": "Use this header in other endpoints"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/statement": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "accruals, bonuses, withdrawals and transfers in [from, to) with a running balance\nfrom and to are RFC 3339 times or dates, a date in to includes the whole day\ncsv columns: at, kind, order, source, amount, balance, the first and the last rows are\nOPENING and CLOSING balances",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period start, the beginning of history by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period end, now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Statement"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "list registered webhooks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "register url for events: order.processed, order.invalid, withdrawal.created, balance.changed\nevery delivery is signed: X-Gophermart-Signature = \"sha256=\" + hex(HMAC-SHA256(secret,\nX-Gophermart-Timestamp + \".\" + body)), the secret is returned only here\nthe url host must resolve to public addresses, private and loopback ones are rejected with 422",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Url and events",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WebhookIn"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "stop sending events to the webhook, queued deliveries are dropped",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "latest 100 deliveries of the webhook, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/webhooks/{id}/deliveries/{deliveryID}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "queue the delivery again with a fresh attempts budget",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery id",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/withdrawals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get user withdraw list",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get withdraw list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Uploaded at or after, RFC 3339 time or date",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, a date includes the whole day",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimal sum",
+                        "name": "min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximal sum",
+                        "name": "max",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by creation time",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawOut"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ping": {
+            "get": {
+                "description": "check service",
+                "tags": [
+                    "tech"
+                ],
+                "summary": "Get ping",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
         }
     },
     "definitions": {
         "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Balance": {
             "type": "object",
             "properties": {
+                "available": {
+                    "type": "number"
+                },
                 "current": {
                     "type": "number"
                 },
+                "held": {
+                    "type": "number"
+                },
+                "tier": {
+                    "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Tier"
+                },
                 "withdrawn": {
                     "type": "number"
                 }
             }
         },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Campaign": {
+            "type": "object",
+            "required": [
+                "ends_at",
+                "kind",
+                "name",
+                "starts_at"
+            ],
+            "properties": {
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "MULTIPLIER",
+                        "FIXED"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "new_users_only": {
+                    "type": "boolean"
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "tiers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.HoldOut": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "sum": {
+                    "type": "number"
+                }
+            }
+        },
         "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Order": {
             "type": "object",
             "properties": {
                 "accrual": {
                     "type": "number"
                 },
+                "checked_at": {
+                    "type": "string"
+                },
                 "number": {
                     "type": "string",
                     "example": "0"
                 },
+                "processed_at": {
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
                 },
@@ -342,19 +1678,334 @@ const docTemplate = `{
                 }
             }
         },
-        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.User": {
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderDetail": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderStatusChange"
+                    }
+                },
+                "number": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "processed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderResult": {
+            "type": "object",
+            "properties": {
+                "number": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ACCEPTED",
+                        "ALREADY_UPLOADED",
+                        "CONFLICT",
+                        "INVALID"
+                    ]
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.OrderStatusChange": {
+            "type": "object",
+            "properties": {
+                "accrual": {
+                    "type": "number"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Referral": {
             "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "rewarded_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Referrals": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "referrals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.Referral"
+                    }
+                },
+                "rewarded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Statement": {
+            "type": "object",
+            "properties": {
+                "closing_balance": {
+                    "type": "number"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_NStegura_gophermart_internal_app_gophermartapi_models.StatementEntry"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.StatementEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "ACCRUAL",
+                        "BONUS",
+                        "WITHDRAWAL",
+                        "TRANSFER_IN",
+                        "TRANSFER_OUT",
+                        "ADJUSTMENT"
+                    ]
+                },
+                "order": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Tier": {
+            "type": "object",
+            "properties": {
+                "multiplier": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_threshold": {
+                    "type": "number"
+                },
+                "next_tier": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "number"
+                },
+                "volume": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.TransferIn": {
+            "type": "object",
+            "required": [
+                "login"
+            ],
             "properties": {
                 "login": {
                     "type": "string"
                 },
+                "sum": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.User": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3
+                },
                 "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                },
+                "referral_code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "QUEUED",
+                        "DELIVERED",
+                        "FAILED"
+                    ]
+                }
+            }
+        },
+        "github_com_NStegura_gophermart_internal_app_gophermartapi_models.WebhookIn": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
         "github_com_NStegura_gophermart_internal_app_gophermartapi_models.WithdrawIn": {
             "type": "object",
+            "required": [
+                "order"
+            ],
             "properties": {
                 "order": {
                     "type": "string"
@@ -380,6 +2031,11 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "AdminKeyAuth": {
+            "type": "apiKey",
+            "name": "X-Admin-Key",
+            "in": "header"
+        },
         "ApiKeyAuth": {
             "type": "apiKey",
             "name": "Authorization",
@@ -395,7 +2051,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Gophermart API",
-	Description:      "This is a Gophermart server.",
+	Description:      "This is a Gophermart server.\nErrors are returned as RFC 7807 application/problem+json.\nRequests over the rate limit get 429 with RateLimit-* and Retry-After headers.\nResponses are compressed by Accept-Encoding (gzip, zstd), so are request bodies by Content-Encoding.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,42 @@
+package gophermartapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// checkETag tags the response with the user version and answers 304 when the client already has it,
+// false means the response is written. The version is read before the data, so a concurrent change
+// can only leave the tag older than the body and cost the client one more full response.
+func (s *APIServer) checkETag(userID int64, w http.ResponseWriter, r *http.Request) bool {
+	version, err := s.business.GetUserVersion(r.Context(), userID)
+	if err != nil {
+		s.writeError(err, w, r)
+		return false
+	}
+
+	// weak, a body with only a newer checked_at is equivalent
+	etag := `W/"` + strconv.FormatInt(version, 10) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if etagMatch(r.Header.Values("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return false
+	}
+	return true
+}
+
+// etagMatch uses the weak comparison If-None-Match requires.
+func etagMatch(values []string, etag string) bool {
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+	}
+	return false
+}
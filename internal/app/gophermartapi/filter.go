@@ -0,0 +1,112 @@
+package gophermartapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+const (
+	sortAsc  = "asc"
+	sortDesc = "desc"
+)
+
+var orderStatuses = map[string]struct{}{
+	"NEW":        {},
+	"PROCESSING": {},
+	"INVALID":    {},
+	"PROCESSED":  {},
+}
+
+// parseListFilter reads status, from, to, min, max and sort query parameters,
+// the returned error is safe to show to the client.
+func parseListFilter(q url.Values, withStatus bool) (filter domenModels.ListFilter, err error) {
+	if status := q.Get("status"); status != "" {
+		if !withStatus {
+			return filter, errors.New("status filter is not supported")
+		}
+		for _, st := range strings.Split(status, ",") {
+			st = strings.ToUpper(strings.TrimSpace(st))
+			if _, ok := orderStatuses[st]; !ok {
+				return filter, errors.New("status must be one of NEW, PROCESSING, INVALID, PROCESSED")
+			}
+			filter.Statuses = append(filter.Statuses, st)
+		}
+	}
+
+	from, ok, err := parseTimeParam(q, "from", false)
+	if err != nil {
+		return filter, err
+	}
+	if ok {
+		filter.From = &from
+	}
+	to, ok, err := parseTimeParam(q, "to", true)
+	if err != nil {
+		return filter, err
+	}
+	if ok {
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return filter, errors.New("to must be after from")
+	}
+
+	minAmount, ok, err := parseAmountParam(q, "min")
+	if err != nil {
+		return filter, err
+	}
+	if ok {
+		filter.MinAmount = &minAmount
+	}
+	maxAmount, ok, err := parseAmountParam(q, "max")
+	if err != nil {
+		return filter, err
+	}
+	if ok {
+		filter.MaxAmount = &maxAmount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, errors.New("min must not be greater than max")
+	}
+
+	switch q.Get("sort") {
+	case "", sortAsc:
+	case sortDesc:
+		filter.Desc = true
+	default:
+		return filter, errors.New("sort must be asc or desc")
+	}
+	return filter, nil
+}
+
+// parseTimeParam reports false when the parameter is not set.
+func parseTimeParam(q url.Values, name string, endOfDay bool) (time.Time, bool, error) {
+	value := q.Get(name)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := parseStatementTime(value, time.Time{}, endOfDay)
+	if err != nil {
+		return t, false, fmt.Errorf("%s must be an RFC 3339 time or a date", name)
+	}
+	return t, true, nil
+}
+
+// parseAmountParam reports false when the parameter is not set.
+func parseAmountParam(q url.Values, name string) (float64, bool, error) {
+	value := q.Get(name)
+	if value == "" {
+		return 0, false, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		return 0, false, fmt.Errorf("%s must be a non-negative number", name)
+	}
+	return amount, true, nil
+}
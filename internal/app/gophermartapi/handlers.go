@@ -1,12 +1,14 @@
 package gophermartapi
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"strconv"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/NStegura/gophermart/internal/app/gophermartapi/utils"
 	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
 
@@ -14,10 +16,6 @@ import (
 	"github.com/NStegura/gophermart/internal/customerrors"
 )
 
-const (
-	complexityAlgorithm int = 14
-)
-
 // register godoc
 //
 //	@Summary		Register
@@ -27,41 +25,35 @@ const (
 //	@Param			data	body	models.User	true	"User data"
 //	@Success		200
 //	@Header			200	{string}	Authorization	"Use this header in other endpoints"
-//	@Failure		409
-//	@Failure		400
-//	@Failure		500
+//	@Failure		409	{object}	models.Problem
+//	@Failure		400	{object}	models.Problem
+//	@Failure		413	{object}	models.Problem
+//	@Failure		422	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Router			/api/user/register [post]
 func (s *APIServer) register() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var inputUser models.User
 		var token string
 
-		if err := json.NewDecoder(r.Body).Decode(&inputUser); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !s.decodeJSON(&inputUser, w, r) || !s.validateStruct(inputUser, w, r) {
 			return
 		}
 
-		newPass, err := s.auth.GeneratePasswordHash(inputUser.Password, complexityAlgorithm)
+		newPass, err := s.auth.GeneratePasswordHash(inputUser.Password)
 		if err != nil {
-			s.logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
 			return
 		}
 
-		uID, err := s.business.CreateUser(r.Context(), inputUser.Login, newPass)
+		uID, err := s.business.CreateUser(r.Context(), inputUser.Login, newPass, inputUser.ReferralCode)
 		if err != nil {
-			if errors.Is(err, customerrors.ErrAlreadyExists) {
-				w.WriteHeader(http.StatusConflict)
-				return
-			}
-			s.logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
 			return
 		}
 		token, err = s.auth.GenerateToken(uID)
 		if err != nil {
-			s.logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
 			return
 		}
 		w.Header().Set("Authorization", token)
@@ -78,36 +70,33 @@ func (s *APIServer) register() http.HandlerFunc {
 //	@Param			data	body	models.User	true	"User data"
 //	@Success		200
 //	@Header			200	{string}	Authorization	"Use this header in other endpoints"
-//	@Failure		400
-//	@Failure		401
-//	@Failure		500
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		413	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Router			/api/user/login [post]
 func (s *APIServer) login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var inputUser models.User
 		var token string
 
-		if err := json.NewDecoder(r.Body).Decode(&inputUser); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !s.decodeJSON(&inputUser, w, r) {
 			return
 		}
 
 		dbUser, err := s.business.GetUserByLogin(r.Context(), inputUser.Login)
-		if err != nil {
-			if errors.Is(err, errors.New("doesnt exist")) {
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
+		if err != nil && !errors.Is(err, customerrors.ErrNotFound) {
+			s.writeError(err, w, r)
+			return
 		}
 
-		if !s.auth.CheckPasswordHash(inputUser.Password, dbUser.Password) {
-			w.WriteHeader(http.StatusUnauthorized)
+		if err != nil || !s.auth.CheckPasswordHash(inputUser.Password, dbUser.Password) {
+			s.writeProblem(problemInvalidCredentials, "", w, r)
 			return
 		}
 		token, err = s.auth.GenerateToken(dbUser.ID)
 		if err != nil {
-			s.logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
 			return
 		}
 		w.Header().Set("Authorization", token)
@@ -124,11 +113,12 @@ func (s *APIServer) login() http.HandlerFunc {
 //	@Param			string	body	string	true	"Order id"
 //	@Success		200
 //	@Success		202
-//	@Failure		400
-//	@Failure		401
-//	@Failure		409
-//	@Failure		422
-//	@Failure		500
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		409	{object}	models.Problem
+//	@Failure		413	{object}	models.Problem
+//	@Failure		422	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/api/user/orders [post]
 func (s *APIServer) createOrder() http.HandlerFunc {
@@ -140,14 +130,19 @@ func (s *APIServer) createOrder() http.HandlerFunc {
 
 		userID, err := s.getUserID(r.Context())
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "", w, r)
 			return
 		}
 
-		data, err = io.ReadAll(r.Body)
+		data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				s.writeProblem(problemBodyTooLarge, "", w, r)
+				return
+			}
 			s.logger.Error(err)
+			s.writeProblem(problemBadRequest, "failed to read request body", w, r)
 			return
 		}
 		defer func() {
@@ -156,28 +151,22 @@ func (s *APIServer) createOrder() http.HandlerFunc {
 
 		orderUID, err = strconv.ParseInt(string(data), 10, 64)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			s.writeProblem(problemBadRequest, "order number must contain only digits", w, r)
 			return
 		}
 
 		if !utils.Valid(orderUID) {
-			http.Error(w, "invalid order format", http.StatusUnprocessableEntity)
+			s.writeProblem(problemInvalidOrderNumber, "", w, r)
 			return
 		}
 
 		if err = s.business.CreateOrder(r.Context(), userID, orderUID); err != nil {
-			switch {
-			case errors.Is(err, customerrors.ErrCurrUserUploaded):
+			if errors.Is(err, customerrors.ErrCurrUserUploaded) {
 				w.WriteHeader(http.StatusOK)
 				return
-			case errors.Is(err, customerrors.ErrAnotherUserUploaded):
-				w.WriteHeader(http.StatusConflict)
-				return
-			default:
-				s.logger.Error(err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
 			}
+			s.writeError(err, w, r)
+			return
 		}
 		w.WriteHeader(http.StatusAccepted)
 	}
@@ -189,10 +178,20 @@ func (s *APIServer) createOrder() http.HandlerFunc {
 //	@Description	get order list by user
 //	@Tags			user
 //	@Produce		json
-//	@Success		200	{array}	models.Order
+//	@Param			status			query		string	false	"Comma separated NEW, PROCESSING, INVALID, PROCESSED"
+//	@Param			from			query		string	false	"Uploaded at or after, RFC 3339 time or date"
+//	@Param			to				query		string	false	"Created before, a date includes the whole day"
+//	@Param			min				query		number	false	"Minimal accrual"
+//	@Param			max				query		number	false	"Maximal accrual"
+//	@Param			sort			query		string	false	"Sort by upload time"	Enums(asc, desc)
+//	@Param			If-None-Match	header		string	false	"ETag of a previous response"
+//	@Success		200				{array}		models.Order
+//	@Header			200				{string}	ETag	"Version of the user data"
+//	@Success		304
 //	@Failure		204
-//	@Failure		401
-//	@Failure		500
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/api/user/orders [get]
 func (s *APIServer) getOrderList() http.HandlerFunc {
@@ -202,13 +201,22 @@ func (s *APIServer) getOrderList() http.HandlerFunc {
 
 		userID, err := s.getUserID(r.Context())
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "", w, r)
 			return
 		}
 
-		domenOrders, err = s.business.GetOrders(r.Context(), userID)
+		filter, err := parseListFilter(r.URL.Query(), true)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeProblem(problemBadRequest, err.Error(), w, r)
+			return
+		}
+		if !s.checkETag(userID, w, r) {
+			return
+		}
+
+		domenOrders, err = s.business.GetOrders(r.Context(), userID, filter)
+		if err != nil {
+			s.writeError(err, w, r)
 			return
 		}
 
@@ -227,33 +235,52 @@ func (s *APIServer) getOrderList() http.HandlerFunc {
 // getBalance godoc
 //
 //	@Summary		Get balance
-//	@Description	get user balance
+//	@Description	get user balance and loyalty tier
 //	@Tags			user
 //	@Produce		json
-//	@Success		200	{object}	models.Balance
-//	@Failure		401
-//	@Failure		500
+//	@Param			If-None-Match	header		string	false	"ETag of a previous response"
+//	@Success		200				{object}	models.Balance
+//	@Header			200				{string}	ETag	"Version of the user data"
+//	@Success		304
+//	@Failure		401	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/api/user/balance [get]
 func (s *APIServer) getBalance() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var domenUser domenModels.User
+		var (
+			domenUser domenModels.User
+			domenTier domenModels.Tier
+		)
 
 		userID, err := s.getUserID(r.Context())
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+		if !s.checkETag(userID, w, r) {
 			return
 		}
 
 		domenUser, err = s.business.GetUserByID(r.Context(), userID)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
+			return
+		}
+
+		domenTier, err = s.business.GetUserTier(r.Context(), userID)
+		if err != nil {
+			s.writeError(err, w, r)
 			return
 		}
+		tier := models.Tier(domenTier)
 
 		s.writeJSONResp(models.Balance{
 			Current:   domenUser.Balance,
 			Withdrawn: domenUser.Withdrawn,
+			Held:      domenUser.Held,
+			Available: domenUser.Balance - domenUser.Held,
+			Tier:      &tier,
 		}, w)
 	}
 }
@@ -266,10 +293,14 @@ func (s *APIServer) getBalance() http.HandlerFunc {
 //	@Accept			json
 //	@Param			data	body	models.WithdrawIn	true	"User withdraw data"
 //	@Success		200
-//	@Failure		401
-//	@Failure		402
-//	@Failure		422
-//	@Failure		500
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		402	{object}	models.Problem
+//	@Failure		403	{object}	models.Problem
+//	@Failure		413	{object}	models.Problem
+//	@Failure		422	{object}	models.Problem
+//	@Failure		429	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/api/user/balance/withdraw [post]
 func (s *APIServer) createWithdraw() http.HandlerFunc {
@@ -281,31 +312,176 @@ func (s *APIServer) createWithdraw() http.HandlerFunc {
 
 		userID, err := s.getUserID(r.Context())
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "", w, r)
 			return
 		}
 
-		if err = json.NewDecoder(r.Body).Decode(&withdraw); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !s.decodeJSON(&withdraw, w, r) || !s.validateStruct(withdraw, w, r) {
 			return
 		}
 		orderUID, err = strconv.ParseInt(withdraw.Order, 10, 64)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			s.writeProblem(problemBadRequest, "order number must contain only digits", w, r)
 			return
 		}
 
-		if !utils.Valid(orderUID) {
-			http.Error(w, "invalid order format", http.StatusUnprocessableEntity)
+		if err = s.business.CreateWithdraw(r.Context(), userID, orderUID, withdraw.Sum); err != nil {
+			s.writeError(err, w, r)
 			return
 		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
 
-		if err = s.business.CreateWithdraw(r.Context(), userID, orderUID, withdraw.Sum); err != nil {
-			if errors.Is(err, customerrors.ErrNotEnoughFunds) {
-				w.WriteHeader(http.StatusPaymentRequired)
-				return
-			}
-			http.Error(w, err.Error(), http.StatusBadRequest)
+// createHold godoc
+//
+//	@Summary		Create hold
+//	@Description	reserve points for an order until the hold is captured or released
+//	@Tags			user
+//	@Accept			json
+//	@Produce		json
+//	@Param			data	body		models.WithdrawIn	true	"Order and sum to hold"
+//	@Success		201		{object}	models.HoldOut
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		402		{object}	models.Problem
+//	@Failure		403		{object}	models.Problem
+//	@Failure		413		{object}	models.Problem
+//	@Failure		422		{object}	models.Problem
+//	@Failure		429		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/balance/holds [post]
+func (s *APIServer) createHold() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			orderUID  int64
+			holdIn    models.WithdrawIn
+			domenHold domenModels.Hold
+		)
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		if !s.decodeJSON(&holdIn, w, r) || !s.validateStruct(holdIn, w, r) {
+			return
+		}
+		orderUID, err = strconv.ParseInt(holdIn.Order, 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "order number must contain only digits", w, r)
+			return
+		}
+
+		domenHold, err = s.business.CreateHold(r.Context(), userID, orderUID, holdIn.Sum)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		s.writeJSONRespStatus(models.HoldOut{
+			ID:        domenHold.ID,
+			Order:     strconv.FormatInt(domenHold.OrderID, 10),
+			Sum:       domenHold.Sum,
+			Status:    domenHold.Status,
+			ExpiresAt: domenHold.ExpiresAt,
+		}, http.StatusCreated, w)
+	}
+}
+
+// captureHold godoc
+//
+//	@Summary		Capture hold
+//	@Description	withdraw the held points
+//	@Tags			user
+//	@Param			id	path	int	true	"Hold id"
+//	@Success		200
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		409	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/balance/holds/{id}/capture [post]
+func (s *APIServer) captureHold() http.HandlerFunc {
+	return s.finishHold(s.business.CaptureHold)
+}
+
+// releaseHold godoc
+//
+//	@Summary		Release hold
+//	@Description	return the held points to the available balance
+//	@Tags			user
+//	@Param			id	path	int	true	"Hold id"
+//	@Success		200
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		409	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/balance/holds/{id}/release [post]
+func (s *APIServer) releaseHold() http.HandlerFunc {
+	return s.finishHold(s.business.ReleaseHold)
+}
+
+func (s *APIServer) finishHold(finish func(ctx context.Context, userID, holdID int64) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		holdID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "id must be an integer", w, r)
+			return
+		}
+
+		if err = finish(r.Context(), userID, holdID); err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// createTransfer godoc
+//
+//	@Summary		Create transfer
+//	@Description	transfer points to another user
+//	@Tags			user
+//	@Accept			json
+//	@Param			data	body	models.TransferIn	true	"Recipient login and sum"
+//	@Success		200
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		402	{object}	models.Problem
+//	@Failure		403	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		413	{object}	models.Problem
+//	@Failure		422	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/balance/transfer [post]
+func (s *APIServer) createTransfer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var transfer models.TransferIn
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		if !s.decodeJSON(&transfer, w, r) || !s.validateStruct(transfer, w, r) {
+			return
+		}
+
+		if err = s.business.CreateTransfer(r.Context(), userID, transfer.Login, transfer.Sum); err != nil {
+			s.writeError(err, w, r)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
@@ -318,9 +494,15 @@ func (s *APIServer) createWithdraw() http.HandlerFunc {
 //	@Description	get user withdraw list
 //	@Tags			user
 //	@Produce		json
-//	@Success		200	{array}	models.WithdrawOut
-//	@Failure		401
-//	@Failure		500
+//	@Param			from	query		string	false	"Uploaded at or after, RFC 3339 time or date"
+//	@Param			to		query		string	false	"Created before, a date includes the whole day"
+//	@Param			min		query		number	false	"Minimal sum"
+//	@Param			max		query		number	false	"Maximal sum"
+//	@Param			sort	query		string	false	"Sort by creation time"	Enums(asc, desc)
+//	@Success		200		{array}		models.WithdrawOut
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
 //	@Security		ApiKeyAuth
 //	@Router			/api/user/withdrawals [get]
 func (s *APIServer) getWithdrawals() http.HandlerFunc {
@@ -328,13 +510,19 @@ func (s *APIServer) getWithdrawals() http.HandlerFunc {
 		var withdrawals []models.WithdrawOut
 		userID, err := s.getUserID(r.Context())
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "", w, r)
 			return
 		}
 
-		domenWithdrawals, err := s.business.GetWithdrawals(r.Context(), userID)
+		filter, err := parseListFilter(r.URL.Query(), false)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeProblem(problemBadRequest, err.Error(), w, r)
+			return
+		}
+
+		domenWithdrawals, err := s.business.GetWithdrawals(r.Context(), userID, filter)
+		if err != nil {
+			s.writeError(err, w, r)
 			return
 		}
 
@@ -354,6 +542,43 @@ func (s *APIServer) getWithdrawals() http.HandlerFunc {
 	}
 }
 
+// getReferrals godoc
+//
+//	@Summary		Get referrals
+//	@Description	get user referral code and invited users
+//	@Tags			user
+//	@Produce		json
+//	@Success		200	{object}	models.Referrals
+//	@Failure		401	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/referrals [get]
+func (s *APIServer) getReferrals() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		domenReferrals, err := s.business.GetReferrals(r.Context(), userID)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		referrals := models.Referrals{
+			Code:      domenReferrals.Code,
+			Rewarded:  domenReferrals.Rewarded,
+			Referrals: make([]models.Referral, 0, len(domenReferrals.Referrals)),
+		}
+		for _, referral := range domenReferrals.Referrals {
+			referrals.Referrals = append(referrals.Referrals, models.Referral(referral))
+		}
+		s.writeJSONResp(referrals, w)
+	}
+}
+
 // ToDo: add pagination.
 func (s *APIServer) getOrderPaginateList() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {}
@@ -369,8 +594,7 @@ func (s *APIServer) getOrderPaginateList() http.HandlerFunc {
 func (s *APIServer) ping() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := s.business.Ping(r.Context()); err != nil {
-			s.logger.Error(err)
-			w.WriteHeader(http.StatusInternalServerError)
+			s.writeError(err, w, r)
 			return
 		}
 
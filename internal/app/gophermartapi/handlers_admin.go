@@ -0,0 +1,101 @@
+package gophermartapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+// createCampaign godoc
+//
+//	@Summary		Create campaign
+//	@Description	create a promotional campaign, bonuses apply to orders uploaded during the campaign
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			data	body		models.Campaign	true	"Campaign"
+//	@Success		201		{object}	models.Campaign
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		413		{object}	models.Problem
+//	@Failure		422		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		AdminKeyAuth
+//	@Router			/api/admin/campaigns [post]
+func (s *APIServer) createCampaign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var campaign models.Campaign
+
+		if !s.decodeJSON(&campaign, w, r) || !s.validateStruct(campaign, w, r) {
+			return
+		}
+
+		domenCampaign, err := s.business.CreateCampaign(r.Context(), domenModels.Campaign(campaign))
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		s.writeJSONRespStatus(models.Campaign(domenCampaign), http.StatusCreated, w)
+	}
+}
+
+// getCampaigns godoc
+//
+//	@Summary		Get campaigns
+//	@Description	list all promotional campaigns
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{array}		models.Campaign
+//	@Failure		401	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		AdminKeyAuth
+//	@Router			/api/admin/campaigns [get]
+func (s *APIServer) getCampaigns() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		domenCampaigns, err := s.business.GetCampaigns(r.Context())
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		campaigns := make([]models.Campaign, 0, len(domenCampaigns))
+		for _, campaign := range domenCampaigns {
+			campaigns = append(campaigns, models.Campaign(campaign))
+		}
+		s.writeJSONResp(campaigns, w)
+	}
+}
+
+// endCampaign godoc
+//
+//	@Summary		End campaign
+//	@Description	stop the campaign now, orders uploaded afterwards get no campaign bonus
+//	@Tags			admin
+//	@Param			id	path	int	true	"Campaign id"
+//	@Success		200
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		409	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		AdminKeyAuth
+//	@Router			/api/admin/campaigns/{id}/end [post]
+func (s *APIServer) endCampaign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		campaignID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "id must be an integer", w, r)
+			return
+		}
+
+		if err = s.business.EndCampaign(r.Context(), campaignID); err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,181 @@
+package gophermartapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+
+	eventsHeartbeat = 15 * time.Second
+	eventsBatchSize = 100
+	// eventsOverlap is longer than any transaction writing user events. An event id is taken at insert
+	// and becomes visible at commit, so a recent event may show up after the ones with greater ids.
+	eventsOverlap = time.Minute
+)
+
+// eventsCursor remembers the events written to the stream within the overlap window.
+type eventsCursor struct {
+	lastID int64
+	sent   map[int64]time.Time
+}
+
+func (c *eventsCursor) add(e domenModels.UserEvent) {
+	c.sent[e.ID] = e.CreatedAt
+	c.lastID = max(c.lastID, e.ID)
+}
+
+// forget drops the events created before the overlap window, they are not read again.
+func (c *eventsCursor) forget(since time.Time) {
+	for id, createdAt := range c.sent {
+		if createdAt.Before(since) {
+			delete(c.sent, id)
+		}
+	}
+}
+
+// getOrderEvents godoc
+//
+//	@Summary		Stream user events
+//	@Description	server-sent events on order status, accrual and balance changes
+//	@Description	event "order": {"number", "status", "accrual"}
+//	@Description	event "balance": {"current", "withdrawn", "held", "available"}
+//	@Description	after a reconnect with Last-Event-ID an event committed late may be sent again, dedupe by id
+//	@Tags			user
+//	@Produce		text/event-stream
+//	@Param			Last-Event-ID	header	int	false	"Resume after this event id"
+//	@Success		200
+//	@Failure		400
+//	@Failure		401
+//	@Failure		500
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/orders/events [get]
+func (s *APIServer) getOrderEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.logger.Error("response writer does not support flushing")
+			s.writeProblem(problemInternal, "", w, r)
+			return
+		}
+
+		// subscribe before reading the last id, so no event slips in between
+		wakeup, unsubscribe := s.events.Subscribe(userID)
+		defer unsubscribe()
+
+		cursor := &eventsCursor{sent: make(map[int64]time.Time)}
+		if h := r.Header.Get(lastEventIDHeader); h != "" {
+			cursor.lastID, err = strconv.ParseInt(h, 10, 64)
+			if err != nil {
+				s.writeProblem(problemBadRequest, "Last-Event-ID must be an integer", w, r)
+				return
+			}
+		} else if err = s.skipUserEvents(r.Context(), userID, cursor); err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		w.Header().Set(contType, "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			if err = s.writeUserEvents(r.Context(), w, userID, cursor); err != nil {
+				s.logger.Error(err)
+				return
+			}
+			flusher.Flush()
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-wakeup:
+			case <-heartbeat.C:
+				if _, err = fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// skipUserEvents starts the stream after the events already committed.
+func (s *APIServer) skipUserEvents(ctx context.Context, userID int64, cursor *eventsCursor) (err error) {
+	cursor.lastID, err = s.business.GetLastUserEventID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get last user event id, %w", err)
+	}
+	return s.scanUserEvents(ctx, userID, 0, time.Now().Add(-eventsOverlap), func(e domenModels.UserEvent) error {
+		if e.ID <= cursor.lastID {
+			cursor.sent[e.ID] = e.CreatedAt
+		}
+		return nil
+	})
+}
+
+// writeUserEvents writes the events after the cursor and the ones of the overlap window not written yet.
+func (s *APIServer) writeUserEvents(
+	ctx context.Context,
+	w http.ResponseWriter,
+	userID int64,
+	cursor *eventsCursor,
+) error {
+	write := func(e domenModels.UserEvent) error {
+		if _, ok := cursor.sent[e.ID]; ok {
+			return nil
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Payload); err != nil {
+			return fmt.Errorf("failed to write user event, %w", err)
+		}
+		cursor.add(e)
+		return nil
+	}
+
+	since := time.Now().Add(-eventsOverlap)
+	cursor.forget(since)
+	if err := s.scanUserEvents(ctx, userID, 0, since, write); err != nil {
+		return err
+	}
+	return s.scanUserEvents(ctx, userID, cursor.lastID, time.Time{}, write)
+}
+
+// scanUserEvents calls fn for the events with id greater than afterID created since the given time.
+func (s *APIServer) scanUserEvents(
+	ctx context.Context,
+	userID, afterID int64,
+	since time.Time,
+	fn func(e domenModels.UserEvent) error,
+) error {
+	for {
+		events, err := s.business.GetUserEvents(ctx, userID, afterID, since, eventsBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get user events, %w", err)
+		}
+		for _, e := range events {
+			if err = fn(e); err != nil {
+				return err
+			}
+			afterID = e.ID
+		}
+		if len(events) < eventsBatchSize {
+			return nil
+		}
+	}
+}
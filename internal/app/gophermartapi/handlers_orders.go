@@ -0,0 +1,179 @@
+package gophermartapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/utils"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+const (
+	maxOrderBatch = 1000
+)
+
+// createOrderBatch godoc
+//
+//	@Summary		Create orders batch
+//	@Description	upload up to 1000 orders at once, as a JSON array or a text/plain list with one number per line
+//	@Description	every number gets its own result: ACCEPTED, ALREADY_UPLOADED, CONFLICT (another user) or INVALID
+//	@Tags			user
+//	@Accept			json,plain
+//	@Produce		json
+//	@Param			data	body		[]string	true	"Order numbers"
+//	@Success		200		{array}		models.OrderResult
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		413		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/orders/batch [post]
+func (s *APIServer) createOrderBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var numbers []string
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get(contType))
+		if mediaType == "text/plain" {
+			numbers, err = readLines(http.MaxBytesReader(w, r.Body, maxBodySize))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					s.writeProblem(problemBodyTooLarge, "", w, r)
+					return
+				}
+				s.writeProblem(problemBadRequest, "failed to read request body", w, r)
+				return
+			}
+		} else {
+			var raw []json.RawMessage
+			if !s.decodeJSON(&raw, w, r) {
+				return
+			}
+			for _, item := range raw {
+				numbers = append(numbers, rawOrderNumber(item))
+			}
+		}
+
+		if len(numbers) == 0 || len(numbers) > maxOrderBatch {
+			s.writeProblem(problemBadRequest, fmt.Sprintf("batch must contain 1 to %d orders", maxOrderBatch), w, r)
+			return
+		}
+
+		results := make([]models.OrderResult, len(numbers))
+		valid := make([]int64, 0, len(numbers))
+		validAt := make([]int, 0, len(numbers))
+		for i, number := range numbers {
+			results[i] = models.OrderResult{Number: number, Status: domenModels.OrderInvalid}
+			orderUID, parseErr := strconv.ParseInt(number, 10, 64)
+			if parseErr != nil || orderUID <= 0 || !utils.Valid(orderUID) {
+				continue
+			}
+			valid = append(valid, orderUID)
+			validAt = append(validAt, i)
+		}
+
+		if len(valid) > 0 {
+			var domenResults []domenModels.OrderResult
+			domenResults, err = s.business.CreateOrders(r.Context(), userID, valid)
+			if err != nil {
+				s.writeError(err, w, r)
+				return
+			}
+			for i, result := range domenResults {
+				results[validAt[i]].Status = result.Result
+			}
+		}
+		s.writeJSONResp(results, w)
+	}
+}
+
+// getOrder godoc
+//
+//	@Summary		Get order
+//	@Description	get the user order with every status transition recorded by the accrual sync
+//	@Tags			user
+//	@Produce		json
+//	@Param			number	path		string	true	"Order number"
+//	@Success		200		{object}	models.OrderDetail
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		404		{object}	models.Problem
+//	@Failure		422		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/orders/{number} [get]
+func (s *APIServer) getOrder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var domenOrder domenModels.OrderDetail
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		orderUID, err := strconv.ParseInt(chi.URLParam(r, "number"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "order number must contain only digits", w, r)
+			return
+		}
+		if !utils.Valid(orderUID) {
+			s.writeProblem(problemInvalidOrderNumber, "", w, r)
+			return
+		}
+
+		domenOrder, err = s.business.GetOrder(r.Context(), userID, orderUID)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		order := models.OrderDetail{
+			Order:   models.Order(domenOrder.Order),
+			History: make([]models.OrderStatusChange, 0, len(domenOrder.History)),
+		}
+		for _, h := range domenOrder.History {
+			order.History = append(order.History, models.OrderStatusChange(h))
+		}
+		s.writeJSONResp(order, w)
+	}
+}
+
+// readLines returns the non-empty trimmed lines of the body.
+func readLines(body io.Reader) (lines []string, err error) {
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return lines, fmt.Errorf("failed to read lines, %w", err)
+	}
+	return lines, nil
+}
+
+// rawOrderNumber accepts both "12345678903" and 12345678903 items.
+func rawOrderNumber(raw json.RawMessage) string {
+	var number string
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return strings.TrimSpace(number)
+	}
+	return string(raw)
+}
@@ -0,0 +1,239 @@
+package gophermartapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+const (
+	statementFormatCSV  = "csv"
+	statementFormatJSON = "json"
+	statementDateLayout = "2006-01-02"
+	statementAmountFmt  = 'f'
+	statementAmountPrec = 2
+)
+
+// statementWriter streams the statement straight into the response.
+type statementWriter interface {
+	domenModels.StatementWriter
+	started() bool
+}
+
+// getStatement godoc
+//
+//	@Summary		Get statement
+//	@Description	accruals, bonuses, withdrawals and transfers in [from, to) with a running balance
+//	@Description	from and to are RFC 3339 times or dates, a date in to includes the whole day
+//	@Description	csv columns: at, kind, order, source, amount, balance, the first and the last rows are
+//	@Description	OPENING and CLOSING balances
+//	@Tags			user
+//	@Produce		json,text/csv
+//	@Param			from	query		string	false	"Period start, the beginning of history by default"
+//	@Param			to		query		string	false	"Period end, now by default"
+//	@Param			format	query		string	false	"Response format"	Enums(json, csv)
+//	@Success		200		{object}	models.Statement
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/statement [get]
+func (s *APIServer) getStatement() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var sw statementWriter
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		q := r.URL.Query()
+		from, err := parseStatementTime(q.Get("from"), time.Unix(0, 0).UTC(), false)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "from must be an RFC 3339 time or a date", w, r)
+			return
+		}
+		to, err := parseStatementTime(q.Get("to"), time.Now().UTC(), true)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "to must be an RFC 3339 time or a date", w, r)
+			return
+		}
+		if !to.After(from) {
+			s.writeProblem(problemBadRequest, "to must be after from", w, r)
+			return
+		}
+
+		switch q.Get("format") {
+		case "", statementFormatJSON:
+			sw = &jsonStatement{w: w, from: from, to: to}
+		case statementFormatCSV:
+			sw = &csvStatement{w: w, csv: csv.NewWriter(w), from: from, to: to}
+		default:
+			s.writeProblem(problemBadRequest, "format must be json or csv", w, r)
+			return
+		}
+
+		if err = s.business.WriteStatement(r.Context(), userID, from, to, sw); err != nil {
+			if !sw.started() {
+				s.writeError(err, w, r)
+				return
+			}
+			// the status is already sent, the client sees a truncated body
+			s.logger.Error(err)
+		}
+	}
+}
+
+// parseStatementTime parses RFC 3339 or a date, endOfDay moves a date to the next midnight.
+func parseStatementTime(value string, def time.Time, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(statementDateLayout, value)
+	if err != nil {
+		return t, fmt.Errorf("failed to parse time, %w", err)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+type csvStatement struct {
+	w        http.ResponseWriter
+	csv      *csv.Writer
+	from, to time.Time
+	isOpen   bool
+}
+
+func (c *csvStatement) started() bool {
+	return c.isOpen
+}
+
+func (c *csvStatement) Opening(balance float64) error {
+	c.w.Header().Set(contType, "text/csv; charset=utf-8")
+	c.w.Header().Set("Content-Disposition", `attachment; filename="statement.csv"`)
+	c.w.WriteHeader(http.StatusOK)
+	c.isOpen = true
+
+	return c.write(
+		[]string{"at", "kind", "order", "source", "amount", "balance"},
+		[]string{c.from.Format(time.RFC3339), "OPENING", "", "", "", formatAmount(balance)},
+	)
+}
+
+func (c *csvStatement) Entry(e domenModels.StatementEntry) error {
+	return c.write([]string{
+		e.At.Format(time.RFC3339),
+		e.Kind,
+		formatOrder(e.OrderID),
+		e.Source,
+		formatAmount(e.Amount),
+		formatAmount(e.Balance),
+	})
+}
+
+func (c *csvStatement) Closing(balance float64) error {
+	if !c.isOpen {
+		if err := c.Opening(balance); err != nil {
+			return err
+		}
+	}
+	if err := c.write([]string{c.to.Format(time.RFC3339), "CLOSING", "", "", "", formatAmount(balance)}); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return fmt.Errorf("failed to write csv, %w", err)
+	}
+	return nil
+}
+
+func (c *csvStatement) write(records ...[]string) error {
+	for _, record := range records {
+		if err := c.csv.Write(record); err != nil {
+			return fmt.Errorf("failed to write csv, %w", err)
+		}
+	}
+	return nil
+}
+
+type jsonStatement struct {
+	w        http.ResponseWriter
+	from, to time.Time
+	isOpen   bool
+	entries  int
+}
+
+func (j *jsonStatement) started() bool {
+	return j.isOpen
+}
+
+func (j *jsonStatement) Opening(balance float64) error {
+	j.w.Header().Set(contType, "application/json")
+	j.w.WriteHeader(http.StatusOK)
+	j.isOpen = true
+
+	_, err := fmt.Fprintf(j.w, `{"from":%q,"to":%q,"opening_balance":%s,"entries":[`,
+		j.from.Format(time.RFC3339Nano), j.to.Format(time.RFC3339Nano), formatAmount(balance))
+	if err != nil {
+		return fmt.Errorf("failed to write json, %w", err)
+	}
+	return nil
+}
+
+func (j *jsonStatement) Entry(e domenModels.StatementEntry) error {
+	entry, err := json.Marshal(models.StatementEntry{
+		At:      e.At,
+		Kind:    e.Kind,
+		Order:   formatOrder(e.OrderID),
+		Source:  e.Source,
+		Amount:  e.Amount,
+		Balance: e.Balance,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal statement entry, %w", err)
+	}
+	if j.entries > 0 {
+		entry = append([]byte{','}, entry...)
+	}
+	j.entries++
+
+	if _, err = j.w.Write(entry); err != nil {
+		return fmt.Errorf("failed to write json, %w", err)
+	}
+	return nil
+}
+
+func (j *jsonStatement) Closing(balance float64) error {
+	if !j.isOpen {
+		if err := j.Opening(balance); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(j.w, `],"closing_balance":%s}`, formatAmount(balance)); err != nil {
+		return fmt.Errorf("failed to write json, %w", err)
+	}
+	return nil
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, statementAmountFmt, statementAmountPrec, 64)
+}
+
+func formatOrder(orderID *int64) string {
+	if orderID == nil {
+		return ""
+	}
+	return strconv.FormatInt(*orderID, 10)
+}
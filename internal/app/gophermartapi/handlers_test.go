@@ -1,21 +1,33 @@
 package gophermartapi
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 
 	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
 	"github.com/NStegura/gophermart/internal/customerrors"
 	"github.com/NStegura/gophermart/internal/monitoring/logger"
 	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
 	mock_gophermartapi "github.com/NStegura/gophermart/mocks/app/gophermartapi"
 )
 
@@ -24,6 +36,7 @@ type testHelper struct {
 	ts           *httptest.Server
 	mockBusiness *mock_gophermartapi.MockBusiness
 	mockAuth     *mock_gophermartapi.MockAuth
+	mockEvents   *mock_gophermartapi.MockEvents
 }
 
 func (th *testHelper) request(
@@ -61,11 +74,14 @@ func initTestHelper(t *testing.T) *testHelper {
 	cfglog, _ := logger.Init("info")
 	mockBusiness := mock_gophermartapi.NewMockBusiness(ctrl)
 	mockAuth := mock_gophermartapi.NewMockAuth(ctrl)
+	mockEvents := mock_gophermartapi.NewMockEvents(ctrl)
 
 	server := New(
-		":8080",
+		Config{Address: ":8080", AdminKey: "admin key", RespTimeout: time.Minute, CompressMinSize: 1024},
 		mockBusiness,
 		mockAuth,
+		mockEvents,
+		ratelimit.NewMemory(),
 		cfglog,
 	)
 	server.configRouter()
@@ -76,6 +92,7 @@ func initTestHelper(t *testing.T) *testHelper {
 		ts:           ts,
 		mockBusiness: mockBusiness,
 		mockAuth:     mockAuth,
+		mockEvents:   mockEvents,
 	}
 }
 
@@ -110,8 +127,8 @@ func TestHandler_Register__ok(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
-				th.mockAuth.EXPECT().GeneratePasswordHash(gomock.Any(), gomock.Any()).Return("newPass", nil),
-				th.mockBusiness.EXPECT().CreateUser(gomock.Any(), test.inputUser.Login, "newPass").Return(int64(1), test.err),
+				th.mockAuth.EXPECT().GeneratePasswordHash(gomock.Any()).Return("newPass", nil),
+				th.mockBusiness.EXPECT().CreateUser(gomock.Any(), test.inputUser.Login, "newPass", test.inputUser.ReferralCode).Return(int64(1), test.err),
 				th.mockAuth.EXPECT().GenerateToken(gomock.Any()).Return("token", nil))
 
 			headers, statusCode, respBodyStr := th.request(t, "POST", "/api/user/register",
@@ -186,8 +203,8 @@ func TestHandler_Register__userAlreadyExists(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
-				th.mockAuth.EXPECT().GeneratePasswordHash(gomock.Any(), gomock.Any()).Return("newPass", nil),
-				th.mockBusiness.EXPECT().CreateUser(gomock.Any(), test.inputUser.Login, "newPass").Return(int64(1), test.err),
+				th.mockAuth.EXPECT().GeneratePasswordHash(gomock.Any()).Return("newPass", nil),
+				th.mockBusiness.EXPECT().CreateUser(gomock.Any(), test.inputUser.Login, "newPass", test.inputUser.ReferralCode).Return(int64(1), test.err),
 			)
 
 			_, statusCode, _ := th.request(t, "POST", "/api/user/register",
@@ -545,7 +562,8 @@ func TestHandler_getOrderList__Ok(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
 				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
-				th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1)).Return([]domenModels.Order{{}}, nil),
+				th.mockBusiness.EXPECT().GetUserVersion(gomock.Any(), int64(1)).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1), domenModels.ListFilter{}).Return([]domenModels.Order{{}}, nil),
 			)
 			_, statusCode, _ := th.request(t, "GET", "/api/user/orders",
 				bytes.NewBufferString(``), &headers)
@@ -580,7 +598,8 @@ func TestHandler_getOrderList__NoContent(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
 				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
-				th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1)).Return([]domenModels.Order{}, nil),
+				th.mockBusiness.EXPECT().GetUserVersion(gomock.Any(), int64(1)).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1), domenModels.ListFilter{}).Return([]domenModels.Order{}, nil),
 			)
 			_, statusCode, _ := th.request(t, "GET", "/api/user/orders",
 				bytes.NewBufferString(``), &headers)
@@ -615,7 +634,9 @@ func TestHandler_getBalance__Ok(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
 				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().GetUserVersion(gomock.Any(), int64(1)).Return(int64(1), nil),
 				th.mockBusiness.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(domenModels.User{}, nil),
+				th.mockBusiness.EXPECT().GetUserTier(gomock.Any(), int64(1)).Return(domenModels.Tier{Name: "BRONZE"}, nil),
 			)
 			_, statusCode, _ := th.request(t, "GET", "/api/user/balance",
 				bytes.NewBufferString(``), &headers)
@@ -760,7 +781,7 @@ func TestHandler_getWithdrawals__Ok(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			gomock.InOrder(
 				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
-				th.mockBusiness.EXPECT().GetWithdrawals(gomock.Any(), int64(1)).Return([]domenModels.Withdraw{{}}, nil),
+				th.mockBusiness.EXPECT().GetWithdrawals(gomock.Any(), int64(1), domenModels.ListFilter{}).Return([]domenModels.Withdraw{{}}, nil),
 			)
 			_, statusCode, _ := th.request(t, "GET", "/api/user/withdrawals",
 				bytes.NewBufferString(``), &headers)
@@ -770,3 +791,1546 @@ func TestHandler_getWithdrawals__Ok(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_createTransfer__Ok(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			inputBody:          `{"login": "friend", "sum": 50}`,
+			err:                nil,
+			expectedStatusCode: 200,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().CreateTransfer(gomock.Any(), int64(1), "friend", float64(50)).Return(test.err),
+			)
+			_, statusCode, _ := th.request(t, "POST", "/api/user/balance/transfer",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_createTransfer__BadRequest(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		expectedStatusCode int
+	}{
+		{
+			name:               "NegativeSum",
+			inputBody:          `{"login": "friend", "sum": -50}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "EmptyLogin",
+			inputBody:          `{"sum": 50}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "InvalidLogin",
+			inputBody:          `{"login": "my friend", "sum": 50}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "SumWithThreeDecimals",
+			inputBody:          `{"login": "friend", "sum": 0.005}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "NotJSON",
+			inputBody:          `{"login": `,
+			expectedStatusCode: 400,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			)
+			_, statusCode, _ := th.request(t, "POST", "/api/user/balance/transfer",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_createTransfer__BusinessErrors(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "RecipientNotFound",
+			inputBody:          `{"login": "friend", "sum": 50}`,
+			err:                customerrors.ErrNotFound,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "SelfTransfer",
+			inputBody:          `{"login": "friend", "sum": 50}`,
+			err:                customerrors.ErrSelfTransfer,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "Blocked",
+			inputBody:          `{"login": "friend", "sum": 50}`,
+			err:                customerrors.ErrUserBlocked,
+			expectedStatusCode: 403,
+		},
+		{
+			name:               "NotEnoughFunds",
+			inputBody:          `{"login": "friend", "sum": 50}`,
+			err:                customerrors.ErrNotEnoughFunds,
+			expectedStatusCode: 402,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().CreateTransfer(gomock.Any(), int64(1), "friend", float64(50)).Return(test.err),
+			)
+			_, statusCode, _ := th.request(t, "POST", "/api/user/balance/transfer",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_createWithdraw__RulesViolated(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "InvalidSum",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                customerrors.ErrInvalidSum,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "BelowMin",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                customerrors.ErrWithdrawBelowMin,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "DailyCap",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                customerrors.ErrWithdrawDailyCap,
+			expectedStatusCode: 403,
+		},
+		{
+			name:               "RateLimit",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                customerrors.ErrWithdrawRateLimit,
+			expectedStatusCode: 429,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().CreateWithdraw(gomock.Any(), int64(1), int64(1234567897), float64(50)).Return(test.err),
+			)
+			_, statusCode, _ := th.request(t, "POST", "/api/user/balance/withdraw",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_createHold__Ok(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                nil,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "NotEnoughFunds",
+			inputBody:          `{"order": "1234567897", "sum": 50}`,
+			err:                customerrors.ErrNotEnoughFunds,
+			expectedStatusCode: 402,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().CreateHold(gomock.Any(), int64(1), int64(1234567897), float64(50)).Return(
+					domenModels.Hold{ID: 1, OrderID: 1234567897, Sum: 50, Status: "HELD"}, test.err),
+			)
+			_, statusCode, _ := th.request(t, "POST", "/api/user/balance/holds",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_finishHold(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		path               string
+		capture            bool
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "CaptureOk",
+			path:               "/api/user/balance/holds/7/capture",
+			capture:            true,
+			err:                nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "CaptureNotActive",
+			path:               "/api/user/balance/holds/7/capture",
+			capture:            true,
+			err:                customerrors.ErrHoldNotActive,
+			expectedStatusCode: 409,
+		},
+		{
+			name:               "ReleaseOk",
+			path:               "/api/user/balance/holds/7/release",
+			err:                nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "ReleaseNotFound",
+			path:               "/api/user/balance/holds/7/release",
+			err:                customerrors.ErrNotFound,
+			expectedStatusCode: 404,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			if test.capture {
+				th.mockBusiness.EXPECT().CaptureHold(gomock.Any(), int64(1), int64(7)).Return(test.err)
+			} else {
+				th.mockBusiness.EXPECT().ReleaseHold(gomock.Any(), int64(1), int64(7)).Return(test.err)
+			}
+			_, statusCode, _ := th.request(t, "POST", test.path,
+				bytes.NewBufferString(``), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_Register__referral(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		inputUser          models.User
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:      "Ok",
+			inputBody: `{"login": "login", "password": "password", "referral_code": "ABCD2345"}`,
+			inputUser: models.User{
+				Login:        "login",
+				Password:     "password",
+				ReferralCode: "ABCD2345",
+			},
+			err:                nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:      "InvalidCode",
+			inputBody: `{"login": "login", "password": "password", "referral_code": "ABCD2345"}`,
+			inputUser: models.User{
+				Login:        "login",
+				Password:     "password",
+				ReferralCode: "ABCD2345",
+			},
+			err:                customerrors.ErrReferralInvalid,
+			expectedStatusCode: 422,
+		},
+		{
+			name:      "ReferrerLimit",
+			inputBody: `{"login": "login", "password": "password", "referral_code": "ABCD2345"}`,
+			inputUser: models.User{
+				Login:        "login",
+				Password:     "password",
+				ReferralCode: "ABCD2345",
+			},
+			err:                customerrors.ErrReferralLimit,
+			expectedStatusCode: 422,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().GeneratePasswordHash(gomock.Any()).Return("newPass", nil)
+			th.mockBusiness.EXPECT().CreateUser(
+				gomock.Any(), test.inputUser.Login, "newPass", test.inputUser.ReferralCode,
+			).Return(int64(1), test.err)
+			if test.err == nil {
+				th.mockAuth.EXPECT().GenerateToken(gomock.Any()).Return("token", nil)
+			}
+
+			_, statusCode, _ := th.request(t, "POST", "/api/user/register",
+				bytes.NewBufferString(test.inputBody), nil)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_getReferrals__Ok(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	gomock.InOrder(
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+		th.mockBusiness.EXPECT().GetReferrals(gomock.Any(), int64(1)).Return(domenModels.Referrals{
+			Code:      "ABCD2345",
+			Referrals: []domenModels.Referral{{Login: "friend", Status: "PENDING"}},
+		}, nil),
+	)
+	_, statusCode, body := th.request(t, "GET", "/api/user/referrals",
+		bytes.NewBufferString(``), &headers)
+
+	// require
+	require.Equal(t, statusCode, 200)
+	require.Contains(t, body, `"code":"ABCD2345"`)
+}
+
+func TestHandler_createCampaign(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		adminKey           string
+		inputBody          string
+		callBusiness       bool
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:     "ok",
+			adminKey: "admin key",
+			inputBody: `{"name": "weekend", "kind": "MULTIPLIER", "value": 2,
+				"starts_at": "2024-03-02T00:00:00Z", "ends_at": "2024-03-04T00:00:00Z"}`,
+			callBusiness:       true,
+			err:                nil,
+			expectedStatusCode: 201,
+		},
+		{
+			name:     "Invalid",
+			adminKey: "admin key",
+			inputBody: `{"name": "weekend", "kind": "MULTIPLIER", "value": 0.5,
+				"starts_at": "2024-03-02T00:00:00Z", "ends_at": "2024-03-04T00:00:00Z"}`,
+			callBusiness:       true,
+			err:                customerrors.ErrCampaignInvalid,
+			expectedStatusCode: 422,
+		},
+		{
+			name:     "EndsBeforeStart",
+			adminKey: "admin key",
+			inputBody: `{"name": "weekend", "kind": "MULTIPLIER", "value": 2,
+				"starts_at": "2024-03-04T00:00:00Z", "ends_at": "2024-03-02T00:00:00Z"}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "UnknownKind",
+			adminKey:           "admin key",
+			inputBody:          `{"name": "weekend", "kind": "DOUBLE", "value": 2, "starts_at": "2024-03-02T00:00:00Z"}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "BadRequest",
+			adminKey:           "admin key",
+			inputBody:          `{"name": `,
+			expectedStatusCode: 400,
+		},
+		{
+			name:               "WrongAdminKey",
+			adminKey:           "auth header",
+			inputBody:          `{}`,
+			expectedStatusCode: 401,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := make(map[string]string, 1)
+			headers["X-Admin-Key"] = test.adminKey
+
+			if test.callBusiness {
+				th.mockBusiness.EXPECT().CreateCampaign(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, c domenModels.Campaign) (domenModels.Campaign, error) {
+						c.ID = 1
+						return c, test.err
+					})
+			}
+			_, statusCode, _ := th.request(t, "POST", "/api/admin/campaigns",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_endCampaign(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			err:                nil,
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "NotFound",
+			err:                customerrors.ErrNotFound,
+			expectedStatusCode: 404,
+		},
+		{
+			name:               "Finished",
+			err:                customerrors.ErrCampaignFinished,
+			expectedStatusCode: 409,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["X-Admin-Key"] = "admin key"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockBusiness.EXPECT().EndCampaign(gomock.Any(), int64(3)).Return(test.err)
+			_, statusCode, _ := th.request(t, "POST", "/api/admin/campaigns/3/end",
+				bytes.NewBufferString(``), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_getOrderEvents(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	wakeup := make(chan struct{})
+	unsubscribed := make(chan struct{})
+	gomock.InOrder(
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+		th.mockEvents.EXPECT().Subscribe(int64(1)).Return(wakeup, func() { close(unsubscribed) }),
+		// the overlap window returns an event committed after the one the client resumes from
+		th.mockBusiness.EXPECT().GetUserEvents(gomock.Any(), int64(1), int64(0), gomock.Any(), int64(eventsBatchSize)).
+			Return([]domenModels.UserEvent{
+				{ID: 4, Type: "balance", Payload: []byte(`{"current":500}`), CreatedAt: time.Now()},
+			}, nil),
+		th.mockBusiness.EXPECT().GetUserEvents(gomock.Any(), int64(1), int64(5), time.Time{}, int64(eventsBatchSize)).
+			Return([]domenModels.UserEvent{
+				{ID: 6, Type: "order", Payload: []byte(`{"number":"1234567897","status":"PROCESSED"}`)},
+			}, nil),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", th.ts.URL+"/api/user/orders/events", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "auth header")
+	req.Header.Set("Last-Event-ID", "5")
+
+	resp, err := th.ts.Client().Do(req)
+	require.NoError(t, err)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Log(err)
+		}
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && len(lines) < 7 {
+		lines = append(lines, scanner.Text())
+	}
+	require.Equal(t, []string{
+		"id: 4",
+		"event: balance",
+		`data: {"current":500}`,
+		"",
+		"id: 6",
+		"event: order",
+		`data: {"number":"1234567897","status":"PROCESSED"}`,
+	}, lines)
+
+	cancel()
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed")
+	}
+}
+
+func TestHandler_createWebhook(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		inputBody          string
+		callBusiness       bool
+		err                error
+		expectedStatusCode int
+	}{
+		{
+			name:               "ok",
+			inputBody:          `{"url": "https://partner.example/hook", "events": ["order.processed"]}`,
+			callBusiness:       true,
+			err:                nil,
+			expectedStatusCode: 201,
+		},
+		{
+			name:               "Invalid",
+			inputBody:          `{"url": "https://partner.example/hook", "events": ["order.processed"]}`,
+			callBusiness:       true,
+			err:                customerrors.ErrWebhookInvalid,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "Limit",
+			inputBody:          `{"url": "https://partner.example/hook", "events": ["order.processed"]}`,
+			callBusiness:       true,
+			err:                customerrors.ErrWebhookLimit,
+			expectedStatusCode: 409,
+		},
+		{
+			name:               "NoEvents",
+			inputBody:          `{"url": "https://partner.example/hook", "events": []}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "NotHTTP",
+			inputBody:          `{"url": "ftp://partner.example/hook", "events": ["order.processed"]}`,
+			expectedStatusCode: 422,
+		},
+		{
+			name:               "BadRequest",
+			inputBody:          `{"url": `,
+			expectedStatusCode: 400,
+		},
+	}
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			if test.callBusiness {
+				th.mockBusiness.EXPECT().CreateWebhook(
+					gomock.Any(), int64(1), "https://partner.example/hook", []string{"order.processed"},
+				).Return(domenModels.Webhook{ID: 1, Secret: "secret"}, test.err)
+			}
+			_, statusCode, _ := th.request(t, "POST", "/api/user/webhooks",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			// require
+			require.Equal(t, statusCode, test.expectedStatusCode)
+		})
+	}
+}
+
+func TestHandler_getWebhookDeliveries(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := make(map[string]string, 1)
+	headers["Authorization"] = "auth header"
+
+	gomock.InOrder(
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+		th.mockBusiness.EXPECT().GetWebhookDeliveries(gomock.Any(), int64(1), int64(2)).Return(
+			[]domenModels.WebhookDelivery{
+				{ID: 3, Event: "balance.changed", Payload: []byte(`{"current":10}`), Status: "FAILED", Attempts: 10},
+			}, nil),
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+		th.mockBusiness.EXPECT().RedeliverWebhook(gomock.Any(), int64(1), int64(2), int64(3)).Return(nil),
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+		th.mockBusiness.EXPECT().RedeliverWebhook(gomock.Any(), int64(1), int64(2), int64(4)).Return(
+			customerrors.ErrNotFound),
+	)
+
+	_, statusCode, body := th.request(t, "GET", "/api/user/webhooks/2/deliveries", nil, &headers)
+	require.Equal(t, http.StatusOK, statusCode)
+	require.Contains(t, body, `"payload":{"current":10}`)
+
+	_, statusCode, _ = th.request(t, "POST", "/api/user/webhooks/2/deliveries/3/redeliver", nil, &headers)
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	_, statusCode, _ = th.request(t, "POST", "/api/user/webhooks/2/deliveries/4/redeliver", nil, &headers)
+	require.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestHandler_problem(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+
+	tests := []struct {
+		name               string
+		method, path, body string
+		headers            *map[string]string
+		prepare            func()
+		expectedStatusCode int
+		expectedCode       string
+		expectedDetail     string
+	}{
+		{
+			name:               "No auth header",
+			method:             "GET",
+			path:               "/api/user/balance",
+			expectedStatusCode: 401,
+			expectedCode:       "unauthorized",
+			expectedDetail:     "Authorization header is required",
+		},
+		{
+			name:    "Bad luhn",
+			method:  "POST",
+			path:    "/api/user/orders",
+			body:    "1234567890",
+			headers: &headers,
+			prepare: func() {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: 422,
+			expectedCode:       "invalid_order_number",
+		},
+		{
+			name:    "Not a number does not leak parse error",
+			method:  "POST",
+			path:    "/api/user/orders",
+			body:    "12a",
+			headers: &headers,
+			prepare: func() {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: 400,
+			expectedCode:       "bad_request",
+			expectedDetail:     "order number must contain only digits",
+		},
+		{
+			name:    "Business error",
+			method:  "POST",
+			path:    "/api/user/balance/withdraw",
+			body:    `{"order": "1234567897", "sum": 50}`,
+			headers: &headers,
+			prepare: func() {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+				th.mockBusiness.EXPECT().CreateWithdraw(gomock.Any(), int64(1), int64(1234567897), float64(50)).
+					Return(fmt.Errorf("wrapped, %w", customerrors.ErrNotEnoughFunds))
+			},
+			expectedStatusCode: 402,
+			expectedCode:       "not_enough_funds",
+			expectedDetail:     customerrors.ErrNotEnoughFunds.Error(),
+		},
+		{
+			name:    "Internal error is hidden",
+			method:  "GET",
+			path:    "/api/user/withdrawals",
+			headers: &headers,
+			prepare: func() {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+				th.mockBusiness.EXPECT().GetWithdrawals(gomock.Any(), int64(1), domenModels.ListFilter{}).
+					Return(nil, errors.New("pq: connection refused"))
+			},
+			expectedStatusCode: 500,
+			expectedCode:       "internal",
+		},
+		{
+			name:               "Unknown route",
+			method:             "GET",
+			path:               "/api/user/unknown",
+			expectedStatusCode: 404,
+			expectedCode:       "route_not_found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.prepare != nil {
+				test.prepare()
+			}
+			respHeaders, statusCode, body := th.request(t, test.method, test.path,
+				bytes.NewBufferString(test.body), test.headers)
+
+			var p models.Problem
+			require.NoError(t, json.Unmarshal([]byte(body), &p))
+
+			require.Equal(t, test.expectedStatusCode, statusCode)
+			require.Equal(t, problemContentType, respHeaders["Content-Type"][0])
+			require.Equal(t, test.expectedStatusCode, p.Status)
+			require.Equal(t, test.expectedCode, p.Code)
+			require.Equal(t, problemTypePrefix+test.expectedCode, p.Type)
+			require.Equal(t, test.expectedDetail, p.Detail)
+			require.Equal(t, test.path, p.Instance)
+			require.NotEmpty(t, p.RequestID)
+		})
+	}
+}
+
+func TestProblem__unique(t *testing.T) {
+	codes := make(map[string]struct{})
+	for _, ep := range errProblems {
+		_, ok := codes[ep.problem.code]
+		require.False(t, ok, ep.problem.code)
+		codes[ep.problem.code] = struct{}{}
+	}
+
+	// every exported customerrors value has a problem, an unmapped one is hidden behind 500
+	fset := token.NewFileSet()
+
+	mapped := make(map[string]struct{})
+	f, err := parser.ParseFile(fset, "problem.go", nil, 0)
+	require.NoError(t, err)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "customerrors" {
+				mapped[sel.Sel.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+
+	pkgs, err := parser.ParseDir(fset, "../../customerrors", nil, 0)
+	require.NoError(t, err)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, obj := range file.Scope.Objects {
+				if obj.Kind != ast.Var || !ast.IsExported(obj.Name) {
+					continue
+				}
+				_, ok := mapped[obj.Name]
+				require.True(t, ok, "customerrors.%s has no problem", obj.Name)
+			}
+		}
+	}
+}
+
+func TestHandler_validation(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+
+	tests := []struct {
+		name               string
+		path, body         string
+		headers            *map[string]string
+		expectedStatusCode int
+		expectedCode       string
+		expectedFields     []models.FieldError
+	}{
+		{
+			name:               "Empty login and short password",
+			path:               "/api/user/register",
+			body:               `{"login": "", "password": "short"}`,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "login", Rule: "required", Message: "is required"},
+				{Field: "password", Rule: "min", Message: "must be at least 8 characters long"},
+			},
+		},
+		{
+			name:               "Login and password charset",
+			path:               "/api/user/register",
+			body:               `{"login": "in valid", "password": "pass word"}`,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "login", Rule: "login", Message: "may contain only latin letters, digits, '_', '.' and '-'"},
+				{Field: "password", Rule: "password", Message: "must not contain whitespace or control characters " +
+					"and be at most 72 bytes long"},
+			},
+		},
+		{
+			name:               "Password over 72 bytes",
+			path:               "/api/user/register",
+			body:               `{"login": "gopher", "password": "` + strings.Repeat("é", 40) + `"}`,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "password", Rule: "password", Message: "must not contain whitespace or control characters " +
+					"and be at most 72 bytes long"},
+			},
+		},
+		{
+			name:               "Password equal to login",
+			path:               "/api/user/register",
+			body:               `{"login": "gopher_123", "password": "gopher_123"}`,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields:     []models.FieldError{{Field: "password", Rule: "nefield", Message: "must differ from login"}},
+		},
+		{
+			name:               "Unknown field",
+			path:               "/api/user/register",
+			body:               `{"login": "login", "password": "password", "admin": true}`,
+			expectedStatusCode: 400,
+			expectedCode:       "bad_request",
+		},
+		{
+			name:               "Wrong type",
+			path:               "/api/user/register",
+			body:               `{"login": 1, "password": "password"}`,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields:     []models.FieldError{{Field: "login", Rule: "type", Message: "must be a string"}},
+		},
+		{
+			name:               "Body too large",
+			path:               "/api/user/register",
+			body:               `{"login": "` + strings.Repeat("a", maxBodySize) + `"}`,
+			expectedStatusCode: 413,
+			expectedCode:       "body_too_large",
+		},
+		{
+			name:               "Negative sum and bad order",
+			path:               "/api/user/balance/withdraw",
+			body:               `{"order": "12a", "sum": -1}`,
+			headers:            &headers,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "order", Rule: "luhn", Message: "must be a valid order number"},
+				{Field: "sum", Rule: "gt", Message: "must be greater than 0"},
+			},
+		},
+		{
+			name:               "Transfer to invalid login",
+			path:               "/api/user/balance/transfer",
+			body:               `{"login": "my friend", "sum": 0.005}`,
+			headers:            &headers,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "login", Rule: "login", Message: "may contain only latin letters, digits, '_', '.' and '-'"},
+				{Field: "sum", Rule: "cents", Message: "must have at most two decimal places"},
+			},
+		},
+		{
+			name:               "Webhook without events",
+			path:               "/api/user/webhooks",
+			body:               `{"url": "partner.example", "events": []}`,
+			headers:            &headers,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "url", Rule: "http_url", Message: "must be an http or https URL"},
+				{Field: "events", Rule: "min", Message: "must have at least 1 item(s)"},
+			},
+		},
+		{
+			name:               "Sum with three decimals",
+			path:               "/api/user/balance/withdraw",
+			body:               `{"order": "1234567897", "sum": 1.005}`,
+			headers:            &headers,
+			expectedStatusCode: 422,
+			expectedCode:       "validation_failed",
+			expectedFields: []models.FieldError{
+				{Field: "sum", Rule: "cents", Message: "must have at most two decimal places"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.headers != nil {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			}
+			_, statusCode, body := th.request(t, "POST", test.path, bytes.NewBufferString(test.body), test.headers)
+
+			var p models.Problem
+			require.NoError(t, json.Unmarshal([]byte(body), &p))
+
+			require.Equal(t, test.expectedStatusCode, statusCode)
+			require.Equal(t, test.expectedCode, p.Code)
+			require.Equal(t, test.expectedFields, p.Errors)
+		})
+	}
+}
+
+func TestHandler_createOrderBatch(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		contentType        string
+		inputBody          string
+		valid              []int64
+		results            []domenModels.OrderResult
+		expectedStatusCode int
+		expectedBody       []models.OrderResult
+	}{
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			inputBody:   `["1234567897", 12345678903, "1", "abc"]`,
+			valid:       []int64{1234567897, 12345678903},
+			results: []domenModels.OrderResult{
+				{Number: 1234567897, Result: domenModels.OrderAccepted},
+				{Number: 12345678903, Result: domenModels.OrderUploadedByAnother},
+			},
+			expectedStatusCode: 200,
+			expectedBody: []models.OrderResult{
+				{Number: "1234567897", Status: "ACCEPTED"},
+				{Number: "12345678903", Status: "CONFLICT"},
+				{Number: "1", Status: "INVALID"},
+				{Number: "abc", Status: "INVALID"},
+			},
+		},
+		{
+			name:        "Plain text",
+			contentType: "text/plain; charset=utf-8",
+			inputBody:   "1234567897\n\n 12345678903 \r\n",
+			valid:       []int64{1234567897, 12345678903},
+			results: []domenModels.OrderResult{
+				{Number: 1234567897, Result: domenModels.OrderAlreadyUploaded},
+				{Number: 12345678903, Result: domenModels.OrderAccepted},
+			},
+			expectedStatusCode: 200,
+			expectedBody: []models.OrderResult{
+				{Number: "1234567897", Status: "ALREADY_UPLOADED"},
+				{Number: "12345678903", Status: "ACCEPTED"},
+			},
+		},
+		{
+			name:               "Only invalid",
+			contentType:        "application/json",
+			inputBody:          `["1"]`,
+			expectedStatusCode: 200,
+			expectedBody:       []models.OrderResult{{Number: "1", Status: "INVALID"}},
+		},
+		{
+			name:               "Empty",
+			contentType:        "application/json",
+			inputBody:          `[]`,
+			expectedStatusCode: 400,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			if test.valid != nil {
+				th.mockBusiness.EXPECT().CreateOrders(gomock.Any(), int64(1), test.valid).Return(test.results, nil)
+			}
+			headers := map[string]string{"Authorization": "auth header", "Content-Type": test.contentType}
+			_, statusCode, body := th.request(t, "POST", "/api/user/orders/batch",
+				bytes.NewBufferString(test.inputBody), &headers)
+
+			require.Equal(t, test.expectedStatusCode, statusCode)
+			if test.expectedBody != nil {
+				var results []models.OrderResult
+				require.NoError(t, json.Unmarshal([]byte(body), &results))
+				require.Equal(t, test.expectedBody, results)
+			}
+		})
+	}
+}
+
+func TestHandler_getStatement(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	orderID := int64(1234567897)
+	writeStatement := func(_ context.Context, _ int64, _, _ time.Time, w domenModels.StatementWriter) error {
+		require.NoError(t, w.Opening(100))
+		require.NoError(t, w.Entry(domenModels.StatementEntry{
+			At: at, Kind: domenModels.StatementAccrual, OrderID: &orderID, Amount: 50, Balance: 150,
+		}))
+		require.NoError(t, w.Entry(domenModels.StatementEntry{
+			At: at, Kind: domenModels.StatementWithdrawal, OrderID: &orderID, Amount: -20.5, Balance: 129.5,
+		}))
+		return w.Closing(129.5)
+	}
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("CSV", func(t *testing.T) {
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().WriteStatement(gomock.Any(), int64(1), from, to, gomock.Any()).
+			DoAndReturn(writeStatement)
+
+		respHeaders, statusCode, body := th.request(t, "GET",
+			"/api/user/statement?from=2024-02-01&to=2024-02-29&format=csv", nil, &headers)
+
+		require.Equal(t, 200, statusCode)
+		require.Equal(t, "text/csv; charset=utf-8", respHeaders["Content-Type"][0])
+		require.Equal(t, "at,kind,order,source,amount,balance\n"+
+			"2024-02-01T00:00:00Z,OPENING,,,,100.00\n"+
+			"2024-02-10T12:00:00Z,ACCRUAL,1234567897,,50.00,150.00\n"+
+			"2024-02-10T12:00:00Z,WITHDRAWAL,1234567897,,-20.50,129.50\n"+
+			"2024-03-01T00:00:00Z,CLOSING,,,,129.50\n", body)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().WriteStatement(gomock.Any(), int64(1), from, to, gomock.Any()).
+			DoAndReturn(writeStatement)
+
+		_, statusCode, body := th.request(t, "GET",
+			"/api/user/statement?from=2024-02-01T00:00:00Z&to=2024-03-01T00:00:00Z", nil, &headers)
+
+		var statement models.Statement
+		require.Equal(t, 200, statusCode)
+		require.NoError(t, json.Unmarshal([]byte(body), &statement))
+		require.Equal(t, float64(100), statement.OpeningBalance)
+		require.Equal(t, 129.5, statement.ClosingBalance)
+		require.Len(t, statement.Entries, 2)
+		require.Equal(t, "1234567897", statement.Entries[0].Order)
+		require.Equal(t, float64(150), statement.Entries[0].Balance)
+	})
+
+	t.Run("Bad params", func(t *testing.T) {
+		for _, query := range []string{"format=xml", "from=yesterday", "from=2024-03-01&to=2024-02-01"} {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			_, statusCode, _ := th.request(t, "GET", "/api/user/statement?"+query, nil, &headers)
+			require.Equal(t, 400, statusCode, query)
+		}
+	})
+
+	t.Run("Error before streaming", func(t *testing.T) {
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().WriteStatement(gomock.Any(), int64(1), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.New("db is down"))
+
+		_, statusCode, _ := th.request(t, "GET", "/api/user/statement", nil, &headers)
+		require.Equal(t, 500, statusCode)
+	})
+}
+
+func TestHandler_listFilter(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	minAmount, maxAmount := float64(10), 99.5
+
+	t.Run("Orders", func(t *testing.T) {
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().GetUserVersion(gomock.Any(), int64(1)).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1), domenModels.ListFilter{
+			Statuses:  []string{"NEW", "PROCESSED"},
+			From:      &from,
+			To:        &to,
+			MinAmount: &minAmount,
+			MaxAmount: &maxAmount,
+			Desc:      true,
+		}).Return([]domenModels.Order{{}}, nil)
+
+		_, statusCode, _ := th.request(t, "GET",
+			"/api/user/orders?status=new,PROCESSED&from=2024-02-01&to=2024-02-29&min=10&max=99.5&sort=desc",
+			nil, &headers)
+		require.Equal(t, 200, statusCode)
+	})
+
+	t.Run("Withdrawals", func(t *testing.T) {
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+		th.mockBusiness.EXPECT().GetWithdrawals(gomock.Any(), int64(1), domenModels.ListFilter{
+			From:      &from,
+			MinAmount: &minAmount,
+		}).Return([]domenModels.Withdraw{{}}, nil)
+
+		_, statusCode, _ := th.request(t, "GET", "/api/user/withdrawals?from=2024-02-01T00:00:00Z&min=10&sort=asc",
+			nil, &headers)
+		require.Equal(t, 200, statusCode)
+	})
+
+	t.Run("Bad params", func(t *testing.T) {
+		for _, path := range []string{
+			"/api/user/orders?status=DONE",
+			"/api/user/orders?sort=up",
+			"/api/user/orders?min=-1",
+			"/api/user/orders?min=10&max=5",
+			"/api/user/orders?from=2024-03-01&to=2024-02-01",
+			"/api/user/withdrawals?status=NEW",
+			"/api/user/withdrawals?to=tomorrow",
+		} {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			_, statusCode, _ := th.request(t, "GET", path, nil, &headers)
+			require.Equal(t, 400, statusCode, path)
+		}
+	})
+}
+
+func TestHandler_getOrder(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+	uploadedAt := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	processedAt := time.Date(2024, 2, 1, 10, 5, 0, 0, time.UTC)
+
+	t.Run("Ok", func(t *testing.T) {
+		gomock.InOrder(
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			th.mockBusiness.EXPECT().GetOrder(gomock.Any(), int64(1), int64(12345678903)).Return(
+				domenModels.OrderDetail{
+					Order: domenModels.Order{
+						Number:      12345678903,
+						Status:      "PROCESSED",
+						Accrual:     500,
+						UploadedAt:  uploadedAt,
+						ProcessedAt: &processedAt,
+						CheckedAt:   processedAt,
+					},
+					History: []domenModels.OrderStatusChange{
+						{Status: "PROCESSING", At: uploadedAt},
+						{Status: "PROCESSED", Accrual: 500, At: processedAt},
+					},
+				}, nil),
+		)
+		_, statusCode, body := th.request(t, "GET", "/api/user/orders/12345678903", nil, &headers)
+		require.Equal(t, 200, statusCode)
+		require.JSONEq(t, `{
+			"number": "12345678903",
+			"status": "PROCESSED",
+			"accrual": 500,
+			"uploaded_at": "2024-02-01T10:00:00Z",
+			"processed_at": "2024-02-01T10:05:00Z",
+			"checked_at": "2024-02-01T10:05:00Z",
+			"history": [
+				{"status": "PROCESSING", "at": "2024-02-01T10:00:00Z"},
+				{"status": "PROCESSED", "accrual": 500, "at": "2024-02-01T10:05:00Z"}
+			]
+		}`, body)
+	})
+
+	t.Run("Empty history", func(t *testing.T) {
+		gomock.InOrder(
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			th.mockBusiness.EXPECT().GetOrder(gomock.Any(), int64(1), int64(12345678903)).Return(
+				domenModels.OrderDetail{Order: domenModels.Order{Number: 12345678903, Status: "NEW"}}, nil),
+		)
+		_, statusCode, body := th.request(t, "GET", "/api/user/orders/12345678903", nil, &headers)
+		require.Equal(t, 200, statusCode)
+		require.Contains(t, body, `"history":[]`)
+	})
+
+	tests := []struct {
+		name               string
+		path               string
+		err                error
+		expectedStatusCode int
+	}{
+		{"Not digits", "/api/user/orders/12a", nil, 400},
+		{"Invalid number", "/api/user/orders/12345678904", nil, 422},
+		{"Not found", "/api/user/orders/12345678903", customerrors.ErrNotFound, 404},
+		{"Unexpected error", "/api/user/orders/12345678903", errors.New("db is down"), 500},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			if test.err != nil {
+				th.mockBusiness.EXPECT().GetOrder(gomock.Any(), int64(1), int64(12345678903)).
+					Return(domenModels.OrderDetail{}, test.err)
+			}
+			_, statusCode, _ := th.request(t, "GET", test.path, nil, &headers)
+			require.Equal(t, test.expectedStatusCode, statusCode)
+		})
+	}
+}
+
+func TestHandler_etag(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name               string
+		path               string
+		ifNoneMatch        string
+		prepare            func()
+		expectedStatusCode int
+	}{
+		{
+			name: "Balance without tag",
+			path: "/api/user/balance",
+			prepare: func() {
+				th.mockBusiness.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(domenModels.User{}, nil)
+				th.mockBusiness.EXPECT().GetUserTier(gomock.Any(), int64(1)).Return(domenModels.Tier{}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Balance not modified",
+			path:               "/api/user/balance",
+			ifNoneMatch:        `W/"7"`,
+			expectedStatusCode: 304,
+		},
+		{
+			name:               "Strong tag matches weakly",
+			path:               "/api/user/orders",
+			ifNoneMatch:        `"6", "7"`,
+			expectedStatusCode: 304,
+		},
+		{
+			name:               "Any tag",
+			path:               "/api/user/orders",
+			ifNoneMatch:        `*`,
+			expectedStatusCode: 304,
+		},
+		{
+			name:        "Orders changed",
+			path:        "/api/user/orders",
+			ifNoneMatch: `W/"6"`,
+			prepare: func() {
+				th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1), domenModels.ListFilter{}).
+					Return([]domenModels.Order{{}}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := map[string]string{"Authorization": "auth header"}
+			if test.ifNoneMatch != "" {
+				headers["If-None-Match"] = test.ifNoneMatch
+			}
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			th.mockBusiness.EXPECT().GetUserVersion(gomock.Any(), int64(1)).Return(int64(7), nil)
+			if test.prepare != nil {
+				test.prepare()
+			}
+
+			respHeaders, statusCode, body := th.request(t, "GET", test.path, nil, &headers)
+			require.Equal(t, test.expectedStatusCode, statusCode)
+			require.Equal(t, []string{`W/"7"`}, respHeaders["Etag"])
+			require.Equal(t, []string{"private, no-cache"}, respHeaders["Cache-Control"])
+			if statusCode == 304 {
+				require.Empty(t, body)
+			}
+		})
+	}
+}
+
+func TestHandler_rateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cfglog, _ := logger.Init("info")
+	mockBusiness := mock_gophermartapi.NewMockBusiness(ctrl)
+	mockAuth := mock_gophermartapi.NewMockAuth(ctrl)
+	mockLimiter := mock_gophermartapi.NewMockRateLimiter(ctrl)
+	limits := RateLimits{
+		Auth:   ratelimit.Limit{Requests: 1, Window: time.Minute},
+		API:    ratelimit.Limit{Requests: 100, Window: time.Minute},
+		Orders: ratelimit.Limit{Requests: 1, Window: time.Minute},
+	}
+	proxies := []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("10.0.0.0/8")}
+	server := New(Config{Address: ":8080", RespTimeout: time.Minute, RateLimits: limits, TrustedProxies: proxies},
+		mockBusiness, mockAuth, nil, mockLimiter, cfglog)
+	server.configRouter()
+	th := &testHelper{ctrl: ctrl, ts: httptest.NewServer(server.router), mockBusiness: mockBusiness, mockAuth: mockAuth}
+	defer th.finish()
+
+	headers := map[string]string{"Authorization": "auth header"}
+	reset := time.Now().Add(30 * time.Second)
+
+	t.Run("Login is limited by IP", func(t *testing.T) {
+		mockLimiter.EXPECT().Take(gomock.Any(), "auth:ip:127.0.0.1", limits.Auth).
+			Return(ratelimit.Result{Limit: 1, Remaining: 0, Reset: reset, Allowed: false}, nil)
+
+		respHeaders, statusCode, body := th.request(t, "POST", "/api/user/login",
+			bytes.NewBufferString(`{"login": "user", "password": "password"}`), nil)
+		require.Equal(t, 429, statusCode)
+		require.Contains(t, body, `"code":"rate_limited"`)
+		require.Equal(t, "1", respHeaders["Ratelimit-Limit"][0])
+		require.Equal(t, "0", respHeaders["Ratelimit-Remaining"][0])
+		require.Contains(t, []string{"29", "30"}, respHeaders["Ratelimit-Reset"][0])
+		require.Equal(t, respHeaders["Ratelimit-Reset"], respHeaders["Retry-After"])
+	})
+
+	t.Run("Login behind trusted proxies is limited by forwarded IP", func(t *testing.T) {
+		mockLimiter.EXPECT().Take(gomock.Any(), "auth:ip:203.0.113.7", limits.Auth).
+			Return(ratelimit.Result{Limit: 1, Remaining: 0, Reset: reset, Allowed: true}, nil)
+		mockBusiness.EXPECT().GetUserByLogin(gomock.Any(), "user").Return(domenModels.User{}, customerrors.ErrNotFound)
+
+		// the client may forge the leftmost address, the proxies append the ones they see
+		forwarded := map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.5"}
+		_, statusCode, _ := th.request(t, "POST", "/api/user/login",
+			bytes.NewBufferString(`{"login": "user", "password": "password"}`), &forwarded)
+		require.Equal(t, 401, statusCode)
+	})
+
+	t.Run("Order upload is limited by user", func(t *testing.T) {
+		gomock.InOrder(
+			mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			mockLimiter.EXPECT().Take(gomock.Any(), "api:user:1", limits.API).
+				Return(ratelimit.Result{Limit: 100, Remaining: 99, Reset: reset, Allowed: true}, nil),
+			mockLimiter.EXPECT().Take(gomock.Any(), "orders:user:1", limits.Orders).
+				Return(ratelimit.Result{Limit: 1, Remaining: 0, Reset: reset, Allowed: false}, nil),
+		)
+		_, statusCode, _ := th.request(t, "POST", "/api/user/orders", bytes.NewBufferString("12345678903"), &headers)
+		require.Equal(t, 429, statusCode)
+	})
+
+	t.Run("Limiter failure lets requests through", func(t *testing.T) {
+		gomock.InOrder(
+			mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			mockLimiter.EXPECT().Take(gomock.Any(), "api:user:1", limits.API).
+				Return(ratelimit.Result{}, errors.New("db is down")),
+			mockBusiness.EXPECT().GetReferrals(gomock.Any(), int64(1)).Return(domenModels.Referrals{}, nil),
+		)
+		respHeaders, statusCode, _ := th.request(t, "GET", "/api/user/referrals", nil, &headers)
+		require.Equal(t, 200, statusCode)
+		require.Empty(t, respHeaders["Ratelimit-Limit"])
+	})
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"br", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "gzip"},
+		{"gzip, zstd", "zstd"},
+		{"gzip;q=1.0, zstd;q=0.5", "gzip"},
+		{"zstd;q=0, gzip;q=0.1", "gzip"},
+		{"gzip;q=0", ""},
+		{"*", "zstd"},
+		{"*;q=0.5, zstd;q=0", "gzip"},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			require.Equal(t, test.want, acceptedEncoding(test.header))
+		})
+	}
+}
+
+func TestHandler_compress(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	withdrawals := make([]domenModels.Withdraw, 100)
+	for i := range withdrawals {
+		withdrawals[i] = domenModels.Withdraw{OrderID: 1234567897, Sum: 100}
+	}
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		withdrawals      []domenModels.Withdraw
+		expectedEncoding string
+	}{
+		{"Gzip", "gzip", withdrawals, "gzip"},
+		{"Zstd", "gzip;q=0.5, zstd", withdrawals, "zstd"},
+		{"Small body", "gzip", withdrawals[:1], ""},
+		{"Not accepted", "identity", withdrawals, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := map[string]string{"Authorization": "auth header", "Accept-Encoding": test.acceptEncoding}
+			gomock.InOrder(
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+				th.mockBusiness.EXPECT().GetWithdrawals(gomock.Any(), int64(1), domenModels.ListFilter{}).
+					Return(test.withdrawals, nil),
+			)
+			respHeaders, statusCode, body := th.request(t, "GET", "/api/user/withdrawals", nil, &headers)
+			require.Equal(t, 200, statusCode)
+			require.Equal(t, test.expectedEncoding, http.Header(respHeaders).Get("Content-Encoding"))
+
+			var decoded []byte
+			switch test.expectedEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(strings.NewReader(body))
+				require.NoError(t, err)
+				decoded, err = io.ReadAll(zr)
+				require.NoError(t, err)
+			case "zstd":
+				zr, err := zstd.NewReader(strings.NewReader(body))
+				require.NoError(t, err)
+				defer zr.Close()
+				decoded, err = io.ReadAll(zr)
+				require.NoError(t, err)
+			default:
+				decoded = []byte(body)
+			}
+			var got []models.WithdrawOut
+			require.NoError(t, json.Unmarshal(decoded, &got))
+			require.Len(t, got, len(test.withdrawals))
+		})
+	}
+}
+
+func TestHandler_decompress(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	gzipped := func(data []byte) io.Reader {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return &buf
+	}
+
+	t.Run("Gzip batch", func(t *testing.T) {
+		headers := map[string]string{
+			"Authorization":    "auth header",
+			"Content-Type":     "text/plain",
+			"Content-Encoding": "gzip",
+		}
+		gomock.InOrder(
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			th.mockBusiness.EXPECT().CreateOrders(gomock.Any(), int64(1), []int64{12345678903, 1234567897}).
+				Return([]domenModels.OrderResult{
+					{Number: 12345678903, Result: domenModels.OrderAccepted},
+					{Number: 1234567897, Result: domenModels.OrderAccepted},
+				}, nil),
+		)
+		_, statusCode, _ := th.request(t, "POST", "/api/user/orders/batch",
+			gzipped([]byte("12345678903\n1234567897\n")), &headers)
+		require.Equal(t, 200, statusCode)
+	})
+
+	t.Run("Zstd order", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "auth header", "Content-Encoding": "zstd"}
+		zw, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		gomock.InOrder(
+			th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil),
+			th.mockBusiness.EXPECT().CreateOrder(gomock.Any(), int64(1), int64(12345678903)).Return(nil),
+		)
+		_, statusCode, _ := th.request(t, "POST", "/api/user/orders",
+			bytes.NewReader(zw.EncodeAll([]byte("12345678903"), nil)), &headers)
+		require.Equal(t, 202, statusCode)
+	})
+
+	t.Run("Zstd window above body size", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "auth header", "Content-Encoding": "zstd"}
+		// magic, frame header without content size and a 256 MiB window, one last raw block of "1".
+		frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 18 << 3, 0x09, 0x00, 0x00, '1'}
+		th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+
+		_, statusCode, _ := th.request(t, "POST", "/api/user/orders", bytes.NewReader(frame), &headers)
+		require.Equal(t, 400, statusCode)
+	})
+
+	tests := []struct {
+		name               string
+		encoding           string
+		body               io.Reader
+		expectedStatusCode int
+	}{
+		{"Not gzip", "gzip", strings.NewReader("12345678903"), 400},
+		{"Unsupported encoding", "br", strings.NewReader("12345678903"), 415},
+		{"Decompressed body too large", "gzip", gzipped(bytes.Repeat([]byte("0"), maxBodySize+1)), 413},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := map[string]string{"Authorization": "auth header", "Content-Encoding": test.encoding}
+			if test.expectedStatusCode == 413 {
+				th.mockAuth.EXPECT().ParseToken(gomock.Any()).Return(int64(1), nil)
+			}
+			_, statusCode, _ := th.request(t, "POST", "/api/user/orders", test.body, &headers)
+			require.Equal(t, test.expectedStatusCode, statusCode)
+		})
+	}
+}
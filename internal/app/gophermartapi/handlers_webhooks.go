@@ -0,0 +1,215 @@
+package gophermartapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+)
+
+// createWebhook godoc
+//
+//	@Summary		Create webhook
+//	@Description	register url for events: order.processed, order.invalid, withdrawal.created, balance.changed
+//	@Description	every delivery is signed: X-Gophermart-Signature = "sha256=" + hex(HMAC-SHA256(secret,
+//	@Description	X-Gophermart-Timestamp + "." + body)), the secret is returned only here
+//	@Description	the url host must resolve to public addresses, private and loopback ones are rejected with 422
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			data	body		models.WebhookIn	true	"Url and events"
+//	@Success		201		{object}	models.Webhook
+//	@Failure		400		{object}	models.Problem
+//	@Failure		401		{object}	models.Problem
+//	@Failure		409		{object}	models.Problem
+//	@Failure		413		{object}	models.Problem
+//	@Failure		422		{object}	models.Problem
+//	@Failure		500		{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/webhooks [post]
+func (s *APIServer) createWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var webhook models.WebhookIn
+
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		if !s.decodeJSON(&webhook, w, r) || !s.validateStruct(webhook, w, r) {
+			return
+		}
+
+		domenWebhook, err := s.business.CreateWebhook(r.Context(), userID, webhook.URL, webhook.Events)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		s.writeJSONRespStatus(models.Webhook(domenWebhook), http.StatusCreated, w)
+	}
+}
+
+// getWebhooks godoc
+//
+//	@Summary		Get webhooks
+//	@Description	list registered webhooks
+//	@Tags			webhooks
+//	@Produce		json
+//	@Success		200	{array}		models.Webhook
+//	@Failure		401	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/webhooks [get]
+func (s *APIServer) getWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		domenWebhooks, err := s.business.GetWebhooks(r.Context(), userID)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		webhooks := make([]models.Webhook, 0, len(domenWebhooks))
+		for _, webhook := range domenWebhooks {
+			webhooks = append(webhooks, models.Webhook(webhook))
+		}
+		s.writeJSONResp(webhooks, w)
+	}
+}
+
+// deleteWebhook godoc
+//
+//	@Summary		Delete webhook
+//	@Description	stop sending events to the webhook, queued deliveries are dropped
+//	@Tags			webhooks
+//	@Param			id	path	int	true	"Webhook id"
+//	@Success		200
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/webhooks/{id} [delete]
+func (s *APIServer) deleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		webhookID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "id must be an integer", w, r)
+			return
+		}
+
+		if err = s.business.DeleteWebhook(r.Context(), userID, webhookID); err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// getWebhookDeliveries godoc
+//
+//	@Summary		Get webhook deliveries
+//	@Description	latest 100 deliveries of the webhook, newest first
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook id"
+//	@Success		200	{array}		models.WebhookDelivery
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/webhooks/{id}/deliveries [get]
+func (s *APIServer) getWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		webhookID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "id must be an integer", w, r)
+			return
+		}
+
+		domenDeliveries, err := s.business.GetWebhookDeliveries(r.Context(), userID, webhookID)
+		if err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+
+		deliveries := make([]models.WebhookDelivery, 0, len(domenDeliveries))
+		for _, delivery := range domenDeliveries {
+			deliveries = append(deliveries, models.WebhookDelivery{
+				ID:             delivery.ID,
+				Event:          delivery.Event,
+				Payload:        delivery.Payload,
+				Status:         delivery.Status,
+				Attempts:       delivery.Attempts,
+				NextAttemptAt:  delivery.NextAttemptAt,
+				LastStatusCode: delivery.LastStatusCode,
+				LastError:      delivery.LastError,
+				CreatedAt:      delivery.CreatedAt,
+				DeliveredAt:    delivery.DeliveredAt,
+			})
+		}
+		s.writeJSONResp(deliveries, w)
+	}
+}
+
+// redeliverWebhook godoc
+//
+//	@Summary		Redeliver webhook
+//	@Description	queue the delivery again with a fresh attempts budget
+//	@Tags			webhooks
+//	@Param			id			path	int	true	"Webhook id"
+//	@Param			deliveryID	path	int	true	"Delivery id"
+//	@Success		202
+//	@Failure		400	{object}	models.Problem
+//	@Failure		401	{object}	models.Problem
+//	@Failure		404	{object}	models.Problem
+//	@Failure		500	{object}	models.Problem
+//	@Security		ApiKeyAuth
+//	@Router			/api/user/webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (s *APIServer) redeliverWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := s.getUserID(r.Context())
+		if err != nil {
+			s.writeProblem(problemUnauthorized, "", w, r)
+			return
+		}
+
+		webhookID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "id must be an integer", w, r)
+			return
+		}
+		deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryID"), 10, 64)
+		if err != nil {
+			s.writeProblem(problemBadRequest, "deliveryID must be an integer", w, r)
+			return
+		}
+
+		if err = s.business.RedeliverWebhook(r.Context(), userID, webhookID, deliveryID); err != nil {
+			s.writeError(err, w, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
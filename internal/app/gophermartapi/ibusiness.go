@@ -2,6 +2,7 @@ package gophermartapi
 
 import (
 	"context"
+	"time"
 
 	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
 )
@@ -9,11 +10,38 @@ import (
 type Business interface {
 	Ping(ctx context.Context) error
 
-	CreateUser(ctx context.Context, login, password string) (id int64, err error)
+	CreateUser(ctx context.Context, login, password, referralCode string) (id int64, err error)
 	GetUserByLogin(ctx context.Context, login string) (u domenModels.User, err error)
 	GetUserByID(ctx context.Context, ID int64) (u domenModels.User, err error)
-	GetOrders(ctx context.Context, userID int64) (orders []domenModels.Order, err error)
+	GetUserVersion(ctx context.Context, userID int64) (version int64, err error)
+	GetOrders(ctx context.Context, userID int64, filter domenModels.ListFilter) (orders []domenModels.Order, err error)
+	GetOrder(ctx context.Context, userID, orderID int64) (order domenModels.OrderDetail, err error)
 	CreateOrder(ctx context.Context, userID int64, orderID int64) error
+	CreateOrders(ctx context.Context, userID int64, orderIDs []int64) (results []domenModels.OrderResult, err error)
 	CreateWithdraw(ctx context.Context, userID int64, orderID int64, sum float64) error
-	GetWithdrawals(ctx context.Context, userID int64) (withdrawals []domenModels.Withdraw, err error)
+	GetWithdrawals(
+		ctx context.Context, userID int64, filter domenModels.ListFilter,
+	) (withdrawals []domenModels.Withdraw, err error)
+	WriteStatement(ctx context.Context, userID int64, from, to time.Time, w domenModels.StatementWriter) error
+	GetUserTier(ctx context.Context, userID int64) (t domenModels.Tier, err error)
+	CreateHold(ctx context.Context, userID, orderID int64, sum float64) (hold domenModels.Hold, err error)
+	CaptureHold(ctx context.Context, userID, holdID int64) error
+	ReleaseHold(ctx context.Context, userID, holdID int64) error
+	GetReferrals(ctx context.Context, userID int64) (referrals domenModels.Referrals, err error)
+	CreateTransfer(ctx context.Context, senderID int64, recipientLogin string, sum float64) error
+	GetUserEvents(
+		ctx context.Context, userID, afterID int64, since time.Time, limit int64,
+	) (events []domenModels.UserEvent, err error)
+	GetLastUserEventID(ctx context.Context, userID int64) (id int64, err error)
+	CreateWebhook(ctx context.Context, userID int64, url string, events []string) (w domenModels.Webhook, err error)
+	GetWebhooks(ctx context.Context, userID int64) (webhooks []domenModels.Webhook, err error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int64) error
+	GetWebhookDeliveries(
+		ctx context.Context, userID, webhookID int64,
+	) (deliveries []domenModels.WebhookDelivery, err error)
+	RedeliverWebhook(ctx context.Context, userID, webhookID, deliveryID int64) error
+
+	CreateCampaign(ctx context.Context, campaign domenModels.Campaign) (c domenModels.Campaign, err error)
+	GetCampaigns(ctx context.Context) (campaigns []domenModels.Campaign, err error)
+	EndCampaign(ctx context.Context, campaignID int64) error
 }
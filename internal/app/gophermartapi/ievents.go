@@ -0,0 +1,5 @@
+package gophermartapi
+
+type Events interface {
+	Subscribe(userID int64) (ch <-chan struct{}, unsubscribe func())
+}
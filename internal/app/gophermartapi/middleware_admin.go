@@ -0,0 +1,22 @@
+package gophermartapi
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+const (
+	adminKeyHeader = "X-Admin-Key"
+)
+
+func (s *APIServer) adminMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(adminKeyHeader)
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(s.adminKey)) != 1 {
+			s.logger.Debugln("Admin key not set or invalid")
+			s.writeProblem(problemUnauthorized, "admin key is missing or invalid", w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
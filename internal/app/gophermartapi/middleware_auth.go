@@ -21,14 +21,14 @@ func (s *APIServer) authMiddleware(h http.Handler) http.Handler {
 		authH := r.Header.Get(authHeader)
 		if authH == "" {
 			s.logger.Debugln("Auth header not set")
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "Authorization header is required", w, r)
 			return
 		}
 
 		userID, err := s.auth.ParseToken(authH)
 		if err != nil {
 			s.logger.Debugf("ParseToken failed: %s", err)
-			w.WriteHeader(http.StatusUnauthorized)
+			s.writeProblem(problemUnauthorized, "token is invalid or expired", w, r)
 			return
 		}
 		span := trace.SpanFromContext(r.Context())
@@ -0,0 +1,256 @@
+package gophermartapi
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+var (
+	gzipWriters = sync.Pool{New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}}
+	zstdWriters = sync.Pool{New: func() any {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+		return w
+	}}
+)
+
+// compressedTypes are the content types worth compressing.
+var compressedTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"text/",
+}
+
+// compressMiddleware compresses responses of at least s.compressMinSize with the best encoding
+// the client accepts. Streams are compressed from the first flush.
+func (s *APIServer) compressMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: s.compressMinSize, status: http.StatusOK}
+		h.ServeHTTP(cw, r)
+		if err := cw.Close(); err != nil {
+			s.logger.Errorf("failed to compress response: %s", err)
+		}
+	})
+}
+
+// decompressMiddleware replaces gzip and zstd request bodies by the decoded ones,
+// the decoded body is limited to maxBodySize like a plain one. Zstd frames declaring a window
+// or a content size above maxBodySize are rejected before the decoder allocates it.
+func (s *APIServer) decompressMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			body io.ReadCloser
+			err  error
+		)
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+			h.ServeHTTP(w, r)
+			return
+		case encodingGzip:
+			body, err = gzip.NewReader(r.Body)
+		case encodingZstd:
+			var zr *zstd.Decoder
+			zr, err = zstd.NewReader(r.Body,
+				zstd.WithDecoderConcurrency(1),
+				zstd.WithDecoderMaxWindow(maxBodySize),
+				zstd.WithDecoderMaxMemory(maxBodySize))
+			if err == nil {
+				body = zr.IOReadCloser()
+			}
+		default:
+			s.writeProblem(problemUnsupportedEncoding, fmt.Sprintf("%s is not supported", encoding), w, r)
+			return
+		}
+		if err != nil {
+			s.writeProblem(problemBadRequest, "failed to decompress request body", w, r)
+			return
+		}
+		defer func() {
+			_ = body.Close()
+		}()
+
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+		r.ContentLength = -1
+		r.Body = http.MaxBytesReader(w, body, maxBodySize)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// acceptedEncoding picks zstd or gzip by the Accept-Encoding weights, zstd wins a tie.
+func acceptedEncoding(header string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, encoding := range []string{encodingZstd, encodingGzip} {
+		q, ok := weights[encoding]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the body until it reaches minSize, then decides how to send it.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	status   int
+	buf      []byte
+
+	started bool // headers are sent
+	enc     io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.started {
+		return
+	}
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.started {
+		n, err := cw.out().Write(p)
+		if err != nil {
+			return n, fmt.Errorf("failed to write response, %w", err)
+		}
+		return n, nil
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush starts the stream compressed whatever the buffered size, so event streams are not held back.
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		_ = cw.start(true)
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		if err := cw.start(len(cw.buf) >= cw.minSize); err != nil {
+			return err
+		}
+	}
+	if cw.enc == nil {
+		return nil
+	}
+	err := cw.enc.Close()
+	switch enc := cw.enc.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(enc)
+	case *zstd.Encoder:
+		zstdWriters.Put(enc)
+	}
+	cw.enc = nil
+	if err != nil {
+		return fmt.Errorf("failed to close %s writer, %w", cw.encoding, err)
+	}
+	return nil
+}
+
+// start sends the headers and the buffered body, compressed when wanted and the response allows it.
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+	header := cw.Header()
+	if compress && header.Get("Content-Encoding") == "" && compressible(header.Get(contType)) {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		switch cw.encoding {
+		case encodingZstd:
+			enc, _ := zstdWriters.Get().(*zstd.Encoder)
+			enc.Reset(cw.ResponseWriter)
+			cw.enc = enc
+		default:
+			enc, _ := gzipWriters.Get().(*gzip.Writer)
+			enc.Reset(cw.ResponseWriter)
+			cw.enc = enc
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	_, err := cw.out().Write(cw.buf)
+	cw.buf = nil
+	if err != nil {
+		return fmt.Errorf("failed to write response, %w", err)
+	}
+	return nil
+}
+
+func (cw *compressWriter) out() io.Writer {
+	if cw.enc != nil {
+		return cw.enc
+	}
+	return cw.ResponseWriter
+}
+
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range compressedTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package gophermartapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+const (
+	rateLimitHeader     = "RateLimit-Limit"
+	rateRemainingHeader = "RateLimit-Remaining"
+	rateResetHeader     = "RateLimit-Reset"
+	retryAfterHeader    = "Retry-After"
+	forwardedForHeader  = "X-Forwarded-For"
+)
+
+// RateLimits configures the route groups, zero value of a limit disables it.
+// Auth is counted by client IP, API and Orders by user, Orders only covers uploads.
+type RateLimits struct {
+	Auth   ratelimit.Limit
+	API    ratelimit.Limit
+	Orders ratelimit.Limit
+}
+
+// rateLimit counts the requests of the group by key and rejects them with 429 above the limit.
+// The limiter failure lets the request through, the limits protect the API and must not break it.
+func (s *APIServer) rateLimit(
+	group string,
+	limit ratelimit.Limit,
+	key func(r *http.Request) string,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if s.limiter == nil || !limit.Enabled() {
+			return h
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := s.limiter.Take(r.Context(), group+":"+key(r), limit)
+			if err != nil {
+				s.logger.Errorf("rate limiter failed: %s", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			reset := strconv.FormatInt(int64(math.Ceil(max(time.Until(res.Reset), 0).Seconds())), 10)
+			w.Header().Set(rateLimitHeader, strconv.FormatInt(res.Limit, 10))
+			w.Header().Set(rateRemainingHeader, strconv.FormatInt(res.Remaining, 10))
+			w.Header().Set(rateResetHeader, reset)
+			if !res.Allowed {
+				w.Header().Set(retryAfterHeader, reset)
+				s.writeProblem(problemTooManyRequests, "", w, r)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP is the peer address. Behind a trusted proxy it is the last X-Forwarded-For address
+// not added by a trusted proxy, the addresses on the left are sent by the client and may be forged.
+func (s *APIServer) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !s.trustedProxy(ip) {
+		return "ip:" + ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !s.trustedProxy(ip) {
+			break
+		}
+	}
+	return "ip:" + ip
+}
+
+func (s *APIServer) trustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range s.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *APIServer) userKey(r *http.Request) string {
+	userID, err := s.getUserID(r.Context())
+	if err != nil {
+		return s.clientIP(r)
+	}
+	return "user:" + strconv.FormatInt(userID, 10)
+}
@@ -1,27 +1,74 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login        string `json:"login" validate:"required,min=3,max=64,login"`
+	Password     string `json:"password" validate:"required,min=8,max=72,password,nefield=Login"`
+	ReferralCode string `json:"referral_code,omitempty" validate:"omitempty,alphanum,max=32"`
 }
 
 type Order struct {
-	Number     int64     `json:"number,string"`
-	Status     string    `json:"status"`
-	Accrual    float64   `json:"accrual,omitempty"`
-	UploadedAt time.Time `json:"uploaded_at"`
+	Number      int64      `json:"number,string"`
+	Status      string     `json:"status"`
+	Accrual     float64    `json:"accrual,omitempty"`
+	UploadedAt  time.Time  `json:"uploaded_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+	CheckedAt   time.Time  `json:"checked_at"`
+}
+
+type OrderDetail struct {
+	Order
+	History []OrderStatusChange `json:"history"`
+}
+
+type OrderStatusChange struct {
+	Status  string    `json:"status"`
+	Accrual float64   `json:"accrual,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+type OrderResult struct {
+	Number string `json:"number"`
+	Status string `json:"status" enums:"ACCEPTED,ALREADY_UPLOADED,CONFLICT,INVALID"`
 }
 
 type Balance struct {
 	Current   float64 `json:"current"`
 	Withdrawn float64 `json:"withdrawn"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+	Tier      *Tier   `json:"tier,omitempty"`
+}
+
+type Tier struct {
+	Name          string  `json:"name"`
+	Multiplier    float64 `json:"multiplier"`
+	Volume        float64 `json:"volume"`
+	NextTier      string  `json:"next_tier,omitempty"`
+	NextThreshold float64 `json:"next_threshold,omitempty"`
+	Progress      float64 `json:"progress"`
 }
 
 type WithdrawIn struct {
-	Order string  `json:"order"`
-	Sum   float64 `json:"sum"`
+	Order string  `json:"order" validate:"required,luhn"`
+	Sum   float64 `json:"sum" validate:"gt=0,cents"`
+}
+
+type HoldOut struct {
+	ID        int64     `json:"id"`
+	Order     string    `json:"order"`
+	Sum       float64   `json:"sum"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TransferIn struct {
+	Login string  `json:"login" validate:"required,login"`
+	Sum   float64 `json:"sum" validate:"gt=0,cents"`
 }
 
 type WithdrawOut struct {
@@ -29,3 +76,90 @@ type WithdrawOut struct {
 	Sum         float64   `json:"sum"`
 	ProcessedAt time.Time `json:"processed_at"`
 }
+
+type Referral struct {
+	Login      string     `json:"login"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at,omitempty"`
+}
+
+type Referrals struct {
+	Code      string     `json:"code"`
+	Rewarded  int64      `json:"rewarded"`
+	Referrals []Referral `json:"referrals"`
+}
+
+type Campaign struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name" validate:"required"`
+	Kind         string    `json:"kind" enums:"MULTIPLIER,FIXED" validate:"required,oneof=MULTIPLIER FIXED"`
+	Value        float64   `json:"value" validate:"gt=0"`
+	StartsAt     time.Time `json:"starts_at" validate:"required"`
+	EndsAt       time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	NewUsersOnly bool      `json:"new_users_only"`
+	Tiers        []string  `json:"tiers"`
+}
+
+type WebhookIn struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1"`
+}
+
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" enums:"QUEUED,DELIVERED,FAILED"`
+	Attempts       int64           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int64           `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// Problem is an RFC 7807 error response.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Statement is streamed, the type only documents the JSON shape.
+type Statement struct {
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance float64          `json:"opening_balance"`
+	Entries        []StatementEntry `json:"entries"`
+	ClosingBalance float64          `json:"closing_balance"`
+}
+
+type StatementEntry struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind" enums:"ACCRUAL,BONUS,WITHDRAWAL,TRANSFER_IN,TRANSFER_OUT,ADJUSTMENT"`
+	Order   string    `json:"order,omitempty"`
+	Source  string    `json:"source,omitempty"`
+	Amount  float64   `json:"amount"`
+	Balance float64   `json:"balance"`
+}
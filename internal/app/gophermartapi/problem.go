@@ -0,0 +1,179 @@
+package gophermartapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+	"github.com/NStegura/gophermart/internal/customerrors"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:gophermart:problem:"
+)
+
+// problem is a kind of error response, code is stable and never reused.
+type problem struct {
+	status int
+	code   string
+	title  string
+}
+
+var (
+	problemBadRequest         = problem{http.StatusBadRequest, "bad_request", "Request is malformed"}
+	problemUnauthorized       = problem{http.StatusUnauthorized, "unauthorized", "Authentication is required"}
+	problemInvalidCredentials = problem{http.StatusUnauthorized, "invalid_credentials", "Login or password is wrong"}
+	problemInvalidOrderNumber = problem{
+		http.StatusUnprocessableEntity, "invalid_order_number", "Order number fails the Luhn check",
+	}
+	problemValidation = problem{
+		http.StatusUnprocessableEntity, "validation_failed", "Request fields are not valid",
+	}
+	problemBodyTooLarge        = problem{http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large"}
+	problemUnsupportedEncoding = problem{
+		http.StatusUnsupportedMediaType, "unsupported_encoding", "Content encoding is not supported",
+	}
+	problemTooManyRequests  = problem{http.StatusTooManyRequests, "rate_limited", "Too many requests"}
+	problemRouteNotFound    = problem{http.StatusNotFound, "route_not_found", "Route not found"}
+	problemMethodNotAllowed = problem{http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed"}
+	problemInternal         = problem{http.StatusInternalServerError, "internal", "Internal server error"}
+)
+
+// errProblems maps every customerrors value to exactly one problem.
+var errProblems = []struct {
+	err     error
+	problem problem
+}{
+	{customerrors.ErrNotFound, problem{http.StatusNotFound, "not_found", "Resource not found"}},
+	{customerrors.ErrAlreadyExists, problem{http.StatusConflict, "already_exists", "Resource already exists"}},
+	{customerrors.ErrCurrUserUploaded, problem{
+		http.StatusConflict, "order_uploaded", "Order already uploaded by current user",
+	}},
+	{customerrors.ErrAnotherUserUploaded, problem{
+		http.StatusConflict, "order_uploaded_by_another_user", "Order already uploaded by another user",
+	}},
+	{customerrors.ErrNotEnoughFunds, problem{http.StatusPaymentRequired, "not_enough_funds", "Not enough funds"}},
+	{customerrors.ErrSelfTransfer, problem{http.StatusUnprocessableEntity, "self_transfer", "Transfer to yourself"}},
+	{customerrors.ErrUserBlocked, problem{http.StatusForbidden, "user_blocked", "User is blocked"}},
+	{customerrors.ErrTransferLimit, problem{http.StatusForbidden, "transfer_limit", "Transfer limit exceeded"}},
+	{customerrors.ErrInvalidSum, problem{http.StatusBadRequest, "invalid_sum", "Sum is not valid"}},
+	{customerrors.ErrPasswordTooLong, problem{http.StatusBadRequest, "password_too_long", "Password is too long"}},
+	{customerrors.ErrWithdrawBelowMin, problem{
+		http.StatusUnprocessableEntity, "withdraw_below_min", "Withdraw sum is below the minimum",
+	}},
+	{customerrors.ErrWithdrawAboveMax, problem{
+		http.StatusUnprocessableEntity, "withdraw_above_max", "Withdraw sum is above the maximum",
+	}},
+	{customerrors.ErrWithdrawDailyCap, problem{http.StatusForbidden, "withdraw_daily_cap", "Daily withdraw cap"}},
+	{customerrors.ErrWithdrawMonthlyCap, problem{
+		http.StatusForbidden, "withdraw_monthly_cap", "Monthly withdraw cap",
+	}},
+	{customerrors.ErrWithdrawRateLimit, problem{
+		http.StatusTooManyRequests, "withdraw_rate_limit", "Too many withdrawals",
+	}},
+	{customerrors.ErrWithdrawCoolingOff, problem{
+		http.StatusForbidden, "withdraw_cooling_off", "Withdrawals are not allowed yet",
+	}},
+	{customerrors.ErrHoldNotActive, problem{http.StatusConflict, "hold_not_active", "Hold is not active"}},
+	{customerrors.ErrReferralInvalid, problem{
+		http.StatusUnprocessableEntity, "referral_invalid", "Referral code is not valid",
+	}},
+	{customerrors.ErrReferralLimit, problem{
+		http.StatusUnprocessableEntity, "referral_limit", "Referral limit reached",
+	}},
+	{customerrors.ErrCampaignInvalid, problem{
+		http.StatusUnprocessableEntity, "campaign_invalid", "Campaign is not valid",
+	}},
+	{customerrors.ErrCampaignFinished, problem{http.StatusConflict, "campaign_finished", "Campaign is finished"}},
+	{customerrors.ErrWebhookInvalid, problem{
+		http.StatusUnprocessableEntity, "webhook_invalid", "Webhook is not valid",
+	}},
+	{customerrors.ErrWebhookLimit, problem{http.StatusConflict, "webhook_limit", "Too many webhooks"}},
+	{customerrors.ErrAdjustmentInvalid, problem{
+		http.StatusUnprocessableEntity, "adjustment_invalid", "Adjustment is not valid",
+	}},
+	{customerrors.ErrOrderProcessed, problem{http.StatusConflict, "order_processed", "Order is already processed"}},
+}
+
+// writeError writes the problem of a customerrors value, anything else is logged and hidden behind 500.
+func (s *APIServer) writeError(err error, w http.ResponseWriter, r *http.Request) {
+	for _, ep := range errProblems {
+		if errors.Is(err, ep.err) {
+			s.writeProblem(ep.problem, ep.err.Error(), w, r)
+			return
+		}
+	}
+	s.logger.Error(err)
+	s.writeProblem(problemInternal, "", w, r)
+}
+
+func (s *APIServer) writeProblem(p problem, detail string, w http.ResponseWriter, r *http.Request) {
+	s.writeProblemResp(newProblem(p, detail, r), w)
+}
+
+func (s *APIServer) writeValidationProblem(fields []models.FieldError, w http.ResponseWriter, r *http.Request) {
+	resp := newProblem(problemValidation, "one or more fields are not valid", r)
+	resp.Errors = fields
+	s.writeProblemResp(resp, w)
+}
+
+func newProblem(p problem, detail string, r *http.Request) models.Problem {
+	return models.Problem{
+		Type:      problemTypePrefix + p.code,
+		Title:     p.title,
+		Status:    p.status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      p.code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+func (s *APIServer) writeProblemResp(resp models.Problem, w http.ResponseWriter) {
+	w.Header().Set(contType, problemContentType)
+	w.WriteHeader(resp.Status)
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		s.logger.Error(err)
+		return
+	}
+	if _, err = w.Write(body); err != nil {
+		s.logger.Error(err)
+	}
+}
+
+func (s *APIServer) notFound() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeProblem(problemRouteNotFound, "", w, r)
+	}
+}
+
+func (s *APIServer) methodNotAllowed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.writeProblem(problemMethodNotAllowed, "", w, r)
+	}
+}
+
+// recoverMiddleware replaces middleware.Recoverer, so a panic also ends with a problem.
+func (s *APIServer) recoverMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			s.logger.Errorf("panic: %v\n%s", rec, debug.Stack())
+			s.writeProblem(problemInternal, "", w, r)
+		}()
+		h.ServeHTTP(w, r)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -19,24 +20,43 @@ const (
 )
 
 type APIServer struct {
-	address     string
-	respTimeout time.Duration
-	business    Business
-	auth        Auth
+	address         string
+	adminKey        string
+	respTimeout     time.Duration
+	compressMinSize int
+	business        Business
+	auth            Auth
+	events          Events
+	limiter         RateLimiter
+	limits          RateLimits
+	trustedProxies  []netip.Prefix
 
 	router *chi.Mux
 
 	logger *logrus.Logger
 }
 
-func New(address string, business Business, auth Auth, logger *logrus.Logger) *APIServer {
+func New(
+	cfg Config,
+	business Business,
+	auth Auth,
+	events Events,
+	limiter RateLimiter,
+	logger *logrus.Logger,
+) *APIServer {
 	return &APIServer{
-		address:     address,
-		respTimeout: time.Minute,
-		business:    business,
-		auth:        auth,
-		router:      chi.NewRouter(),
-		logger:      logger,
+		address:         cfg.Address,
+		adminKey:        cfg.AdminKey,
+		respTimeout:     cfg.RespTimeout,
+		compressMinSize: cfg.CompressMinSize,
+		business:        business,
+		auth:            auth,
+		events:          events,
+		limiter:         limiter,
+		limits:          cfg.RateLimits,
+		trustedProxies:  cfg.TrustedProxies,
+		router:          chi.NewRouter(),
+		logger:          logger,
 	}
 }
 
@@ -45,11 +65,18 @@ func New(address string, business Business, auth Auth, logger *logrus.Logger) *A
 //	@title						Gophermart API
 //	@version					1.0
 //	@description				This is a Gophermart server.
+//	@description				Errors are returned as RFC 7807 application/problem+json.
+//	@description				Requests over the rate limit get 429 with RateLimit-* and Retry-After headers.
+//	@description				Responses are compressed by Accept-Encoding (gzip, zstd), so are request bodies by Content-Encoding.
 //	@BasePath					/
 //
 //	@securityDefinitions.apikey	ApiKeyAuth
 //	@in							header
 //	@name						Authorization
+//
+//	@securityDefinitions.apikey	AdminKeyAuth
+//	@in							header
+//	@name						X-Admin-Key
 func (s *APIServer) Start() error {
 	s.configRouter()
 
@@ -64,32 +91,75 @@ func (s *APIServer) configRouter() {
 	s.router.Use(s.tracingMiddleware)
 	s.router.Use(middleware.RequestID)
 	s.router.Use(middleware.Logger)
-	s.router.Use(middleware.Recoverer)
-
-	s.router.Use(middleware.Timeout(s.respTimeout))
-
-	s.router.Get(`/ping`, s.ping())
-
-	s.router.Group(s.baseRouter)
+	s.router.Use(s.recoverMiddleware)
+	s.router.Use(s.compressMiddleware)
+	s.router.Use(s.decompressMiddleware)
+	s.router.NotFound(s.notFound())
+	s.router.MethodNotAllowed(s.methodNotAllowed())
+
+	s.router.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(s.respTimeout))
+		r.Get(`/ping`, s.ping())
+		r.Group(s.baseRouter)
+	})
 	s.router.Route(`/api/user`, func(r chi.Router) {
-		r.Group(s.authRouter)
-		r.Group(s.apiRouter)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(s.respTimeout))
+			r.Group(s.authRouter)
+			r.Group(s.apiRouter)
+		})
+		r.Group(s.streamRouter)
 	})
+	if s.adminKey != "" {
+		s.router.Route(`/api/admin`, func(r chi.Router) {
+			r.Use(middleware.Timeout(s.respTimeout))
+			s.adminRouter(r)
+		})
+	}
 }
 
 func (s *APIServer) authRouter(r chi.Router) {
+	r.Use(s.rateLimit("auth", s.limits.Auth, s.clientIP))
 	r.Post(`/register`, s.register())
 	r.Post(`/login`, s.login())
 }
 
 func (s *APIServer) apiRouter(r chi.Router) {
 	r.Use(s.authMiddleware)
-	r.Post(`/orders`, s.createOrder())
+	r.Use(s.rateLimit("api", s.limits.API, s.userKey))
+	r.With(s.rateLimit("orders", s.limits.Orders, s.userKey)).Post(`/orders`, s.createOrder())
 	r.Get(`/orders`, s.getOrderList())
+	r.With(s.rateLimit("orders", s.limits.Orders, s.userKey)).Post(`/orders/batch`, s.createOrderBatch())
 	r.Get(`/orders/paginate`, s.getOrderPaginateList())
+	r.Get(`/orders/{number}`, s.getOrder())
 	r.Get(`/balance`, s.getBalance())
 	r.Post(`/balance/withdraw`, s.createWithdraw())
+	r.Post(`/balance/transfer`, s.createTransfer())
+	r.Post(`/balance/holds`, s.createHold())
+	r.Post(`/balance/holds/{id}/capture`, s.captureHold())
+	r.Post(`/balance/holds/{id}/release`, s.releaseHold())
 	r.Get(`/withdrawals`, s.getWithdrawals())
+	r.Get(`/statement`, s.getStatement())
+	r.Get(`/referrals`, s.getReferrals())
+	r.Post(`/webhooks`, s.createWebhook())
+	r.Get(`/webhooks`, s.getWebhooks())
+	r.Delete(`/webhooks/{id}`, s.deleteWebhook())
+	r.Get(`/webhooks/{id}/deliveries`, s.getWebhookDeliveries())
+	r.Post(`/webhooks/{id}/deliveries/{deliveryID}/redeliver`, s.redeliverWebhook())
+}
+
+// streamRouter serves long-lived responses, so it has no response timeout.
+func (s *APIServer) streamRouter(r chi.Router) {
+	r.Use(s.authMiddleware)
+	r.Use(s.rateLimit("api", s.limits.API, s.userKey))
+	r.Get(`/orders/events`, s.getOrderEvents())
+}
+
+func (s *APIServer) adminRouter(r chi.Router) {
+	r.Use(s.adminMiddleware)
+	r.Post(`/campaigns`, s.createCampaign())
+	r.Get(`/campaigns`, s.getCampaigns())
+	r.Post(`/campaigns/{id}/end`, s.endCampaign())
 }
 
 func (s *APIServer) baseRouter(r chi.Router) {
@@ -98,6 +168,10 @@ func (s *APIServer) baseRouter(r chi.Router) {
 }
 
 func (s *APIServer) writeJSONResp(resp any, w http.ResponseWriter) {
+	s.writeJSONRespStatus(resp, http.StatusOK, w)
+}
+
+func (s *APIServer) writeJSONRespStatus(resp any, status int, w http.ResponseWriter) {
 	w.Header().Set(contType, "application/json")
 
 	jsonResp, err := json.Marshal(resp)
@@ -105,7 +179,7 @@ func (s *APIServer) writeJSONResp(resp any, w http.ResponseWriter) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	if _, err = w.Write(jsonResp); err != nil {
 		s.logger.Error(err)
 	}
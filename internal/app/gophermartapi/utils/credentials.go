@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// the limits of the `validate` tags of models.User, shared with the other entry points.
+const (
+	minLoginLen    = 3
+	maxLoginLen    = 64
+	minPasswordLen = 8
+	// MaxPasswordBytes is the bcrypt limit, longer passwords are rejected by the hashing.
+	MaxPasswordBytes = 72
+
+	centsInPoint = 100
+	centsEpsilon = 1e-6
+)
+
+var (
+	ErrLoginInvalid = errors.New(
+		"login must be 3 to 64 characters long and contain only latin letters, digits, '_', '.' and '-'")
+	ErrPasswordInvalid = errors.New(
+		"password must be 8 to 72 bytes long, differ from login and contain no whitespace or control characters")
+)
+
+var loginRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+func ValidLogin(login string) bool {
+	return loginRe.MatchString(login)
+}
+
+// ValidPassword rejects whitespace, control characters and passwords longer than bcrypt accepts.
+func ValidPassword(password string) bool {
+	if len(password) > MaxPasswordBytes {
+		return false
+	}
+	for _, c := range password {
+		if unicode.IsSpace(c) || unicode.IsControl(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckCredentials applies the login and password rules of the registration.
+func CheckCredentials(login, password string) error {
+	n := utf8.RuneCountInString(login)
+	if n < minLoginLen || n > maxLoginLen || !ValidLogin(login) {
+		return ErrLoginInvalid
+	}
+	if utf8.RuneCountInString(password) < minPasswordLen || !ValidPassword(password) || password == login {
+		return ErrPasswordInvalid
+	}
+	return nil
+}
+
+// ValidCents reports whether the sum has at most two decimal places.
+func ValidCents(sum float64) bool {
+	cents := sum * centsInPoint
+	return math.Abs(cents-math.Round(cents)) < centsEpsilon
+}
@@ -0,0 +1,175 @@
+package gophermartapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/models"
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/utils"
+)
+
+const maxBodySize = 1 << 16
+
+// validate checks the `validate` tags of the API models, custom rules are registered in newValidator.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	for tag, fn := range map[string]validator.Func{
+		"login":    validLogin,
+		"password": validPassword,
+		"luhn":     validLuhn,
+		"cents":    validCents,
+	} {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+	return v
+}
+
+func validLogin(fl validator.FieldLevel) bool {
+	return utils.ValidLogin(fl.Field().String())
+}
+
+// validPassword rejects whitespace and control characters. The max tag counts runes,
+// so the bytes are checked here too, bcrypt rejects passwords longer than 72 bytes.
+func validPassword(fl validator.FieldLevel) bool {
+	return utils.ValidPassword(fl.Field().String())
+}
+
+func validLuhn(fl validator.FieldLevel) bool {
+	number, err := strconv.ParseInt(fl.Field().String(), 10, 64)
+	return err == nil && number > 0 && utils.Valid(number)
+}
+
+func validCents(fl validator.FieldLevel) bool {
+	return utils.ValidCents(fl.Field().Float())
+}
+
+// sliceRuleMessages replace ruleMessages for list fields.
+var sliceRuleMessages = map[string]string{
+	"min": "must have at least %s item(s)",
+}
+
+var ruleMessages = map[string]string{
+	"required": "is required",
+	"min":      "must be at least %s characters long",
+	"max":      "must be at most %s characters long",
+	"gt":       "must be greater than %s",
+	"nefield":  "must differ from %s",
+	"gtfield":  "must be after %s",
+	"oneof":    "must be one of %s",
+	"http_url": "must be an http or https URL",
+	"alphanum": "may contain only latin letters and digits",
+	"login":    "may contain only latin letters, digits, '_', '.' and '-'",
+	"password": "must not contain whitespace or control characters and be at most 72 bytes long",
+	"luhn":     "must be a valid order number",
+	"cents":    "must have at most two decimal places",
+}
+
+// decodeJSON reads a single JSON object of at most maxBodySize bytes, unknown fields are rejected.
+// On failure the problem is already written.
+func (s *APIServer) decodeJSON(v any, w http.ResponseWriter, r *http.Request) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		if _, err = dec.Token(); errors.Is(err, io.EOF) {
+			return true
+		}
+		s.writeProblem(problemBadRequest, "request body must contain a single JSON object", w, r)
+		return false
+	}
+
+	var (
+		maxBytesErr *http.MaxBytesError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		s.writeProblem(problemBodyTooLarge, fmt.Sprintf("request body is limited to %d bytes", maxBytesErr.Limit), w, r)
+	case errors.As(err, &typeErr):
+		s.writeValidationProblem([]models.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be a " + typeErr.Type.Kind().String(),
+		}}, w, r)
+	case errors.Is(err, io.EOF):
+		s.writeProblem(problemBadRequest, "request body is empty", w, r)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		s.writeProblem(problemBadRequest, strings.TrimPrefix(err.Error(), "json: "), w, r)
+	default:
+		s.writeProblem(problemBadRequest, "request body is not valid JSON", w, r)
+	}
+	return false
+}
+
+// validateStruct checks the `validate` tags of v, on failure the per-field problem is already written.
+func (s *APIServer) validateStruct(v any, w http.ResponseWriter, r *http.Request) bool {
+	err := validate.Struct(v)
+	if err == nil {
+		return true
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		s.writeError(err, w, r)
+		return false
+	}
+
+	fields := make([]models.FieldError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		msg, ok := ruleMessages[fe.Tag()]
+		if sliceMsg, isSlice := sliceRuleMessages[fe.Tag()]; isSlice && fe.Kind() == reflect.Slice {
+			msg, ok = sliceMsg, true
+		}
+		if !ok {
+			msg = "is not valid"
+		}
+		if strings.Contains(msg, "%s") {
+			param := fe.Param()
+			if strings.HasSuffix(fe.Tag(), "field") {
+				param = jsonFieldName(v, param)
+			}
+			msg = fmt.Sprintf(msg, param)
+		}
+		fields = append(fields, models.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: msg,
+		})
+	}
+	s.writeValidationProblem(fields, w, r)
+	return false
+}
+
+// jsonFieldName returns the JSON name of the field of struct v, the field rules take Go names as params.
+func jsonFieldName(v any, field string) string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if f, ok := t.FieldByName(field); ok {
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(field)
+}
@@ -0,0 +1,214 @@
+package gophermartgrpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/NStegura/gophermart/api/gophermart/v1"
+	"github.com/NStegura/gophermart/internal/app/gophermartapi/utils"
+	"github.com/NStegura/gophermart/internal/customerrors"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+)
+
+func (s *GRPCServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if err := utils.CheckCredentials(req.GetLogin(), req.GetPassword()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	newPass, err := s.auth.GeneratePasswordHash(req.GetPassword())
+	if errors.Is(err, customerrors.ErrPasswordTooLong) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+
+	uID, err := s.business.CreateUser(ctx, req.GetLogin(), newPass, req.GetReferralCode())
+	if err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrAlreadyExists):
+			return nil, status.Error(codes.AlreadyExists, customerrors.ErrAlreadyExists.Error())
+		case errors.Is(err, customerrors.ErrReferralInvalid),
+			errors.Is(err, customerrors.ErrReferralLimit):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		default:
+			return nil, s.internalErr(err)
+		}
+	}
+
+	token, err := s.auth.GenerateToken(uID)
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+	return &pb.RegisterResponse{Token: token}, nil
+}
+
+func (s *GRPCServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	dbUser, err := s.business.GetUserByLogin(ctx, req.GetLogin())
+	if err != nil && !errors.Is(err, customerrors.ErrNotFound) {
+		return nil, s.internalErr(err)
+	}
+	if err != nil || !s.auth.CheckPasswordHash(req.GetPassword(), dbUser.Password) {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+
+	token, err := s.auth.GenerateToken(dbUser.ID)
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+	return &pb.LoginResponse{Token: token}, nil
+}
+
+func (s *GRPCServer) UploadOrder(ctx context.Context, req *pb.UploadOrderRequest) (*pb.UploadOrderResponse, error) {
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orderUID, err := parseOrder(req.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.business.CreateOrder(ctx, userID, orderUID); err != nil {
+		switch {
+		case errors.Is(err, customerrors.ErrCurrUserUploaded):
+			return &pb.UploadOrderResponse{AlreadyUploaded: true}, nil
+		case errors.Is(err, customerrors.ErrAnotherUserUploaded):
+			return nil, status.Error(codes.AlreadyExists, customerrors.ErrAnotherUserUploaded.Error())
+		default:
+			return nil, s.internalErr(err)
+		}
+	}
+	return &pb.UploadOrderResponse{}, nil
+}
+
+func (s *GRPCServer) ListOrders(ctx context.Context, _ *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domenOrders, err := s.business.GetOrders(ctx, userID, domenModels.ListFilter{})
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.Order, 0, len(domenOrders))}
+	for _, o := range domenOrders {
+		order := &pb.Order{
+			Number:     strconv.FormatInt(o.Number, 10),
+			Status:     o.Status,
+			Accrual:    o.Accrual,
+			UploadedAt: timestamppb.New(o.UploadedAt),
+			CheckedAt:  timestamppb.New(o.CheckedAt),
+		}
+		if o.ProcessedAt != nil {
+			order.ProcessedAt = timestamppb.New(*o.ProcessedAt)
+		}
+		resp.Orders = append(resp.Orders, order)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetBalance(ctx context.Context, _ *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domenUser, err := s.business.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+	return &pb.GetBalanceResponse{
+		Current:   domenUser.Balance,
+		Withdrawn: domenUser.Withdrawn,
+		Held:      domenUser.Held,
+		Available: domenUser.Balance - domenUser.Held,
+	}, nil
+}
+
+func (s *GRPCServer) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orderUID, err := parseOrder(req.GetOrder())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetSum() <= 0 || !utils.ValidCents(req.GetSum()) {
+		return nil, status.Error(codes.InvalidArgument, "sum must be positive with at most two decimal places")
+	}
+
+	if err = s.business.CreateWithdraw(ctx, userID, orderUID, req.GetSum()); err != nil {
+		return nil, s.withdrawErr(err)
+	}
+	return &pb.WithdrawResponse{}, nil
+}
+
+func (s *GRPCServer) ListWithdrawals(
+	ctx context.Context,
+	_ *pb.ListWithdrawalsRequest,
+) (*pb.ListWithdrawalsResponse, error) {
+	userID, err := s.getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domenWithdrawals, err := s.business.GetWithdrawals(ctx, userID, domenModels.ListFilter{})
+	if err != nil {
+		return nil, s.internalErr(err)
+	}
+
+	resp := &pb.ListWithdrawalsResponse{Withdrawals: make([]*pb.Withdrawal, 0, len(domenWithdrawals))}
+	for _, w := range domenWithdrawals {
+		resp.Withdrawals = append(resp.Withdrawals, &pb.Withdrawal{
+			Order:       strconv.FormatInt(w.OrderID, 10),
+			Sum:         w.Sum,
+			ProcessedAt: timestamppb.New(w.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+func parseOrder(number string) (int64, error) {
+	orderUID, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || !utils.Valid(orderUID) {
+		return 0, status.Error(codes.InvalidArgument, "invalid order format")
+	}
+	return orderUID, nil
+}
+
+func (s *GRPCServer) withdrawErr(err error) error {
+	switch {
+	case errors.Is(err, customerrors.ErrInvalidSum),
+		errors.Is(err, customerrors.ErrWithdrawBelowMin),
+		errors.Is(err, customerrors.ErrWithdrawAboveMax):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, customerrors.ErrNotEnoughFunds):
+		return status.Error(codes.FailedPrecondition, customerrors.ErrNotEnoughFunds.Error())
+	case errors.Is(err, customerrors.ErrWithdrawDailyCap),
+		errors.Is(err, customerrors.ErrWithdrawMonthlyCap),
+		errors.Is(err, customerrors.ErrWithdrawRateLimit):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, customerrors.ErrWithdrawCoolingOff),
+		errors.Is(err, customerrors.ErrUserBlocked):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return s.internalErr(err)
+	}
+}
+
+// internalErr logs err and hides it from the client.
+func (s *GRPCServer) internalErr(err error) error {
+	s.logger.Error(err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,311 @@
+package gophermartgrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/NStegura/gophermart/api/gophermart/v1"
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/monitoring/logger"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+	mock_gophermartgrpc "github.com/NStegura/gophermart/mocks/app/gophermartgrpc"
+)
+
+const bufSize = 1024 * 1024
+
+type testHelper struct {
+	ctrl         *gomock.Controller
+	server       *GRPCServer
+	conn         *grpc.ClientConn
+	client       pb.GophermartServiceClient
+	mockBusiness *mock_gophermartgrpc.MockBusiness
+	mockAuth     *mock_gophermartgrpc.MockAuth
+}
+
+func initTestHelper(t *testing.T) *testHelper {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	cfglog, _ := logger.Init("info")
+	mockBusiness := mock_gophermartgrpc.NewMockBusiness(ctrl)
+	mockAuth := mock_gophermartgrpc.NewMockAuth(ctrl)
+
+	server := New(":9090", mockBusiness, mockAuth, nil, ratelimit.Limit{}, cfglog)
+
+	lis := bufconn.Listen(bufSize)
+	go func() {
+		if err := server.server.Serve(lis); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	return &testHelper{
+		ctrl:         ctrl,
+		server:       server,
+		conn:         conn,
+		client:       pb.NewGophermartServiceClient(conn),
+		mockBusiness: mockBusiness,
+		mockAuth:     mockAuth,
+	}
+}
+
+func (th *testHelper) authCtx() context.Context {
+	th.mockAuth.EXPECT().ParseToken("auth header").Return(int64(1), nil)
+	return metadata.AppendToOutgoingContext(context.Background(), authMetadata, "auth header")
+}
+
+func (th *testHelper) finish() {
+	_ = th.conn.Close()
+	th.server.Stop()
+	th.ctrl.Finish()
+}
+
+func TestGRPC_Register(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "Ok",
+			err:          nil,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "Already exists",
+			err:          customerrors.ErrAlreadyExists,
+			expectedCode: codes.AlreadyExists,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			th.mockAuth.EXPECT().GeneratePasswordHash("password").Return("newPass", nil)
+			th.mockBusiness.EXPECT().CreateUser(gomock.Any(), "login", "newPass", "").Return(int64(1), test.err)
+			if test.err == nil {
+				th.mockAuth.EXPECT().GenerateToken(int64(1)).Return("token", nil)
+			}
+
+			resp, err := th.client.Register(context.Background(), &pb.RegisterRequest{
+				Login:    "login",
+				Password: "password",
+			})
+
+			require.Equal(t, test.expectedCode, status.Code(err))
+			if test.err == nil {
+				require.Equal(t, "token", resp.GetToken())
+			}
+		})
+	}
+}
+
+func TestGRPC_Register__invalid(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	for _, req := range []*pb.RegisterRequest{
+		{Login: "in valid", Password: "password"},
+		{Login: "login", Password: "short"},
+		{Login: "login", Password: strings.Repeat("é", 40)},
+	} {
+		_, err := th.client.Register(context.Background(), req)
+
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestGRPC_rateLimit(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	mockLimiter := mock_gophermartgrpc.NewMockRateLimiter(th.ctrl)
+	th.server.limiter = mockLimiter
+	th.server.authLimit = ratelimit.Limit{Requests: 1, Window: time.Minute}
+
+	t.Run("Login is limited by peer IP", func(t *testing.T) {
+		mockLimiter.EXPECT().Take(gomock.Any(), "auth:ip:bufconn", th.server.authLimit).
+			Return(ratelimit.Result{Allowed: false}, nil)
+
+		_, err := th.client.Login(context.Background(), &pb.LoginRequest{Login: "login", Password: "password"})
+
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("Limiter failure lets calls through", func(t *testing.T) {
+		mockLimiter.EXPECT().Take(gomock.Any(), "auth:ip:bufconn", th.server.authLimit).
+			Return(ratelimit.Result{}, errors.New("db is down"))
+		th.mockBusiness.EXPECT().GetUserByLogin(gomock.Any(), "login").
+			Return(domenModels.User{}, customerrors.ErrNotFound)
+
+		_, err := th.client.Login(context.Background(), &pb.LoginRequest{Login: "login", Password: "password"})
+
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Other methods are not limited", func(t *testing.T) {
+		th.mockBusiness.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(domenModels.User{}, nil)
+
+		_, err := th.client.GetBalance(th.authCtx(), &pb.GetBalanceRequest{})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestGRPC_Login__unauthenticated(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	th.mockBusiness.EXPECT().GetUserByLogin(gomock.Any(), "login").Return(domenModels.User{}, customerrors.ErrNotFound)
+
+	_, err := th.client.Login(context.Background(), &pb.LoginRequest{Login: "login", Password: "password"})
+
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPC_Auth__no_token(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	_, err := th.client.GetBalance(context.Background(), &pb.GetBalanceRequest{})
+
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPC_UploadOrder(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name            string
+		number          string
+		err             error
+		callBusiness    bool
+		expectedCode    codes.Code
+		alreadyUploaded bool
+	}{
+		{
+			name:         "Ok",
+			number:       "12345678903",
+			callBusiness: true,
+			expectedCode: codes.OK,
+		},
+		{
+			name:            "Uploaded by current user",
+			number:          "12345678903",
+			err:             customerrors.ErrCurrUserUploaded,
+			callBusiness:    true,
+			expectedCode:    codes.OK,
+			alreadyUploaded: true,
+		},
+		{
+			name:         "Uploaded by another user",
+			number:       "12345678903",
+			err:          customerrors.ErrAnotherUserUploaded,
+			callBusiness: true,
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name:         "Invalid number",
+			number:       "12345678904",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := th.authCtx()
+			if test.callBusiness {
+				th.mockBusiness.EXPECT().CreateOrder(gomock.Any(), int64(1), int64(12345678903)).Return(test.err)
+			}
+
+			resp, err := th.client.UploadOrder(ctx, &pb.UploadOrderRequest{Number: test.number})
+
+			require.Equal(t, test.expectedCode, status.Code(err))
+			require.Equal(t, test.alreadyUploaded, resp.GetAlreadyUploaded())
+		})
+	}
+}
+
+func TestGRPC_ListOrders(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	uploadedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	th.mockBusiness.EXPECT().GetOrders(gomock.Any(), int64(1), domenModels.ListFilter{}).Return([]domenModels.Order{
+		{Number: 12345678903, Status: "PROCESSED", Accrual: 500, UploadedAt: uploadedAt},
+	}, nil)
+
+	resp, err := th.client.ListOrders(th.authCtx(), &pb.ListOrdersRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, resp.GetOrders(), 1)
+	require.Equal(t, "12345678903", resp.GetOrders()[0].GetNumber())
+	require.Equal(t, "PROCESSED", resp.GetOrders()[0].GetStatus())
+	require.Equal(t, float64(500), resp.GetOrders()[0].GetAccrual())
+	require.True(t, uploadedAt.Equal(resp.GetOrders()[0].GetUploadedAt().AsTime()))
+}
+
+func TestGRPC_Withdraw(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode codes.Code
+	}{
+		{name: "Ok", expectedCode: codes.OK},
+		{name: "Not enough funds", err: customerrors.ErrNotEnoughFunds, expectedCode: codes.FailedPrecondition},
+		{name: "Invalid sum", err: customerrors.ErrInvalidSum, expectedCode: codes.InvalidArgument},
+		{name: "Daily cap", err: customerrors.ErrWithdrawDailyCap, expectedCode: codes.ResourceExhausted},
+		{name: "Blocked", err: customerrors.ErrUserBlocked, expectedCode: codes.PermissionDenied},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := th.authCtx()
+			th.mockBusiness.EXPECT().CreateWithdraw(gomock.Any(), int64(1), int64(12345678903), float64(100)).
+				Return(test.err)
+
+			_, err := th.client.Withdraw(ctx, &pb.WithdrawRequest{Order: "12345678903", Sum: 100})
+
+			require.Equal(t, test.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestGRPC_Withdraw__invalidSum(t *testing.T) {
+	th := initTestHelper(t)
+	defer th.finish()
+
+	for _, sum := range []float64{0, -1, 1.005} {
+		_, err := th.client.Withdraw(th.authCtx(), &pb.WithdrawRequest{Order: "12345678903", Sum: sum})
+
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
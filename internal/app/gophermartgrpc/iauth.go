@@ -0,0 +1,8 @@
+package gophermartgrpc
+
+type Auth interface {
+	GenerateToken(userID int64) (string, error)
+	ParseToken(accessToken string) (int64, error)
+	GeneratePasswordHash(password string) (string, error)
+	CheckPasswordHash(password, hash string) bool
+}
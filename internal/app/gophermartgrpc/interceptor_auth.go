@@ -0,0 +1,67 @@
+package gophermartgrpc
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/NStegura/gophermart/api/gophermart/v1"
+)
+
+type ctxUserID struct{}
+
+const (
+	authMetadata = "authorization"
+)
+
+var publicMethods = map[string]struct{}{
+	pb.GophermartService_Register_FullMethodName: {},
+	pb.GophermartService_Login_FullMethodName:    {},
+}
+
+// authInterceptor is the gRPC counterpart of the HTTP authMiddleware.
+func (s *GRPCServer) authInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if _, ok := publicMethods[info.FullMethod]; ok {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authMetadata)
+	if len(values) == 0 || values[0] == "" {
+		s.logger.Debugln("Auth metadata not set")
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	userID, err := s.auth.ParseToken(values[0])
+	if err != nil {
+		s.logger.Debugf("ParseToken failed: %s", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Key("userID").String(strconv.FormatInt(userID, 10)),
+	)
+
+	s.logger.Debugf("Authorize USER.ID=%v", userID)
+	return handler(context.WithValue(ctx, ctxUserID{}, userID), req)
+}
+
+func (s *GRPCServer) getUserID(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value(ctxUserID{}).(int64)
+	if !ok {
+		s.logger.Warning("user id not found in context")
+		return 0, status.Error(codes.Unauthenticated, "user id not found in context")
+	}
+	return userID, nil
+}
@@ -0,0 +1,47 @@
+package gophermartgrpc
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimitInterceptor is the gRPC counterpart of the HTTP auth rate limit, Register and Login
+// are counted by peer IP in the same group, so both APIs share the budget.
+// The limiter failure lets the call through.
+func (s *GRPCServer) rateLimitInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if _, ok := publicMethods[info.FullMethod]; !ok || s.limiter == nil || !s.authLimit.Enabled() {
+		return handler(ctx, req)
+	}
+
+	res, err := s.limiter.Take(ctx, "auth:"+peerIP(ctx), s.authLimit)
+	if err != nil {
+		s.logger.Errorf("rate limiter failed: %s", err)
+		return handler(ctx, req)
+	}
+	if !res.Allowed {
+		return nil, status.Error(codes.ResourceExhausted, "too many requests")
+	}
+	return handler(ctx, req)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "ip:unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,11 @@
+package gophermartgrpc
+
+import (
+	"context"
+
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (res ratelimit.Result, err error)
+}
@@ -0,0 +1,69 @@
+package gophermartgrpc
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+
+	pb "github.com/NStegura/gophermart/api/gophermart/v1"
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+type GRPCServer struct {
+	pb.UnimplementedGophermartServiceServer
+
+	address   string
+	business  Business
+	auth      Auth
+	limiter   RateLimiter
+	authLimit ratelimit.Limit
+
+	server *grpc.Server
+
+	logger *logrus.Logger
+}
+
+// New takes the limit of Register and Login, zero value or nil limiter disables it.
+func New(
+	address string,
+	business Business,
+	auth Auth,
+	limiter RateLimiter,
+	authLimit ratelimit.Limit,
+	logger *logrus.Logger,
+) *GRPCServer {
+	s := &GRPCServer{
+		address:   address,
+		business:  business,
+		auth:      auth,
+		limiter:   limiter,
+		authLimit: authLimit,
+		logger:    logger,
+	}
+	s.server = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(s.rateLimitInterceptor, s.authInterceptor),
+	)
+	pb.RegisterGophermartServiceServer(s.server, s)
+	return s
+}
+
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen %s: %w", s.address, err)
+	}
+
+	s.logger.Infof("starting GRPCServer %s", s.address)
+	if err = s.server.Serve(lis); err != nil {
+		return fmt.Errorf("failed to start grpc server: %w", err)
+	}
+	return nil
+}
+
+func (s *GRPCServer) Stop() {
+	s.server.GracefulStop()
+}
@@ -0,0 +1,454 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/NStegura/gophermart/internal/services/jobs/reconciliation"
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+const (
+	// DefaultSecretKey is only for tests and dev mode, Validate refuses it otherwise.
+	DefaultSecretKey = "gljfsj;312sf;kdhrf;"
+
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+
+	DatabaseDriverPostgres = "postgres"
+	// DatabaseDriverMemory keeps the data in the process memory, for demos and tests.
+	DatabaseDriverMemory = "memory"
+
+	configFileEnv = "CONFIG_FILE"
+	// secretFileSuffix marks the env var with the path of a file holding the secret, like Docker secrets.
+	secretFileSuffix = "_FILE"
+)
+
+// secretEnvs can be read from files by their *_FILE env vars.
+var secretEnvs = []string{"SECRET_KEY", "VERIFICATION_KEYS", "DATABASE_URI", "ADMIN_KEY"}
+
+const (
+	defaultLogLevel        = "debug"
+	defaultShutdownTimeout = 10 * time.Second
+
+	defaultHTTPAddress     = ":8080"
+	defaultGRPCAddress     = ":9090"
+	defaultResponseTimeout = time.Minute
+	defaultCompressMinSize = 1024
+	defaultRateLimitAuth   = 10
+	defaultRateLimitAPI    = 600
+	defaultRateLimitOrders = 60
+
+	defaultTokenTTL   = 72 * time.Hour
+	defaultBcryptCost = 14
+
+	defaultAccrualAddress  = "accrual-api:8082"
+	defaultAccrualSyncFreq = 15 * time.Second
+	defaultAccrualWorkers  = 5
+
+	defaultTierWindow      = 90 * 24 * time.Hour
+	defaultTierRecalcFreq  = 3 * time.Hour
+	defaultHoldTTL         = 15 * time.Minute
+	defaultHoldExpiryFreq  = 30 * time.Second
+	defaultWebhookFreq     = 5 * time.Second
+	defaultWebhookTimeout  = 10 * time.Second
+	defaultOutboxFreq      = 5 * time.Second
+	defaultOutboxRetention = 7 * 24 * time.Hour
+	defaultEventsTimeout   = 10 * time.Second
+	defaultEventsRetry     = 5 * time.Second
+	defaultEventsRetention = 7 * 24 * time.Hour
+	defaultEventsCleanFreq = time.Hour
+
+	defaultTransferDailyLimit     = 10000
+	defaultTransferDailyCount     = 10
+	defaultReferralMaxPerReferrer = 50
+	defaultReferrerBonus          = 100
+	defaultRefereeBonus           = 50
+
+	defaultWithdrawMaxSum      = 100000
+	defaultWithdrawDailyCap    = 100000
+	defaultWithdrawMonthlyCap  = 1000000
+	defaultWithdrawHourlyCount = 60
+
+	defaultReconciliationFreq      = time.Hour
+	defaultReconciliationTolerance = 0.005
+)
+
+type Config struct {
+	ConfigFile string `yaml:"-"`
+
+	Dev             bool          `yaml:"dev"`
+	LogLevel        string        `yaml:"log_level"`
+	TracerURL       string        `yaml:"tracer_url"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	HTTP        HTTP        `yaml:"http"`
+	GRPC        GRPC        `yaml:"grpc"`
+	Database    Database    `yaml:"database"`
+	Auth        Auth        `yaml:"auth"`
+	Accrual     Accrual     `yaml:"accrual"`
+	Tiers       Tiers       `yaml:"tiers"`
+	Holds       Holds       `yaml:"holds"`
+	Webhooks    Webhooks    `yaml:"webhooks"`
+	Outbox      Outbox      `yaml:"outbox"`
+	Events      Events      `yaml:"events"`
+	Transfers   Transfers   `yaml:"transfers"`
+	Referrals   Referrals   `yaml:"referrals"`
+	Withdrawals Withdrawals `yaml:"withdrawals"`
+
+	Reconciliation Reconciliation `yaml:"reconciliation"`
+}
+
+type HTTP struct {
+	Address         string        `yaml:"address"`
+	AdminKey        string        `yaml:"admin_key"`
+	ResponseTimeout time.Duration `yaml:"response_timeout"`
+	CompressMinSize int           `yaml:"compress_min_size"`
+	RateLimit       RateLimit     `yaml:"rate_limit"`
+	// TrustedProxies are the addresses or CIDRs of the proxies allowed to set X-Forwarded-For.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// Proxies parses TrustedProxies, a single address is a prefix of its full length.
+func (h HTTP) Proxies() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(h.TrustedProxies))
+	for _, proxy := range h.TrustedProxies {
+		if strings.Contains(proxy, "/") {
+			p, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse trusted proxy, %w", err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trusted proxy, %w", err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+type RateLimit struct {
+	Store  string          `yaml:"store"`
+	Auth   ratelimit.Limit `yaml:"auth"`
+	API    ratelimit.Limit `yaml:"api"`
+	Orders ratelimit.Limit `yaml:"orders"`
+}
+
+type GRPC struct {
+	Address string `yaml:"address"`
+}
+
+type Database struct {
+	Driver string `yaml:"driver"`
+	URI    string `yaml:"uri"`
+	// MaxConns is the pool size, zero keeps the pgx default.
+	MaxConns int `yaml:"max_conns"`
+}
+
+type Auth struct {
+	SecretKey string `yaml:"secret_key"`
+	// VerificationKeys are the previous secret keys, tokens signed by them are still accepted.
+	VerificationKeys []string      `yaml:"verification_keys"`
+	TokenTTL         time.Duration `yaml:"token_ttl"`
+	BcryptCost       int           `yaml:"bcrypt_cost"`
+}
+
+type Accrual struct {
+	Address       string        `yaml:"address"`
+	SyncFrequency time.Duration `yaml:"sync_frequency"`
+	SyncWorkers   int           `yaml:"sync_workers"`
+}
+
+type Tiers struct {
+	Window          time.Duration `yaml:"window"`
+	RecalcFrequency time.Duration `yaml:"recalc_frequency"`
+}
+
+type Holds struct {
+	TTL             time.Duration `yaml:"ttl"`
+	ExpiryFrequency time.Duration `yaml:"expiry_frequency"`
+}
+
+type Webhooks struct {
+	Frequency time.Duration `yaml:"frequency"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+type Outbox struct {
+	Frequency time.Duration `yaml:"frequency"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+type Events struct {
+	File    string        `yaml:"file"`
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry is the delay before the user events listener reconnects.
+	Retry time.Duration `yaml:"retry"`
+	// Retention is how long user events stay available to resume the stream.
+	Retention        time.Duration `yaml:"retention"`
+	CleanupFrequency time.Duration `yaml:"cleanup_frequency"`
+}
+
+type Transfers struct {
+	DailyLimit float64 `yaml:"daily_limit"`
+	DailyCount int64   `yaml:"daily_count"`
+}
+
+type Referrals struct {
+	MaxPerReferrer int64   `yaml:"max_per_referrer"`
+	ReferrerBonus  float64 `yaml:"referrer_bonus"`
+	RefereeBonus   float64 `yaml:"referee_bonus"`
+}
+
+// Withdrawals configures withdrawrules, zero value of a limit disables its rule.
+// MinSum and CoolingOff are opt-in, they default to zero so a new user can spend the first accrual at once.
+type Withdrawals struct {
+	MinSum      float64       `yaml:"min_sum"`
+	MaxSum      float64       `yaml:"max_sum"`
+	DailyCap    float64       `yaml:"daily_cap"`
+	MonthlyCap  float64       `yaml:"monthly_cap"`
+	HourlyCount int64         `yaml:"hourly_count"`
+	CoolingOff  time.Duration `yaml:"cooling_off"`
+}
+
+// Reconciliation configures the balance reconciliation job, mode is report, dry-run or apply.
+type Reconciliation struct {
+	Frequency time.Duration `yaml:"frequency"`
+	Mode      string        `yaml:"mode"`
+	Tolerance float64       `yaml:"tolerance"`
+}
+
+func Default() *Config {
+	return &Config{
+		LogLevel:        defaultLogLevel,
+		ShutdownTimeout: defaultShutdownTimeout,
+		HTTP: HTTP{
+			Address:         defaultHTTPAddress,
+			ResponseTimeout: defaultResponseTimeout,
+			CompressMinSize: defaultCompressMinSize,
+			RateLimit: RateLimit{
+				Store:  RateLimitStoreMemory,
+				Auth:   ratelimit.Limit{Requests: defaultRateLimitAuth, Window: time.Minute},
+				API:    ratelimit.Limit{Requests: defaultRateLimitAPI, Window: time.Minute},
+				Orders: ratelimit.Limit{Requests: defaultRateLimitOrders, Window: time.Minute},
+			},
+		},
+		GRPC:     GRPC{Address: defaultGRPCAddress},
+		Database: Database{Driver: DatabaseDriverPostgres},
+		Auth: Auth{
+			SecretKey:  DefaultSecretKey,
+			TokenTTL:   defaultTokenTTL,
+			BcryptCost: defaultBcryptCost,
+		},
+		Accrual: Accrual{
+			Address:       defaultAccrualAddress,
+			SyncFrequency: defaultAccrualSyncFreq,
+			SyncWorkers:   defaultAccrualWorkers,
+		},
+		Tiers:    Tiers{Window: defaultTierWindow, RecalcFrequency: defaultTierRecalcFreq},
+		Holds:    Holds{TTL: defaultHoldTTL, ExpiryFrequency: defaultHoldExpiryFreq},
+		Webhooks: Webhooks{Frequency: defaultWebhookFreq, Timeout: defaultWebhookTimeout},
+		Outbox:   Outbox{Frequency: defaultOutboxFreq, Retention: defaultOutboxRetention},
+		Events: Events{
+			Timeout:          defaultEventsTimeout,
+			Retry:            defaultEventsRetry,
+			Retention:        defaultEventsRetention,
+			CleanupFrequency: defaultEventsCleanFreq,
+		},
+		Transfers: Transfers{
+			DailyLimit: defaultTransferDailyLimit,
+			DailyCount: defaultTransferDailyCount,
+		},
+		Referrals: Referrals{
+			MaxPerReferrer: defaultReferralMaxPerReferrer,
+			ReferrerBonus:  defaultReferrerBonus,
+			RefereeBonus:   defaultRefereeBonus,
+		},
+		Withdrawals: Withdrawals{
+			MaxSum:      defaultWithdrawMaxSum,
+			DailyCap:    defaultWithdrawDailyCap,
+			MonthlyCap:  defaultWithdrawMonthlyCap,
+			HourlyCount: defaultWithdrawHourlyCount,
+		},
+		Reconciliation: Reconciliation{
+			Frequency: defaultReconciliationFreq,
+			Mode:      reconciliation.ModeReport,
+			Tolerance: defaultReconciliationTolerance,
+		},
+	}
+}
+
+// Load layers the defaults, the YAML or JSON config file, env and flags, each one overrides the previous.
+// The config file is -c flag or CONFIG_FILE env, secrets are also read from the files of their *_FILE env.
+//
+// Load is called again on SIGHUP, only the log level, the accrual sync schedule and the auth keys
+// are applied without a restart.
+func Load(args []string) (*Config, error) {
+	c, rest, err := LoadArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q", rest)
+	}
+	return c, nil
+}
+
+// LoadArgs is Load for commands, it stops at the first non-flag argument and returns the rest.
+func LoadArgs(args []string) (*Config, []string, error) {
+	// the first pass only finds the config file, the flags are applied again over the file and env
+	scratch := Default()
+	scratch.ConfigFile = os.Getenv(configFileEnv)
+	if err := scratch.flagSet().Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse flags, %w", err)
+	}
+
+	c := Default()
+	if scratch.ConfigFile != "" {
+		if err := c.loadFile(scratch.ConfigFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	fs := c.flagSet()
+	for name, env := range c.envs() {
+		value, ok, err := lookupEnv(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err = fs.Set(name, value); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s, %w", env, err)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse flags, %w", err)
+	}
+	c.ConfigFile = scratch.ConfigFile
+
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return c, fs.Args(), nil
+}
+
+// lookupEnv returns the env var or, for secrets, the content of the file from its *_FILE env var.
+func lookupEnv(env string) (value string, ok bool, err error) {
+	value, ok = os.LookupEnv(env)
+	if !slices.Contains(secretEnvs, env) {
+		return value, ok, nil
+	}
+	path, fromFile := os.LookupEnv(env + secretFileSuffix)
+	if !fromFile {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("only one of %s and %s%s can be set", env, env, secretFileSuffix)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s%s, %w", env, secretFileSuffix, err)
+	}
+	return strings.TrimSpace(string(data)), true, nil
+}
+
+// loadFile reads YAML, JSON being YAML it is read the same way. Unknown keys are errors.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file, %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err = dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s, %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, err := logrus.ParseLevel(c.LogLevel)
+	check(err == nil, "log_level %q is not a logrus level", c.LogLevel)
+	check(c.ShutdownTimeout > 0, "shutdown_timeout must be positive")
+
+	check(c.HTTP.Address != "", "http.address is required")
+	check(c.HTTP.ResponseTimeout > 0, "http.response_timeout must be positive")
+	check(c.HTTP.CompressMinSize >= 0, "http.compress_min_size must not be negative")
+	check(c.HTTP.RateLimit.Store == RateLimitStoreMemory || c.HTTP.RateLimit.Store == RateLimitStorePostgres,
+		"http.rate_limit.store must be %s or %s", RateLimitStoreMemory, RateLimitStorePostgres)
+	_, err = c.HTTP.Proxies()
+	check(err == nil, "http.trusted_proxies must be addresses or CIDRs, %v", err)
+	check(c.GRPC.Address != "", "grpc.address is required")
+
+	check(c.Database.Driver == DatabaseDriverPostgres || c.Database.Driver == DatabaseDriverMemory,
+		"database.driver must be %s or %s", DatabaseDriverPostgres, DatabaseDriverMemory)
+	check(c.Database.URI != "" || c.Database.Driver != DatabaseDriverPostgres, "database.uri is required")
+	check(c.HTTP.RateLimit.Store != RateLimitStorePostgres || c.Database.Driver == DatabaseDriverPostgres,
+		"http.rate_limit.store %s needs database.driver %s", RateLimitStorePostgres, DatabaseDriverPostgres)
+	check(c.Database.MaxConns >= 0, "database.max_conns must not be negative")
+
+	check(c.Auth.SecretKey != "", "auth.secret_key is required")
+	check(!slices.Contains(c.Auth.VerificationKeys, ""), "auth.verification_keys must not be empty")
+	check(c.Dev || c.Auth.SecretKey != DefaultSecretKey, "auth.secret_key is the test key, set it or enable dev mode")
+	check(c.Auth.TokenTTL > 0, "auth.token_ttl must be positive")
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost,
+		"auth.bcrypt_cost must be from %d to %d", bcrypt.MinCost, bcrypt.MaxCost)
+
+	check(c.Accrual.Address != "", "accrual.address is required")
+	check(c.Accrual.SyncFrequency > 0, "accrual.sync_frequency must be positive")
+	check(c.Accrual.SyncWorkers > 0, "accrual.sync_workers must be positive")
+
+	check(c.Tiers.Window > 0, "tiers.window must be positive")
+	check(c.Tiers.RecalcFrequency > 0, "tiers.recalc_frequency must be positive")
+	check(c.Holds.TTL > 0, "holds.ttl must be positive")
+	check(c.Holds.ExpiryFrequency > 0, "holds.expiry_frequency must be positive")
+	check(c.Webhooks.Frequency > 0, "webhooks.frequency must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Outbox.Frequency > 0, "outbox.frequency must be positive")
+	check(c.Outbox.Retention > 0, "outbox.retention must be positive")
+	check(c.Events.Timeout > 0, "events.timeout must be positive")
+	check(c.Events.Retry > 0, "events.retry must be positive")
+	check(c.Events.Retention > 0, "events.retention must be positive")
+	check(c.Events.CleanupFrequency > 0, "events.cleanup_frequency must be positive")
+
+	check(c.Transfers.DailyLimit >= 0 && c.Transfers.DailyCount >= 0, "transfers limits must not be negative")
+	check(c.Referrals.MaxPerReferrer >= 0 && c.Referrals.ReferrerBonus >= 0 && c.Referrals.RefereeBonus >= 0,
+		"referrals settings must not be negative")
+	w := c.Withdrawals
+	check(w.MinSum >= 0 && w.MaxSum >= 0 && w.DailyCap >= 0 && w.MonthlyCap >= 0 && w.HourlyCount >= 0 &&
+		w.CoolingOff >= 0, "withdrawals limits must not be negative")
+	check(c.Reconciliation.Frequency > 0, "reconciliation.frequency must be positive")
+	check(slices.Contains(reconciliation.Modes, c.Reconciliation.Mode),
+		"reconciliation.mode must be one of %s", strings.Join(reconciliation.Modes, ", "))
+	check(c.Reconciliation.Tolerance >= 0, "reconciliation.tolerance must not be negative")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config, %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func TestLoad_precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+log_level: warn
+http:
+  address: ":8000"
+  response_timeout: 30s
+  rate_limit:
+    api: 100/1m
+database:
+  uri: postgres://file
+  max_conns: 20
+auth:
+  secret_key: file secret
+  bcrypt_cost: 10
+accrual:
+  sync_frequency: 1m
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DATABASE_URI", "postgres://env")
+	t.Setenv("ACCRUAL_SYNC_FREQUENCY", "30s")
+	t.Setenv("RATE_LIMIT_API", "200/1m")
+
+	cfg, err := Load([]string{"-d", "postgres://flag", "-rate-limit-api", "300/1h"})
+	require.NoError(t, err)
+
+	assert.Equal(t, path, cfg.ConfigFile)
+	// file over defaults
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, ":8000", cfg.HTTP.Address)
+	assert.Equal(t, 30*time.Second, cfg.HTTP.ResponseTimeout)
+	assert.Equal(t, 20, cfg.Database.MaxConns)
+	assert.Equal(t, "file secret", cfg.Auth.SecretKey)
+	assert.Equal(t, 10, cfg.Auth.BcryptCost)
+	// env over file
+	assert.Equal(t, 30*time.Second, cfg.Accrual.SyncFrequency)
+	// flags over env
+	assert.Equal(t, "postgres://flag", cfg.Database.URI)
+	assert.Equal(t, ratelimit.Limit{Requests: 300, Window: time.Hour}, cfg.HTTP.RateLimit.API)
+	// defaults are kept
+	assert.Equal(t, Default().HTTP.RateLimit.Auth, cfg.HTTP.RateLimit.Auth)
+	assert.Equal(t, 72*time.Hour, cfg.Auth.TokenTTL)
+	assert.Equal(t, DatabaseDriverPostgres, cfg.Database.Driver)
+	// opt-in rules
+	assert.Zero(t, cfg.Withdrawals.MinSum)
+	assert.Zero(t, cfg.Withdrawals.CoolingOff)
+}
+
+func TestLoad_jsonFile(t *testing.T) {
+	path := writeFile(t, "config.json", `{
+		"dev": true,
+		"database": {"uri": "postgres://json"},
+		"holds": {"ttl": "5m"}
+	}`)
+
+	cfg, err := Load([]string{"-c", path})
+	require.NoError(t, err)
+	assert.True(t, cfg.Dev)
+	assert.Equal(t, "postgres://json", cfg.Database.URI)
+	assert.Equal(t, 5*time.Minute, cfg.Holds.TTL)
+}
+
+func TestLoad_secretFiles(t *testing.T) {
+	t.Setenv("SECRET_KEY_FILE", writeFile(t, "secret_key", "file secret\n"))
+	t.Setenv("VERIFICATION_KEYS_FILE", writeFile(t, "verification_keys", "old secret\nolder secret\n"))
+	t.Setenv("DATABASE_URI_FILE", writeFile(t, "database_uri", "postgres://file"))
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file secret", cfg.Auth.SecretKey)
+	assert.Equal(t, []string{"old secret", "older secret"}, cfg.Auth.VerificationKeys)
+	assert.Equal(t, "postgres://file", cfg.Database.URI)
+
+	t.Run("Flag overrides the file", func(t *testing.T) {
+		cfg, err = Load([]string{"-verification-keys", "flag secret"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"flag secret"}, cfg.Auth.VerificationKeys)
+	})
+
+	t.Run("Env and its file are both set", func(t *testing.T) {
+		t.Setenv("SECRET_KEY", "env secret")
+		_, err = Load(nil)
+		assert.ErrorContains(t, err, "only one of SECRET_KEY and SECRET_KEY_FILE can be set")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Setenv("DATABASE_URI_FILE", filepath.Join(t.TempDir(), "missing"))
+		_, err = Load(nil)
+		assert.ErrorContains(t, err, "failed to read DATABASE_URI_FILE")
+	})
+}
+
+func TestLoad_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{
+			name: "Unknown key in file",
+			file: "database:\n  url: postgres://file\n",
+			args: []string{"-dev"},
+			err:  "field url not found",
+		},
+		{
+			name: "Bad duration in env",
+			env:  map[string]string{"TOKEN_TTL": "3 days"},
+			args: []string{"-dev", "-d", "postgres://"},
+			err:  "failed to parse TOKEN_TTL",
+		},
+		{
+			name: "Test secret key outside dev mode",
+			args: []string{"-d", "postgres://"},
+			err:  "auth.secret_key is the test key",
+		},
+		{
+			name: "Postgres rate limits without Postgres",
+			args: []string{"-dev", "-database-driver", "memory", "-rate-limit-store", "postgres"},
+			err:  "http.rate_limit.store postgres needs database.driver postgres",
+		},
+		{
+			name: "Bad trusted proxy",
+			args: []string{"-dev", "-d", "postgres://", "-trusted-proxies", "10.0.0.0/8,proxy.local"},
+			err:  "http.trusted_proxies must be addresses or CIDRs",
+		},
+		{
+			name: "Every invalid setting is reported",
+			args: []string{"-dev", "-l", "loud", "-bcrypt-cost", "100", "-rate-limit-store", "redis"},
+			err:  "log_level \"loud\" is not a logrus level",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-c", writeFile(t, "config.yaml", tt.file)}, args...)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load(args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	t.Run("Validate joins errors", func(t *testing.T) {
+		cfg := Default()
+		cfg.Dev = true
+		cfg.Auth.BcryptCost = 100
+		cfg.HTTP.RateLimit.Store = "redis"
+		cfg.Reconciliation.Mode = "fix"
+
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database.uri is required")
+		assert.Contains(t, err.Error(), "auth.bcrypt_cost must be from 4 to 31")
+		assert.Contains(t, err.Error(), "http.rate_limit.store must be memory or postgres")
+		assert.Contains(t, err.Error(), "reconciliation.mode must be one of report, dry-run, apply")
+	})
+}
+
+func TestHTTP_Proxies(t *testing.T) {
+	proxies, err := HTTP{TrustedProxies: []string{"10.1.2.3/8", "192.168.0.1", "::ffff:172.16.0.1"}}.Proxies()
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.0.1/32"),
+		netip.MustParsePrefix("172.16.0.1/32"),
+	}, proxies)
+}
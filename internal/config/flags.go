@@ -0,0 +1,167 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/services/ratelimit"
+)
+
+// stringList is a comma or newline separated flag, a set replaces the whole list.
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	for i := range *l {
+		(*l)[i] = strings.TrimSpace((*l)[i])
+	}
+	return nil
+}
+
+// binder registers flags bound to the config fields, with the current values as defaults,
+// and remembers the env var of every flag.
+type binder struct {
+	fs   *flag.FlagSet
+	envs map[string]string
+}
+
+func (b binder) string(p *string, name, env, usage string) {
+	b.fs.StringVar(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) bool(p *bool, name, env, usage string) {
+	b.fs.BoolVar(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) int(p *int, name, env, usage string) {
+	b.fs.IntVar(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) int64(p *int64, name, env, usage string) {
+	b.fs.Int64Var(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) float(p *float64, name, env, usage string) {
+	b.fs.Float64Var(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) duration(p *time.Duration, name, env, usage string) {
+	b.fs.DurationVar(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) limit(p *ratelimit.Limit, name, env, usage string) {
+	b.fs.TextVar(p, name, *p, usage)
+	b.env(name, env)
+}
+
+func (b binder) list(p *[]string, name, env, usage string) {
+	b.fs.Var((*stringList)(p), name, usage)
+	b.env(name, env)
+}
+
+func (b binder) env(name, env string) {
+	if env != "" {
+		b.envs[name] = env
+	}
+}
+
+func (c *Config) flagSet() *flag.FlagSet {
+	fs, _ := c.bind()
+	return fs
+}
+
+func (c *Config) envs() map[string]string {
+	_, envs := c.bind()
+	return envs
+}
+
+func (c *Config) bind() (*flag.FlagSet, map[string]string) {
+	b := binder{fs: flag.NewFlagSet(os.Args[0], flag.ContinueOnError), envs: make(map[string]string)}
+
+	b.string(&c.ConfigFile, "c", "", "YAML or JSON config file, env "+configFileEnv)
+	b.bool(&c.Dev, "dev", "DEV", "dev mode, allows the test secret key")
+	b.string(&c.LogLevel, "l", "LOG_LEVEL", "log level")
+	b.string(&c.TracerURL, "t", "TRACER_URL", "tracer collector url")
+	b.duration(&c.ShutdownTimeout, "shutdown-timeout", "SHUTDOWN_TIMEOUT", "graceful shutdown timeout")
+
+	b.string(&c.HTTP.Address, "a", "RUN_ADDRESS", "address and port to run server")
+	b.string(&c.HTTP.AdminKey, "admin-key", "ADMIN_KEY", "admin API key, empty disables the admin API")
+	b.duration(&c.HTTP.ResponseTimeout, "response-timeout", "RESPONSE_TIMEOUT", "API response timeout")
+	b.int(&c.HTTP.CompressMinSize, "compress-min-size", "COMPRESS_MIN_SIZE", "smallest response body to compress")
+	b.string(&c.HTTP.RateLimit.Store, "rate-limit-store", "RATE_LIMIT_STORE", "rate limit store, memory or postgres")
+	b.limit(&c.HTTP.RateLimit.Auth, "rate-limit-auth", "RATE_LIMIT_AUTH", "register and login limit by IP, like 10/1m")
+	b.limit(&c.HTTP.RateLimit.API, "rate-limit-api", "RATE_LIMIT_API", "API limit by user, like 600/1m")
+	b.limit(&c.HTTP.RateLimit.Orders, "rate-limit-orders", "RATE_LIMIT_ORDERS", "order upload limit by user")
+	b.list(&c.HTTP.TrustedProxies, "trusted-proxies", "TRUSTED_PROXIES",
+		"proxy addresses or CIDRs allowed to set X-Forwarded-For, comma or newline separated")
+	b.string(&c.GRPC.Address, "grpc-address", "GRPC_ADDRESS", "address and port to run grpc server")
+
+	b.string(&c.Database.Driver, "database-driver", "DATABASE_DRIVER", "database driver, postgres or memory")
+	b.string(&c.Database.URI, "d", "DATABASE_URI", "database dsn")
+	b.int(&c.Database.MaxConns, "database-max-conns", "DATABASE_MAX_CONNS", "database pool size, 0 is pgx default")
+
+	b.string(&c.Auth.SecretKey, "s", "SECRET_KEY", "secret key to hash auth")
+	b.list(&c.Auth.VerificationKeys, "verification-keys", "VERIFICATION_KEYS",
+		"previous secret keys still accepted for tokens, comma or newline separated")
+	b.duration(&c.Auth.TokenTTL, "token-ttl", "TOKEN_TTL", "auth token lifetime")
+	b.int(&c.Auth.BcryptCost, "bcrypt-cost", "BCRYPT_COST", "password hash cost")
+
+	b.string(&c.Accrual.Address, "r", "ACCRUAL_SYSTEM_ADDRESS", "address and port accrual cli")
+	b.duration(&c.Accrual.SyncFrequency, "accrual-sync-frequency", "ACCRUAL_SYNC_FREQUENCY", "order sync period")
+	b.int(&c.Accrual.SyncWorkers, "accrual-sync-workers", "ACCRUAL_SYNC_WORKERS", "concurrent accrual requests")
+
+	b.duration(&c.Tiers.Window, "tier-window", "TIER_WINDOW", "accrual volume window of loyalty tiers")
+	b.duration(&c.Tiers.RecalcFrequency, "tier-recalc-frequency", "TIER_RECALC_FREQUENCY", "tier recalc period")
+	b.duration(&c.Holds.TTL, "hold-ttl", "HOLD_TTL", "withdraw hold lifetime")
+	b.duration(&c.Holds.ExpiryFrequency, "hold-expiry-frequency", "HOLD_EXPIRY_FREQUENCY", "hold expiry period")
+	b.duration(&c.Webhooks.Frequency, "webhook-frequency", "WEBHOOK_FREQUENCY", "webhook delivery period")
+	b.duration(&c.Webhooks.Timeout, "webhook-timeout", "WEBHOOK_TIMEOUT", "webhook request timeout")
+	b.duration(&c.Outbox.Frequency, "outbox-frequency", "OUTBOX_FREQUENCY", "outbox relay period")
+	b.duration(&c.Outbox.Retention, "outbox-retention", "OUTBOX_RETENTION", "dispatched outbox events retention")
+	b.string(&c.Events.File, "events-file", "EVENTS_FILE", "file to append domain events to")
+	b.string(&c.Events.URL, "events-url", "EVENTS_URL", "url to post domain events to")
+	b.duration(&c.Events.Timeout, "events-timeout", "EVENTS_TIMEOUT", "domain events request timeout")
+	b.duration(&c.Events.Retry, "events-retry", "EVENTS_RETRY", "user events listener reconnect delay")
+	b.duration(&c.Events.Retention, "events-retention", "EVENTS_RETENTION", "user events retention")
+	b.duration(&c.Events.CleanupFrequency, "events-cleanup-frequency", "EVENTS_CLEANUP_FREQUENCY",
+		"user events cleanup period")
+
+	b.float(&c.Transfers.DailyLimit, "transfer-daily-limit", "TRANSFER_DAILY_LIMIT", "daily transfer sum")
+	b.int64(&c.Transfers.DailyCount, "transfer-daily-count", "TRANSFER_DAILY_COUNT", "daily transfer count")
+	b.int64(&c.Referrals.MaxPerReferrer, "referral-max", "REFERRAL_MAX_PER_REFERRER", "rewarded referrals per user")
+	b.float(&c.Referrals.ReferrerBonus, "referrer-bonus", "REFERRER_BONUS", "referrer bonus")
+	b.float(&c.Referrals.RefereeBonus, "referee-bonus", "REFEREE_BONUS", "referee bonus")
+	b.float(&c.Withdrawals.MinSum, "withdraw-min-sum", "WITHDRAW_MIN_SUM", "minimal withdrawal, 0 disables")
+	b.float(&c.Withdrawals.MaxSum, "withdraw-max-sum", "WITHDRAW_MAX_SUM", "maximal withdrawal")
+	b.float(&c.Withdrawals.DailyCap, "withdraw-daily-cap", "WITHDRAW_DAILY_CAP", "daily withdrawals sum")
+	b.float(&c.Withdrawals.MonthlyCap, "withdraw-monthly-cap", "WITHDRAW_MONTHLY_CAP", "monthly withdrawals sum")
+	b.int64(&c.Withdrawals.HourlyCount, "withdraw-hourly-count", "WITHDRAW_HOURLY_COUNT", "hourly withdrawals count")
+	b.duration(&c.Withdrawals.CoolingOff, "withdraw-cooling-off", "WITHDRAW_COOLING_OFF",
+		"time after registration without withdrawals, 0 disables")
+
+	b.duration(&c.Reconciliation.Frequency, "reconciliation-frequency", "RECONCILIATION_FREQUENCY",
+		"balance reconciliation period")
+	b.string(&c.Reconciliation.Mode, "reconciliation-mode", "RECONCILIATION_MODE",
+		"balance reconciliation mode, report, dry-run or apply")
+	b.float(&c.Reconciliation.Tolerance, "reconciliation-tolerance", "RECONCILIATION_TOLERANCE",
+		"ignored balance difference")
+
+	return b.fs, b.envs
+}
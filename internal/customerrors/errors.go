@@ -10,4 +10,24 @@ var (
 	ErrCurrUserUploaded    = errors.New("order already uploaded by current user")
 	ErrAnotherUserUploaded = errors.New("order already uploaded by another user")
 	ErrNotEnoughFunds      = errors.New("there are insufficient funds in the account")
+	ErrSelfTransfer        = errors.New("transfer to yourself is not allowed")
+	ErrUserBlocked         = errors.New("user is blocked")
+	ErrTransferLimit       = errors.New("daily transfer limit exceeded")
+	ErrInvalidSum          = errors.New("sum must be a positive number")
+	ErrWithdrawBelowMin    = errors.New("withdraw sum is below the minimum")
+	ErrWithdrawAboveMax    = errors.New("withdraw sum is above the maximum")
+	ErrWithdrawDailyCap    = errors.New("daily withdraw cap exceeded")
+	ErrWithdrawMonthlyCap  = errors.New("monthly withdraw cap exceeded")
+	ErrWithdrawRateLimit   = errors.New("too many withdrawals in the last hour")
+	ErrWithdrawCoolingOff  = errors.New("withdrawals are not allowed yet after registration")
+	ErrHoldNotActive       = errors.New("hold is already captured, released or expired")
+	ErrReferralInvalid     = errors.New("referral code is not valid")
+	ErrReferralLimit       = errors.New("referrer has reached the referral limit")
+	ErrCampaignInvalid     = errors.New("campaign is not valid")
+	ErrCampaignFinished    = errors.New("campaign is already finished")
+	ErrWebhookInvalid      = errors.New("webhook url or events are not valid")
+	ErrWebhookLimit        = errors.New("too many webhooks")
+	ErrAdjustmentInvalid   = errors.New("adjustment amount must be a finite non-zero number and reason must be set")
+	ErrOrderProcessed      = errors.New("order is already processed")
+	ErrPasswordTooLong     = errors.New("password is longer than 72 bytes")
 )
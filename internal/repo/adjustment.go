@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+)
+
+func (tx *Tx) CreateBalanceAdjustment(
+	ctx context.Context,
+	userID int64,
+	amount float64,
+	reason string,
+) (id int64, err error) {
+	const query = `
+		INSERT INTO "balance_adjustment" (user_id, amount, reason)
+		VALUES ($1, $2, $3)
+		RETURNING  "balance_adjustment".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, amount, reason,
+	).Scan(&id)
+
+	if err != nil {
+		return id, fmt.Errorf("CreateBalanceAdjustment failed, %w", err)
+	}
+	tx.logger.Debugf("Create balance adjustment, id, %v", id)
+	return id, nil
+}
@@ -0,0 +1,156 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateCampaign(ctx context.Context, c models.Campaign) (id int64, err error) {
+	const query = `
+		INSERT INTO "campaign" (name, kind, value, starts_at, ends_at, new_users_only, tiers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING  "campaign".id;
+	`
+
+	if c.Tiers == nil {
+		c.Tiers = []string{}
+	}
+	err = tx.QueryRow(ctx, query,
+		c.Name, c.Kind, c.Value, c.StartsAt, c.EndsAt, c.NewUsersOnly, c.Tiers,
+	).Scan(&id)
+
+	if err != nil {
+		return id, fmt.Errorf("CreateCampaign failed, %w", err)
+	}
+	tx.logger.Debugf("Create campaign, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetCampaign(
+	ctx context.Context,
+	campaignID int64,
+	forUpdate bool,
+) (c models.Campaign, err error) {
+	var query string
+	if forUpdate {
+		query = `
+		SELECT c.id, c.name, c.kind, c.value, c.starts_at, c.ends_at, c.new_users_only, c.tiers, c.created_at
+		FROM "campaign" c
+		WHERE c.id = $1
+		FOR UPDATE;
+	`
+	} else {
+		query = `
+		SELECT c.id, c.name, c.kind, c.value, c.starts_at, c.ends_at, c.new_users_only, c.tiers, c.created_at
+		FROM "campaign" c
+		WHERE c.id = $1;
+	`
+	}
+	err = tx.QueryRow(ctx, query, campaignID).Scan(
+		&c.ID,
+		&c.Name,
+		&c.Kind,
+		&c.Value,
+		&c.StartsAt,
+		&c.EndsAt,
+		&c.NewUsersOnly,
+		&c.Tiers,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return c, fmt.Errorf("get campaign failed, %w", err)
+	}
+	return c, nil
+}
+
+func (tx *Tx) GetCampaigns(ctx context.Context) (campaigns []models.Campaign, err error) {
+	const query = `
+		SELECT c.id, c.name, c.kind, c.value, c.starts_at, c.ends_at, c.new_users_only, c.tiers, c.created_at
+		FROM "campaign" c
+		ORDER BY c.starts_at DESC;
+	`
+	return tx.queryCampaigns(ctx, query)
+}
+
+func (tx *Tx) GetActiveCampaigns(
+	ctx context.Context,
+	at time.Time,
+) (campaigns []models.Campaign, err error) {
+	const query = `
+		SELECT c.id, c.name, c.kind, c.value, c.starts_at, c.ends_at, c.new_users_only, c.tiers, c.created_at
+		FROM "campaign" c
+		WHERE c.starts_at <= $1
+		  AND c.ends_at > $1
+		ORDER BY c.id;
+	`
+	return tx.queryCampaigns(ctx, query, at)
+}
+
+func (tx *Tx) queryCampaigns(
+	ctx context.Context,
+	query string,
+	args ...any,
+) (campaigns []models.Campaign, err error) {
+	var rows pgx.Rows
+
+	rows, err = tx.Query(ctx, query, args...)
+	if err != nil {
+		return campaigns, fmt.Errorf("get campaigns failed, %w", err)
+	}
+
+	for rows.Next() {
+		var c models.Campaign
+		err = rows.Scan(
+			&c.ID,
+			&c.Name,
+			&c.Kind,
+			&c.Value,
+			&c.StartsAt,
+			&c.EndsAt,
+			&c.NewUsersOnly,
+			&c.Tiers,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return campaigns, fmt.Errorf("get campaigns failed, %w", err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err = rows.Err(); err != nil {
+		return campaigns, fmt.Errorf("get campaigns failed, %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (tx *Tx) EndCampaign(ctx context.Context, campaignID int64, endsAt time.Time) (err error) {
+	var id int64
+	const query = `
+		UPDATE "campaign"
+		SET ends_at = $1
+		WHERE "campaign".id = $2
+		RETURNING  "campaign".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		endsAt,
+		campaignID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("EndCampaign failed, %w", err)
+	}
+	tx.logger.Debugf("End campaign, id, %v", id)
+	return
+}
@@ -21,11 +21,29 @@ type DB struct {
 	logger *logrus.Logger
 }
 
-func New(ctx context.Context, dsn string, logger *logrus.Logger) (*DB, error) {
+// New connects the pool and migrates the db, maxConns of zero keeps the pgx default pool size.
+func New(ctx context.Context, dsn string, maxConns int32, logger *logrus.Logger) (*DB, error) {
+	db, err := Connect(ctx, dsn, maxConns, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.runMigrations(); err != nil {
+		return nil, fmt.Errorf("failed to migrate db: %w", err)
+	}
+
+	return db, nil
+}
+
+// Connect connects the pool without migrating, the migrations are left to the caller.
+func Connect(ctx context.Context, dsn string, maxConns int32, logger *logrus.Logger) (*DB, error) {
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
+	if maxConns > 0 {
+		cfg.MaxConns = maxConns
+	}
 	cfg.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithIncludeQueryParameters())
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
@@ -33,16 +51,10 @@ func New(ctx context.Context, dsn string, logger *logrus.Logger) (*DB, error) {
 		return nil, fmt.Errorf("failed to create a connection pool: %w", err)
 	}
 
-	db := DB{
+	return &DB{
 		pool:   pool,
 		logger: logger,
-	}
-
-	if err = db.runMigrations(); err != nil {
-		return nil, fmt.Errorf("failed to migrate db: %w", err)
-	}
-
-	return &db, nil
+	}, nil
 }
 
 func (db *DB) Shutdown(_ context.Context) {
@@ -59,9 +71,9 @@ func (db *DB) Ping(ctx context.Context) error {
 	return nil
 }
 
-func (db *DB) GetUserByLogin(ctx context.Context, tx pgx.Tx, login string) (u models.User, err error) {
+func (tx *Tx) GetUserByLogin(ctx context.Context, login string) (u models.User, err error) {
 	const query = `
-		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.created_at
+		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.held, u.tier, u.blocked, u.referral_code, u.created_at
 		FROM "user" u
 		WHERE u.login = $1; 
 	`
@@ -71,6 +83,10 @@ func (db *DB) GetUserByLogin(ctx context.Context, tx pgx.Tx, login string) (u mo
 		&u.Password,
 		&u.Balance,
 		&u.Withdrawn,
+		&u.Held,
+		&u.Tier,
+		&u.Blocked,
+		&u.ReferralCode,
 		&u.CreatedAt,
 	)
 	if err != nil {
@@ -84,18 +100,37 @@ func (db *DB) GetUserByLogin(ctx context.Context, tx pgx.Tx, login string) (u mo
 	return u, nil
 }
 
-func (db *DB) GetUserByID(ctx context.Context, tx pgx.Tx, id int64, forUpdate bool) (u models.User, err error) {
+// GetUserVersion returns the counter bumped by triggers on every balance, tier or order change.
+func (tx *Tx) GetUserVersion(ctx context.Context, userID int64) (version int64, err error) {
+	const query = `
+		SELECT u.version
+		FROM "user" u
+		WHERE u.id = $1;
+	`
+	err = tx.QueryRow(ctx, query, userID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return version, fmt.Errorf("get user version failed, %w", err)
+	}
+
+	return version, nil
+}
+
+func (tx *Tx) GetUserByID(ctx context.Context, id int64, forUpdate bool) (u models.User, err error) {
 	var query string
 	if forUpdate {
 		query = `
-		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.created_at
+		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.held, u.tier, u.blocked, u.referral_code, u.created_at
 		FROM "user" u
 		WHERE u.id = $1
 		FOR UPDATE; 
 	`
 	} else {
 		query = `
-		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.created_at
+		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.held, u.tier, u.blocked, u.referral_code, u.created_at
 		FROM "user" u
 		WHERE u.id = $1; 
 	`
@@ -107,6 +142,10 @@ func (db *DB) GetUserByID(ctx context.Context, tx pgx.Tx, id int64, forUpdate bo
 		&u.Password,
 		&u.Balance,
 		&u.Withdrawn,
+		&u.Held,
+		&u.Tier,
+		&u.Blocked,
+		&u.ReferralCode,
 		&u.CreatedAt,
 	)
 	if err != nil {
@@ -120,25 +159,25 @@ func (db *DB) GetUserByID(ctx context.Context, tx pgx.Tx, id int64, forUpdate bo
 	return u, nil
 }
 
-func (db *DB) CreateUser(ctx context.Context, tx pgx.Tx, login, password string) (id int64, err error) {
+func (tx *Tx) CreateUser(ctx context.Context, login, password, referralCode string) (id int64, err error) {
 	const query = `
-		INSERT INTO "user" (login, password)
-		VALUES ($1, $2)
+		INSERT INTO "user" (login, password, referral_code)
+		VALUES ($1, $2, $3)
 		RETURNING  "user".id; 
 	`
 
 	err = tx.QueryRow(ctx, query,
-		login, password,
+		login, password, referralCode,
 	).Scan(&id)
 
 	if err != nil {
 		return id, fmt.Errorf("CreateUser failed, %w", err)
 	}
-	db.logger.Debugf("Create user, id, %v", id)
+	tx.logger.Debugf("Create user, id, %v", id)
 	return
 }
 
-func (db *DB) UpdateUserBalance(ctx context.Context, tx pgx.Tx, userID int64, balance, withdrawn float64) (err error) {
+func (tx *Tx) UpdateUserBalance(ctx context.Context, userID int64, balance, withdrawn float64) (err error) {
 	var id int64
 	const query = `
 		UPDATE "user"
@@ -157,22 +196,22 @@ func (db *DB) UpdateUserBalance(ctx context.Context, tx pgx.Tx, userID int64, ba
 	if err != nil {
 		return fmt.Errorf("UpdateUserBalance failed, %w", err)
 	}
-	db.logger.Debugf("Update user balance, id, %v", id)
+	tx.logger.Debugf("Update user balance, id, %v", id)
 	return
 }
 
-func (db *DB) GetOrder(ctx context.Context, tx pgx.Tx, orderID int64, forUpdate bool) (o models.Order, err error) {
+func (tx *Tx) GetOrder(ctx context.Context, orderID int64, forUpdate bool) (o models.Order, err error) {
 	var query string
 	if forUpdate {
 		query = `
-		SELECT o.id, o.status, o.user_id, o.created_at, o.updated_at
+		SELECT o.id, o.status, o.user_id, o.accrual, o.uploaded_at, o.updated_at, o.processed_at
 		FROM "order" o
 		WHERE o.id = $1
 		FOR UPDATE; 
 	`
 	} else {
 		query = `
-		SELECT o.id, o.status, o.user_id, o.created_at, o.updated_at
+		SELECT o.id, o.status, o.user_id, o.accrual, o.uploaded_at, o.updated_at, o.processed_at
 		FROM "order" o
 		WHERE o.id = $1; 
 	`
@@ -181,8 +220,10 @@ func (db *DB) GetOrder(ctx context.Context, tx pgx.Tx, orderID int64, forUpdate
 		&o.ID,
 		&o.Status,
 		&o.UserID,
-		&o.CreatedAt,
+		&o.Accrual,
+		&o.UploadedAt,
 		&o.UpdatedAt,
+		&o.ProcessedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -195,11 +236,15 @@ func (db *DB) GetOrder(ctx context.Context, tx pgx.Tx, orderID int64, forUpdate
 	return o, nil
 }
 
-func (db *DB) UpdateOrder(ctx context.Context, tx pgx.Tx, orderID int64, accrual float64, status string) (err error) {
+func (tx *Tx) UpdateOrder(ctx context.Context, orderID int64, accrual float64, status string) (err error) {
 	var id int64
 	const query = `
 		UPDATE "order"
-		SET accrual = $1, status = $2
+		SET accrual = $1, status = $2, updated_at = NOW(),
+		    processed_at = CASE
+		        WHEN $2 IN ('PROCESSED', 'INVALID') THEN COALESCE(processed_at, NOW())
+		        ELSE processed_at
+		    END
 		WHERE "order".id = $3
 		RETURNING  "order".id; 
 	`
@@ -213,20 +258,39 @@ func (db *DB) UpdateOrder(ctx context.Context, tx pgx.Tx, orderID int64, accrual
 	if err != nil {
 		return fmt.Errorf("UpdateOrder failed, %w", err)
 	}
-	db.logger.Debugf("UpdateOrder, id, %v", id)
+	tx.logger.Debugf("UpdateOrder, id, %v", id)
 	return
 }
 
-func (db *DB) GetOrders(ctx context.Context, tx pgx.Tx, userID int64) (orders []models.Order, err error) {
-	var rows pgx.Rows
+func (tx *Tx) GetOrders(
+	ctx context.Context,
+	userID int64,
+	filter models.ListFilter,
+) (orders []models.Order, err error) {
+	var (
+		rows  pgx.Rows
+		query string
+	)
 
-	const query = `
-		SELECT o.id, o.status, o.user_id, o.accrual, o.created_at, o.updated_at
+	// every filter is a parameter, a NULL one matches everything
+	const where = `
+		SELECT o.id, o.status, o.user_id, o.accrual, o.uploaded_at, o.updated_at, o.processed_at
 		FROM "order" o
 		WHERE o.user_id = $1
-		ORDER BY o.created_at; 
+		  AND ($2::text[] IS NULL OR o.status::text = ANY($2::text[]))
+		  AND ($3::timestamptz IS NULL OR o.uploaded_at >= $3)
+		  AND ($4::timestamptz IS NULL OR o.uploaded_at < $4)
+		  AND ($5::double precision IS NULL OR o.accrual >= $5)
+		  AND ($6::double precision IS NULL OR o.accrual <= $6)
 	`
-	rows, err = tx.Query(ctx, query, userID)
+	if filter.Desc {
+		query = where + `ORDER BY o.uploaded_at DESC;`
+	} else {
+		query = where + `ORDER BY o.uploaded_at;`
+	}
+	rows, err = tx.Query(ctx, query,
+		userID, filter.Statuses, filter.From, filter.To, filter.MinAmount, filter.MaxAmount,
+	)
 	if err != nil {
 		return orders, fmt.Errorf("get orders failed, %w", err)
 	}
@@ -238,14 +302,15 @@ func (db *DB) GetOrders(ctx context.Context, tx pgx.Tx, userID int64) (orders []
 			&o.Status,
 			&o.UserID,
 			&o.Accrual,
-			&o.CreatedAt,
+			&o.UploadedAt,
 			&o.UpdatedAt,
+			&o.ProcessedAt,
 		)
 		if err != nil {
-			db.logger.Debug(err)
+			tx.logger.Debug(err)
 			return orders, fmt.Errorf("get orders failed, %w", err)
 		}
-		db.logger.Debug(o)
+		tx.logger.Debug(o)
 		orders = append(orders, o)
 	}
 	if err = rows.Err(); err != nil {
@@ -255,14 +320,14 @@ func (db *DB) GetOrders(ctx context.Context, tx pgx.Tx, userID int64) (orders []
 	return orders, nil
 }
 
-func (db *DB) GetNotProcessedOrders(ctx context.Context, tx pgx.Tx) (orders []models.Order, err error) {
+func (tx *Tx) GetNotProcessedOrders(ctx context.Context) (orders []models.Order, err error) {
 	var rows pgx.Rows
 
 	const query = `
-		SELECT o.id, o.status, o.user_id, o.accrual, o.created_at, o.updated_at
+		SELECT o.id, o.status, o.user_id, o.accrual, o.uploaded_at, o.updated_at, o.processed_at
 		FROM "order" o
 		WHERE o.status in ('PROCESSING', 'NEW')
-		ORDER BY o.created_at;
+		ORDER BY o.uploaded_at;
 	`
 	rows, err = tx.Query(ctx, query)
 	if err != nil {
@@ -276,14 +341,15 @@ func (db *DB) GetNotProcessedOrders(ctx context.Context, tx pgx.Tx) (orders []mo
 			&o.Status,
 			&o.UserID,
 			&o.Accrual,
-			&o.CreatedAt,
+			&o.UploadedAt,
 			&o.UpdatedAt,
+			&o.ProcessedAt,
 		)
 		if err != nil {
-			db.logger.Debug(err)
+			tx.logger.Debug(err)
 			return orders, fmt.Errorf("get orders failed, %w", err)
 		}
-		db.logger.Debug(o)
+		tx.logger.Debug(o)
 		orders = append(orders, o)
 	}
 	if err = rows.Err(); err != nil {
@@ -293,7 +359,51 @@ func (db *DB) GetNotProcessedOrders(ctx context.Context, tx pgx.Tx) (orders []mo
 	return orders, nil
 }
 
-func (db *DB) CreateOrder(ctx context.Context, tx pgx.Tx, userID, orderID int64) (err error) {
+// RequeueOrders resets the orders in the statuses not checked since updatedBefore to NEW,
+// so the accrual sync treats them as just uploaded.
+func (tx *Tx) RequeueOrders(
+	ctx context.Context,
+	statuses []string,
+	updatedBefore time.Time,
+) (orders []models.Order, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		UPDATE "order" o
+		SET status = 'NEW', accrual = 0, processed_at = NULL, updated_at = NOW()
+		WHERE o.status = ANY($1::status_type[])
+		  AND o.updated_at < $2
+		RETURNING o.id, o.status, o.user_id, o.accrual, o.uploaded_at, o.updated_at, o.processed_at;
+	`
+	rows, err = tx.Query(ctx, query, statuses, updatedBefore)
+	if err != nil {
+		return orders, fmt.Errorf("requeue orders failed, %w", err)
+	}
+
+	for rows.Next() {
+		var o models.Order
+		err = rows.Scan(
+			&o.ID,
+			&o.Status,
+			&o.UserID,
+			&o.Accrual,
+			&o.UploadedAt,
+			&o.UpdatedAt,
+			&o.ProcessedAt,
+		)
+		if err != nil {
+			return orders, fmt.Errorf("requeue orders failed, %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err = rows.Err(); err != nil {
+		return orders, fmt.Errorf("requeue orders failed, %w", err)
+	}
+
+	return orders, nil
+}
+
+func (tx *Tx) CreateOrder(ctx context.Context, userID, orderID int64) (err error) {
 	var id int64
 
 	const query = `
@@ -309,11 +419,11 @@ func (db *DB) CreateOrder(ctx context.Context, tx pgx.Tx, userID, orderID int64)
 	if err != nil {
 		return fmt.Errorf("CreateOrder failed, %w", err)
 	}
-	db.logger.Debugf("Create order, id, %v", id)
+	tx.logger.Debugf("Create order, id, %v", id)
 	return
 }
 
-func (db *DB) CreateWithdraw(ctx context.Context, tx pgx.Tx, userID, orderID int64, sum float64) (err error) {
+func (tx *Tx) CreateWithdraw(ctx context.Context, userID, orderID int64, sum float64) (err error) {
 	var id int64
 
 	const query = `
@@ -329,20 +439,38 @@ func (db *DB) CreateWithdraw(ctx context.Context, tx pgx.Tx, userID, orderID int
 	if err != nil {
 		return fmt.Errorf("CreateWithdraw failed, %w", err)
 	}
-	db.logger.Debugf("Create withdraw, id, %v", id)
+	tx.logger.Debugf("Create withdraw, id, %v", id)
 	return
 }
 
-func (db *DB) GetWithdrawals(ctx context.Context, tx pgx.Tx, userID int64) (withdrawals []models.Withdraw, err error) {
-	var rows pgx.Rows
+func (tx *Tx) GetWithdrawals(
+	ctx context.Context,
+	userID int64,
+	filter models.ListFilter,
+) (withdrawals []models.Withdraw, err error) {
+	var (
+		rows  pgx.Rows
+		query string
+	)
 
-	const query = `
+	// every filter is a parameter, a NULL one matches everything
+	const where = `
 		SELECT w.id, w.order_id, w.user_id, w.sum, w.created_at
 		FROM "withdraw" w
 		WHERE w.user_id = $1
-		ORDER BY w.created_at; 
+		  AND ($2::timestamptz IS NULL OR w.created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR w.created_at < $3)
+		  AND ($4::double precision IS NULL OR w.sum >= $4)
+		  AND ($5::double precision IS NULL OR w.sum <= $5)
 	`
-	rows, err = tx.Query(ctx, query, userID)
+	if filter.Desc {
+		query = where + `ORDER BY w.created_at DESC;`
+	} else {
+		query = where + `ORDER BY w.created_at;`
+	}
+	rows, err = tx.Query(ctx, query,
+		userID, filter.From, filter.To, filter.MinAmount, filter.MaxAmount,
+	)
 	if err != nil {
 		return withdrawals, fmt.Errorf("get orders failed, %w", err)
 	}
@@ -357,10 +485,10 @@ func (db *DB) GetWithdrawals(ctx context.Context, tx pgx.Tx, userID int64) (with
 			&w.CreatedAt,
 		)
 		if err != nil {
-			db.logger.Debug(err)
+			tx.logger.Debug(err)
 			return withdrawals, fmt.Errorf("get withdrawals failed, %w", err)
 		}
-		db.logger.Debug(w)
+		tx.logger.Debug(w)
 		withdrawals = append(withdrawals, w)
 	}
 	if err = rows.Err(); err != nil {
@@ -369,3 +497,38 @@ func (db *DB) GetWithdrawals(ctx context.Context, tx pgx.Tx, userID int64) (with
 
 	return withdrawals, nil
 }
+
+// GetWithdrawStat counts the withdrawals and the active holds, a hold is a withdrawal
+// that is not captured yet and must not let parallel holds pass the caps.
+func (tx *Tx) GetWithdrawStat(
+	ctx context.Context,
+	userID int64,
+	hourFrom, dayFrom, monthFrom time.Time,
+) (stat models.WithdrawStat, err error) {
+	const query = `
+		SELECT COUNT(*) FILTER (WHERE w.created_at >= $2),
+		       COALESCE(SUM(w.sum) FILTER (WHERE w.created_at >= $3), 0),
+		       COALESCE(SUM(w.sum) FILTER (WHERE w.created_at >= $4), 0)
+		FROM (
+			SELECT wd.created_at, wd.sum
+			FROM "withdraw" wd
+			WHERE wd.user_id = $1
+			UNION ALL
+			SELECT h.created_at, h.sum
+			FROM "withdraw_hold" h
+			WHERE h.user_id = $1
+			  AND h.status = 'HELD'
+			  AND h.expires_at > NOW()
+		) w
+		WHERE w.created_at >= LEAST($2::timestamptz, $3::timestamptz, $4::timestamptz);
+	`
+	err = tx.QueryRow(ctx, query, userID, hourFrom, dayFrom, monthFrom).Scan(
+		&stat.HourCount,
+		&stat.DaySum,
+		&stat.MonthSum,
+	)
+	if err != nil {
+		return stat, fmt.Errorf("get withdraw stat failed, %w", err)
+	}
+	return stat, nil
+}
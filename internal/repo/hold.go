@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateHold(
+	ctx context.Context,
+	userID, orderID int64,
+	sum float64,
+	expiresAt time.Time,
+) (id int64, err error) {
+	const query = `
+		INSERT INTO "withdraw_hold" (user_id, order_id, sum, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING  "withdraw_hold".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, orderID, sum, models.HELD.String(), expiresAt,
+	).Scan(&id)
+
+	if err != nil {
+		return id, fmt.Errorf("CreateHold failed, %w", err)
+	}
+	tx.logger.Debugf("Create hold, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetHold(ctx context.Context, holdID int64, forUpdate bool) (h models.Hold, err error) {
+	var query string
+	if forUpdate {
+		query = `
+		SELECT h.id, h.user_id, h.order_id, h.sum, h.status, h.expires_at, h.created_at, h.updated_at
+		FROM "withdraw_hold" h
+		WHERE h.id = $1
+		FOR UPDATE;
+	`
+	} else {
+		query = `
+		SELECT h.id, h.user_id, h.order_id, h.sum, h.status, h.expires_at, h.created_at, h.updated_at
+		FROM "withdraw_hold" h
+		WHERE h.id = $1;
+	`
+	}
+	err = tx.QueryRow(ctx, query, holdID).Scan(
+		&h.ID,
+		&h.UserID,
+		&h.OrderID,
+		&h.Sum,
+		&h.Status,
+		&h.ExpiresAt,
+		&h.CreatedAt,
+		&h.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return h, fmt.Errorf("get hold failed, %w", err)
+	}
+
+	return h, nil
+}
+
+func (tx *Tx) GetExpiredHolds(ctx context.Context, now time.Time, limit int64) (holds []models.Hold, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT h.id, h.user_id, h.order_id, h.sum, h.status, h.expires_at, h.created_at, h.updated_at
+		FROM "withdraw_hold" h
+		WHERE h.status = 'HELD'
+		  AND h.expires_at <= $1
+		ORDER BY h.expires_at
+		LIMIT $2;
+	`
+	rows, err = tx.Query(ctx, query, now, limit)
+	if err != nil {
+		return holds, fmt.Errorf("get expired holds failed, %w", err)
+	}
+
+	for rows.Next() {
+		var h models.Hold
+		err = rows.Scan(
+			&h.ID,
+			&h.UserID,
+			&h.OrderID,
+			&h.Sum,
+			&h.Status,
+			&h.ExpiresAt,
+			&h.CreatedAt,
+			&h.UpdatedAt,
+		)
+		if err != nil {
+			return holds, fmt.Errorf("get expired holds failed, %w", err)
+		}
+		holds = append(holds, h)
+	}
+	if err = rows.Err(); err != nil {
+		return holds, fmt.Errorf("get expired holds failed, %w", err)
+	}
+
+	return holds, nil
+}
+
+func (tx *Tx) UpdateHoldStatus(ctx context.Context, holdID int64, status string) (err error) {
+	var id int64
+	const query = `
+		UPDATE "withdraw_hold"
+		SET status = $1, updated_at = $2
+		WHERE "withdraw_hold".id = $3
+		RETURNING  "withdraw_hold".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		status,
+		time.Now(),
+		holdID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("UpdateHoldStatus failed, %w", err)
+	}
+	tx.logger.Debugf("Update hold status, id, %v", id)
+	return
+}
+
+func (tx *Tx) UpdateUserHeld(ctx context.Context, userID int64, held float64) (err error) {
+	var id int64
+	const query = `
+		UPDATE "user"
+		SET held = $1, updated_at = $2
+		WHERE "user".id = $3
+		RETURNING  "user".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		held,
+		time.Now(),
+		userID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("UpdateUserHeld failed, %w", err)
+	}
+	tx.logger.Debugf("Update user held, id, %v", id)
+	return
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) GetLoyaltyTiers(ctx context.Context) (tiers []models.LoyaltyTier, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT t.name, t.threshold, t.multiplier
+		FROM "loyalty_tier" t
+		ORDER BY t.threshold;
+	`
+	rows, err = tx.Query(ctx, query)
+	if err != nil {
+		return tiers, fmt.Errorf("get loyalty tiers failed, %w", err)
+	}
+
+	for rows.Next() {
+		var t models.LoyaltyTier
+		err = rows.Scan(
+			&t.Name,
+			&t.Threshold,
+			&t.Multiplier,
+		)
+		if err != nil {
+			return tiers, fmt.Errorf("get loyalty tiers failed, %w", err)
+		}
+		tiers = append(tiers, t)
+	}
+	if err = rows.Err(); err != nil {
+		return tiers, fmt.Errorf("get loyalty tiers failed, %w", err)
+	}
+
+	return tiers, nil
+}
+
+func (tx *Tx) GetLoyaltyTier(ctx context.Context, name string) (t models.LoyaltyTier, err error) {
+	const query = `
+		SELECT t.name, t.threshold, t.multiplier
+		FROM "loyalty_tier" t
+		WHERE t.name = $1;
+	`
+	err = tx.QueryRow(ctx, query, name).Scan(
+		&t.Name,
+		&t.Threshold,
+		&t.Multiplier,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return t, fmt.Errorf("get loyalty tier failed, %w", err)
+	}
+
+	return t, nil
+}
+
+func (tx *Tx) GetUserAccrualVolume(
+	ctx context.Context,
+	userID int64,
+	since time.Time,
+) (volume float64, err error) {
+	const query = `
+		SELECT COALESCE(SUM(o.accrual), 0)
+		FROM "order" o
+		WHERE o.user_id = $1
+		  AND o.status = 'PROCESSED'
+		  AND o.uploaded_at >= $2;
+	`
+	err = tx.QueryRow(ctx, query, userID, since).Scan(&volume)
+	if err != nil {
+		return volume, fmt.Errorf("get user accrual volume failed, %w", err)
+	}
+	return volume, nil
+}
+
+func (tx *Tx) GetAccrualVolumes(ctx context.Context, since time.Time) (volumes []models.UserVolume, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT u.id, u.tier, COALESCE(SUM(o.accrual), 0)
+		FROM "user" u
+		LEFT JOIN "order" o ON o.user_id = u.id
+		                   AND o.status = 'PROCESSED'
+		                   AND o.uploaded_at >= $1
+		GROUP BY u.id, u.tier
+		ORDER BY u.id;
+	`
+	rows, err = tx.Query(ctx, query, since)
+	if err != nil {
+		return volumes, fmt.Errorf("get accrual volumes failed, %w", err)
+	}
+
+	for rows.Next() {
+		var v models.UserVolume
+		err = rows.Scan(
+			&v.UserID,
+			&v.Tier,
+			&v.Volume,
+		)
+		if err != nil {
+			return volumes, fmt.Errorf("get accrual volumes failed, %w", err)
+		}
+		volumes = append(volumes, v)
+	}
+	if err = rows.Err(); err != nil {
+		return volumes, fmt.Errorf("get accrual volumes failed, %w", err)
+	}
+
+	return volumes, nil
+}
+
+func (tx *Tx) UpdateUserTier(ctx context.Context, userID int64, tier string) (err error) {
+	var id int64
+	const query = `
+		UPDATE "user"
+		SET tier = $1, updated_at = $2
+		WHERE "user".id = $3
+		RETURNING  "user".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		tier,
+		time.Now(),
+		userID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("UpdateUserTier failed, %w", err)
+	}
+	tx.logger.Debugf("Update user tier, id, %v", id)
+	return
+}
+
+func (tx *Tx) CreateTierHistory(
+	ctx context.Context,
+	userID int64,
+	oldTier, newTier string,
+	volume float64,
+) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "tier_history" (user_id, old_tier, new_tier, volume)
+		VALUES ($1, $2, $3, $4)
+		RETURNING  "tier_history".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, oldTier, newTier, volume,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateTierHistory failed, %w", err)
+	}
+	tx.logger.Debugf("Create tier history, id, %v", id)
+	return
+}
+
+func (tx *Tx) CreateBonus(
+	ctx context.Context,
+	userID, orderID int64,
+	source string,
+	amount float64,
+) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "bonus" (user_id, order_id, source, amount)
+		VALUES ($1, NULLIF($2::bigint, 0), $3, $4)
+		RETURNING  "bonus".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, orderID, source, amount,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateBonus failed, %w", err)
+	}
+	tx.logger.Debugf("Create bonus, id, %v", id)
+	return
+}
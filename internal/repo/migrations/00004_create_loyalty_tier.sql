@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "loyalty_tier"
+(
+    name        TEXT PRIMARY KEY,
+    threshold   double precision NOT NULL,
+    multiplier  double precision NOT NULL DEFAULT 1,
+    CONSTRAINT CH_loyalty_tier_threshold CHECK (threshold >= 0),
+    CONSTRAINT CH_loyalty_tier_multiplier CHECK (multiplier >= 1)
+);
+INSERT INTO "loyalty_tier" (name, threshold, multiplier)
+VALUES ('BRONZE', 0, 1),
+       ('SILVER', 1000, 1.05),
+       ('GOLD', 5000, 1.1)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS tier TEXT NOT NULL DEFAULT 'BRONZE';
+ALTER TABLE "user" ADD CONSTRAINT FK_user_tier FOREIGN KEY(tier) REFERENCES "loyalty_tier"(name)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE;
+
+CREATE TABLE IF NOT EXISTS "tier_history"
+(
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    old_tier    TEXT NOT NULL,
+    new_tier    TEXT NOT NULL,
+    volume      double precision NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT FK_tier_history_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_tier_history_user_id ON "tier_history"(user_id);
+CREATE INDEX idx_order_user_id_created_at ON "order"(user_id, created_at);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_order_user_id_created_at;
+DROP INDEX IF EXISTS idx_tier_history_user_id;
+DROP TABLE IF EXISTS "tier_history";
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS FK_user_tier;
+ALTER TABLE "user" DROP COLUMN IF EXISTS tier;
+DROP TABLE IF EXISTS "loyalty_tier";
+
+-- +goose StatementEnd
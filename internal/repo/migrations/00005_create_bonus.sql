@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "bonus"
+(
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    order_id    bigint NULL,
+    source      TEXT NOT NULL,
+    amount      double precision NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT FK_bonus_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_bonus_user_id ON "bonus"(user_id);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_bonus_user_id;
+DROP TABLE IF EXISTS "bonus";
+
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS blocked boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "transfer"
+(
+    id            bigserial PRIMARY KEY,
+    sender_id     bigint NOT NULL,
+    recipient_id  bigint NOT NULL,
+    sum           double precision NOT NULL,
+    created_at    timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT CH_transfer_sum CHECK (sum > 0),
+    CONSTRAINT CH_transfer_users CHECK (sender_id <> recipient_id),
+    CONSTRAINT FK_transfer_sender FOREIGN KEY(sender_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE,
+    CONSTRAINT FK_transfer_recipient FOREIGN KEY(recipient_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_transfer_sender_id_created_at ON "transfer"(sender_id, created_at);
+CREATE INDEX idx_transfer_recipient_id ON "transfer"(recipient_id);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_transfer_recipient_id;
+DROP INDEX IF EXISTS idx_transfer_sender_id_created_at;
+DROP TABLE IF EXISTS "transfer";
+ALTER TABLE "user" DROP COLUMN IF EXISTS blocked;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS held double precision NOT NULL DEFAULT 0;
+
+CREATE TYPE hold_status_type AS ENUM ('HELD', 'CAPTURED', 'RELEASED', 'EXPIRED');
+CREATE TABLE IF NOT EXISTS "withdraw_hold"
+(
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    order_id    bigint NOT NULL,
+    sum         double precision NOT NULL,
+    status      hold_status_type NOT NULL DEFAULT 'HELD',
+    expires_at  timestamp NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT NOW(),
+    updated_at  timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT CH_withdraw_hold_sum CHECK (sum > 0),
+    CONSTRAINT FK_withdraw_hold_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_withdraw_hold_user_id ON "withdraw_hold"(user_id);
+CREATE INDEX idx_withdraw_hold_expires_at ON "withdraw_hold"(expires_at) WHERE status = 'HELD';
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_withdraw_hold_expires_at;
+DROP INDEX IF EXISTS idx_withdraw_hold_user_id;
+DROP TABLE IF EXISTS "withdraw_hold";
+DROP TYPE IF EXISTS hold_status_type;
+ALTER TABLE "user" DROP COLUMN IF EXISTS held;
+
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS referral_code TEXT NULL;
+UPDATE "user" SET referral_code = upper(substr(md5(random()::text || id::text), 1, 8))
+WHERE referral_code IS NULL;
+ALTER TABLE "user" ALTER COLUMN referral_code SET NOT NULL;
+CREATE UNIQUE INDEX idx_user_referral_code ON "user"(referral_code);
+
+CREATE TYPE referral_status_type AS ENUM ('PENDING', 'REWARDED');
+CREATE TABLE IF NOT EXISTS "referral"
+(
+    id           bigserial PRIMARY KEY,
+    referrer_id  bigint NOT NULL,
+    referee_id   bigint UNIQUE NOT NULL,
+    status       referral_status_type NOT NULL DEFAULT 'PENDING',
+    created_at   timestamp NOT NULL DEFAULT NOW(),
+    rewarded_at  timestamp NULL,
+    CONSTRAINT CH_referral_users CHECK (referrer_id <> referee_id),
+    CONSTRAINT FK_referral_referrer FOREIGN KEY(referrer_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE,
+    CONSTRAINT FK_referral_referee FOREIGN KEY(referee_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_referral_referrer_id ON "referral"(referrer_id);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_referral_referrer_id;
+DROP TABLE IF EXISTS "referral";
+DROP TYPE IF EXISTS referral_status_type;
+DROP INDEX IF EXISTS idx_user_referral_code;
+ALTER TABLE "user" DROP COLUMN IF EXISTS referral_code;
+
+-- +goose StatementEnd
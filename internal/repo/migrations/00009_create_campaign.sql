@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TYPE campaign_kind_type AS ENUM ('MULTIPLIER', 'FIXED');
+CREATE TABLE IF NOT EXISTS "campaign"
+(
+    id              bigserial PRIMARY KEY,
+    name            TEXT NOT NULL,
+    kind            campaign_kind_type NOT NULL,
+    value           double precision NOT NULL,
+    starts_at       timestamp NOT NULL,
+    ends_at         timestamp NOT NULL,
+    new_users_only  boolean NOT NULL DEFAULT false,
+    tiers           TEXT[] NOT NULL DEFAULT '{}',
+    created_at      timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT CH_campaign_period CHECK (ends_at >= starts_at),
+    CONSTRAINT CH_campaign_value CHECK (value > 0)
+);
+CREATE INDEX idx_campaign_period ON "campaign"(starts_at, ends_at);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_campaign_period;
+DROP TABLE IF EXISTS "campaign";
+DROP TYPE IF EXISTS campaign_kind_type;
+
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "user_event"
+(
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL REFERENCES "user" (id),
+    type        TEXT NOT NULL,
+    payload     jsonb NOT NULL,
+    created_at  timestamp NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_user_event_user_id_id ON "user_event"(user_id, id);
+
+CREATE OR REPLACE FUNCTION notify_user_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('user_event', NEW.user_id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_event_notify
+    AFTER INSERT ON "user_event"
+    FOR EACH ROW EXECUTE FUNCTION notify_user_event();
+
+CREATE OR REPLACE FUNCTION order_user_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO "user_event" (user_id, type, payload)
+    VALUES (NEW.user_id, 'order', json_build_object(
+        'number', NEW.id::text,
+        'status', NEW.status,
+        'accrual', NEW.accrual
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_user_event
+    AFTER UPDATE OF status, accrual ON "order"
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.accrual IS DISTINCT FROM NEW.accrual)
+    EXECUTE FUNCTION order_user_event();
+
+CREATE OR REPLACE FUNCTION balance_user_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO "user_event" (user_id, type, payload)
+    VALUES (NEW.id, 'balance', json_build_object(
+        'current', NEW.balance,
+        'withdrawn', NEW.withdrawn,
+        'held', NEW.held,
+        'available', NEW.balance - NEW.held
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER balance_user_event
+    AFTER UPDATE OF balance, withdrawn, held ON "user"
+    FOR EACH ROW
+    WHEN (OLD.balance IS DISTINCT FROM NEW.balance
+        OR OLD.withdrawn IS DISTINCT FROM NEW.withdrawn
+        OR OLD.held IS DISTINCT FROM NEW.held)
+    EXECUTE FUNCTION balance_user_event();
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS balance_user_event ON "user";
+DROP TRIGGER IF EXISTS order_user_event ON "order";
+DROP TRIGGER IF EXISTS user_event_notify ON "user_event";
+DROP FUNCTION IF EXISTS balance_user_event;
+DROP FUNCTION IF EXISTS order_user_event;
+DROP FUNCTION IF EXISTS notify_user_event;
+DROP INDEX IF EXISTS idx_user_event_user_id_id;
+DROP TABLE IF EXISTS "user_event";
+
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "webhook"
+(
+    id          bigserial PRIMARY KEY,
+    user_id     bigint NOT NULL,
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL,
+    events      TEXT[] NOT NULL,
+    active      boolean NOT NULL DEFAULT true,
+    created_at  timestamp NOT NULL DEFAULT NOW(),
+    CONSTRAINT FK_webhook_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                             ON DELETE RESTRICT
+                                             ON UPDATE CASCADE
+);
+CREATE INDEX idx_webhook_user_id ON "webhook"(user_id) WHERE active;
+
+CREATE TYPE delivery_status_type AS ENUM ('QUEUED', 'DELIVERED', 'FAILED');
+CREATE TABLE IF NOT EXISTS "webhook_delivery"
+(
+    id                bigserial PRIMARY KEY,
+    webhook_id        bigint NOT NULL,
+    event             TEXT NOT NULL,
+    payload           jsonb NOT NULL,
+    status            delivery_status_type NOT NULL DEFAULT 'QUEUED',
+    attempts          integer NOT NULL DEFAULT 0,
+    next_attempt_at   timestamp NOT NULL DEFAULT NOW(),
+    last_status_code  integer NOT NULL DEFAULT 0,
+    last_error        TEXT NOT NULL DEFAULT '',
+    created_at        timestamp NOT NULL DEFAULT NOW(),
+    delivered_at      timestamp NULL,
+    CONSTRAINT FK_webhook_delivery_webhook FOREIGN KEY(webhook_id) REFERENCES "webhook"(id)
+                                                             ON DELETE CASCADE
+                                                             ON UPDATE CASCADE
+);
+CREATE INDEX idx_webhook_delivery_webhook_id ON "webhook_delivery"(webhook_id, id);
+CREATE INDEX idx_webhook_delivery_next_attempt_at ON "webhook_delivery"(next_attempt_at) WHERE status = 'QUEUED';
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_webhook_delivery_next_attempt_at;
+DROP INDEX IF EXISTS idx_webhook_delivery_webhook_id;
+DROP TABLE IF EXISTS "webhook_delivery";
+DROP TYPE IF EXISTS delivery_status_type;
+DROP INDEX IF EXISTS idx_webhook_user_id;
+DROP TABLE IF EXISTS "webhook";
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "outbox_event"
+(
+    id             bigserial PRIMARY KEY,
+    type           TEXT NOT NULL,
+    payload        jsonb NOT NULL,
+    created_at     timestamp NOT NULL DEFAULT NOW(),
+    dispatched_at  timestamp NULL
+);
+CREATE INDEX idx_outbox_event_pending ON "outbox_event"(id) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_outbox_event_dispatched_at ON "outbox_event"(dispatched_at) WHERE dispatched_at IS NOT NULL;
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_outbox_event_dispatched_at;
+DROP INDEX IF EXISTS idx_outbox_event_pending;
+DROP TABLE IF EXISTS "outbox_event";
+
+-- +goose StatementEnd
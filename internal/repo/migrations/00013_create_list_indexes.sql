@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE INDEX IF NOT EXISTS idx_order_user_id_status ON "order"(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_withdraw_user_id_created_at ON "withdraw"(user_id, created_at);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_withdraw_user_id_created_at;
+DROP INDEX IF EXISTS idx_order_user_id_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "order_status_history"
+(
+    id          bigserial PRIMARY KEY,
+    order_id    bigint NOT NULL REFERENCES "order" (id),
+    status      status_type NOT NULL,
+    accrual     double precision NOT NULL DEFAULT 0,
+    created_at  timestamp NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_order_status_history_order_id ON "order_status_history"(order_id, id);
+
+INSERT INTO "order_status_history" (order_id, status, accrual, created_at)
+SELECT o.id, o.status, COALESCE(o.accrual, 0), o.updated_at
+FROM "order" o
+WHERE o.status <> 'NEW';
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_order_status_history_order_id;
+DROP TABLE IF EXISTS "order_status_history";
+
+-- +goose StatementEnd
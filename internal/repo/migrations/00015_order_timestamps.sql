@@ -0,0 +1,98 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+-- existing values are read in the session time zone, the one NOW() wrote them in
+ALTER TABLE "user"
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN updated_at TYPE timestamptz;
+ALTER TABLE "order"
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN updated_at TYPE timestamptz;
+ALTER TABLE "withdraw" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "tier_history" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "bonus" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "transfer" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "withdraw_hold"
+    ALTER COLUMN expires_at TYPE timestamptz,
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN updated_at TYPE timestamptz;
+ALTER TABLE "referral"
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN rewarded_at TYPE timestamptz;
+ALTER TABLE "campaign"
+    ALTER COLUMN starts_at TYPE timestamptz,
+    ALTER COLUMN ends_at TYPE timestamptz,
+    ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "user_event" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "webhook" ALTER COLUMN created_at TYPE timestamptz;
+ALTER TABLE "webhook_delivery"
+    ALTER COLUMN next_attempt_at TYPE timestamptz,
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN delivered_at TYPE timestamptz;
+ALTER TABLE "outbox_event"
+    ALTER COLUMN created_at TYPE timestamptz,
+    ALTER COLUMN dispatched_at TYPE timestamptz;
+ALTER TABLE "order_status_history" ALTER COLUMN created_at TYPE timestamptz;
+
+-- updated_at is the last accrual check from now on
+ALTER TABLE "order" RENAME COLUMN created_at TO uploaded_at;
+ALTER TABLE "order" ADD COLUMN processed_at timestamptz NULL;
+ALTER INDEX idx_created_at RENAME TO idx_uploaded_at;
+ALTER INDEX idx_order_user_id_created_at RENAME TO idx_order_user_id_uploaded_at;
+
+UPDATE "order" o
+SET processed_at = COALESCE(
+        (SELECT MAX(h.created_at)
+         FROM "order_status_history" h
+         WHERE h.order_id = o.id AND h.status = o.status),
+        o.updated_at
+    )
+WHERE o.status IN ('PROCESSED', 'INVALID');
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+BEGIN;
+ALTER INDEX idx_order_user_id_uploaded_at RENAME TO idx_order_user_id_created_at;
+ALTER INDEX idx_uploaded_at RENAME TO idx_created_at;
+ALTER TABLE "order" DROP COLUMN processed_at;
+ALTER TABLE "order" RENAME COLUMN uploaded_at TO created_at;
+
+ALTER TABLE "order_status_history" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "outbox_event"
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN dispatched_at TYPE timestamp;
+ALTER TABLE "webhook_delivery"
+    ALTER COLUMN next_attempt_at TYPE timestamp,
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN delivered_at TYPE timestamp;
+ALTER TABLE "webhook" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "user_event" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "campaign"
+    ALTER COLUMN starts_at TYPE timestamp,
+    ALTER COLUMN ends_at TYPE timestamp,
+    ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "referral"
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN rewarded_at TYPE timestamp;
+ALTER TABLE "withdraw_hold"
+    ALTER COLUMN expires_at TYPE timestamp,
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN updated_at TYPE timestamp;
+ALTER TABLE "transfer" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "bonus" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "tier_history" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "withdraw" ALTER COLUMN created_at TYPE timestamp;
+ALTER TABLE "order"
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN updated_at TYPE timestamp;
+ALTER TABLE "user"
+    ALTER COLUMN created_at TYPE timestamp,
+    ALTER COLUMN updated_at TYPE timestamp;
+COMMIT;
+
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION bump_user_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_version
+    BEFORE UPDATE OF balance, withdrawn, held, tier ON "user"
+    FOR EACH ROW
+    WHEN (OLD.balance IS DISTINCT FROM NEW.balance
+        OR OLD.withdrawn IS DISTINCT FROM NEW.withdrawn
+        OR OLD.held IS DISTINCT FROM NEW.held
+        OR OLD.tier IS DISTINCT FROM NEW.tier)
+    EXECUTE FUNCTION bump_user_version();
+
+-- checks that only move updated_at do not bump the version
+CREATE OR REPLACE FUNCTION bump_order_user_version() RETURNS trigger AS $$
+BEGIN
+    UPDATE "user" SET version = version + 1 WHERE id = NEW.user_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_user_version_insert
+    AFTER INSERT ON "order"
+    FOR EACH ROW EXECUTE FUNCTION bump_order_user_version();
+
+CREATE TRIGGER order_user_version_update
+    AFTER UPDATE OF status, accrual ON "order"
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.accrual IS DISTINCT FROM NEW.accrual)
+    EXECUTE FUNCTION bump_order_user_version();
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS order_user_version_update ON "order";
+DROP TRIGGER IF EXISTS order_user_version_insert ON "order";
+DROP TRIGGER IF EXISTS user_version ON "user";
+DROP FUNCTION IF EXISTS bump_order_user_version;
+DROP FUNCTION IF EXISTS bump_user_version;
+ALTER TABLE "user" DROP COLUMN IF EXISTS version;
+
+-- +goose StatementEnd
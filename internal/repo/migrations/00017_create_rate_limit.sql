@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "rate_limit"
+(
+    key           TEXT PRIMARY KEY,
+    window_start  timestamptz NOT NULL,
+    window_end    timestamptz NOT NULL,
+    count         bigint NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_rate_limit_window_end ON "rate_limit"(window_end);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_rate_limit_window_end;
+DROP TABLE IF EXISTS "rate_limit";
+
+-- +goose StatementEnd
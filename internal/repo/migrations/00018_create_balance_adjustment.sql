@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "balance_adjustment"
+(
+    id            bigserial PRIMARY KEY,
+    user_id       bigint NOT NULL,
+    amount        double precision NOT NULL,
+    reason        text NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT CH_balance_adjustment_amount CHECK (amount <> 0),
+    CONSTRAINT CH_balance_adjustment_reason CHECK (reason <> ''),
+    CONSTRAINT FK_balance_adjustment_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_balance_adjustment_user_id_created_at ON "balance_adjustment"(user_id, created_at);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_balance_adjustment_user_id_created_at;
+DROP TABLE IF EXISTS "balance_adjustment";
+
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE TABLE IF NOT EXISTS "balance_correction"
+(
+    id                bigserial PRIMARY KEY,
+    user_id           bigint NOT NULL,
+    balance_before    double precision NOT NULL,
+    balance_after     double precision NOT NULL,
+    withdrawn_before  double precision NOT NULL,
+    withdrawn_after   double precision NOT NULL,
+    created_at        timestamptz NOT NULL DEFAULT NOW(),
+    CONSTRAINT FK_balance_correction_user FOREIGN KEY(user_id) REFERENCES "user"(id)
+                                                    ON DELETE RESTRICT
+                                                    ON UPDATE CASCADE
+);
+CREATE INDEX idx_balance_correction_user_id ON "balance_correction"(user_id);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_balance_correction_user_id;
+DROP TABLE IF EXISTS "balance_correction";
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+CREATE INDEX IF NOT EXISTS idx_user_event_created_at ON "user_event"(created_at);
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_user_event_created_at;
+
+-- +goose StatementEnd
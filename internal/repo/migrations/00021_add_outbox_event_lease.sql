@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+BEGIN;
+ALTER TABLE "outbox_event" ADD COLUMN IF NOT EXISTS leased_until timestamptz NULL;
+COMMIT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE "outbox_event" DROP COLUMN IF EXISTS leased_until;
+
+-- +goose StatementEnd
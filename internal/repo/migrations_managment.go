@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"embed"
 	"fmt"
 
@@ -12,6 +13,11 @@ import (
 var embedMigrations embed.FS
 
 func (db *DB) runMigrations() error {
+	return db.Migrate(context.Background(), "up")
+}
+
+// Migrate runs the goose command, like up, down, status or down-to, over the embedded migrations.
+func (db *DB) Migrate(ctx context.Context, command string, args ...string) error {
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect(string(goose.DialectPostgres)); err != nil {
@@ -19,7 +25,10 @@ func (db *DB) runMigrations() error {
 	}
 
 	dbFromPool := stdlib.OpenDBFromPool(db.pool)
-	if err := goose.Up(dbFromPool, "migrations"); err != nil {
+	defer func() {
+		_ = dbFromPool.Close()
+	}()
+	if err := goose.RunContext(ctx, command, dbFromPool, "migrations", args...); err != nil {
 		return fmt.Errorf("failed to migrate, %w", err)
 	}
 	return nil
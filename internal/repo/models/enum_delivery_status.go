@@ -0,0 +1,17 @@
+package models
+
+type DeliveryStatus int
+
+const (
+	QUEUED DeliveryStatus = iota + 1
+	DELIVERED
+	FAILED
+)
+
+func (ds DeliveryStatus) String() string {
+	return [...]string{"QUEUED", "DELIVERED", "FAILED"}[ds-1]
+}
+
+func (ds DeliveryStatus) Index() int {
+	return int(ds)
+}
@@ -0,0 +1,18 @@
+package models
+
+type HoldStatus int
+
+const (
+	HELD HoldStatus = iota + 1
+	CAPTURED
+	RELEASED
+	EXPIRED
+)
+
+func (hs HoldStatus) String() string {
+	return [...]string{"HELD", "CAPTURED", "RELEASED", "EXPIRED"}[hs-1]
+}
+
+func (hs HoldStatus) Index() int {
+	return int(hs)
+}
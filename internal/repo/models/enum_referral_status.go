@@ -0,0 +1,16 @@
+package models
+
+type ReferralStatus int
+
+const (
+	PENDING ReferralStatus = iota + 1
+	REWARDED
+)
+
+func (rs ReferralStatus) String() string {
+	return [...]string{"PENDING", "REWARDED"}[rs-1]
+}
+
+func (rs ReferralStatus) Index() int {
+	return int(rs)
+}
@@ -6,21 +6,34 @@ import (
 )
 
 type User struct {
-	ID        int64
-	Login     string
-	Password  string
-	Balance   float64
-	Withdrawn float64
-	CreatedAt time.Time
+	ID           int64
+	Login        string
+	Password     string
+	Balance      float64
+	Withdrawn    float64
+	Held         float64
+	Tier         string
+	Blocked      bool
+	ReferralCode string
+	CreatedAt    time.Time
 }
 
 type Order struct {
+	ID          int64
+	Status      string
+	UserID      int64
+	Accrual     sql.NullFloat64
+	UploadedAt  time.Time
+	UpdatedAt   time.Time
+	ProcessedAt sql.NullTime
+}
+
+type OrderStatusChange struct {
 	ID        int64
+	OrderID   int64
 	Status    string
-	UserID    int64
-	Accrual   sql.NullFloat64
+	Accrual   float64
 	CreatedAt time.Time
-	UpdatedAt time.Time
 }
 
 type Withdraw struct {
@@ -30,3 +43,150 @@ type Withdraw struct {
 	Sum       float64
 	CreatedAt time.Time
 }
+
+type WithdrawStat struct {
+	HourCount int64
+	DaySum    float64
+	MonthSum  float64
+}
+
+type LoyaltyTier struct {
+	Name       string
+	Threshold  float64
+	Multiplier float64
+}
+
+type UserVolume struct {
+	UserID int64
+	Tier   string
+	Volume float64
+}
+
+type Transfer struct {
+	ID          int64
+	SenderID    int64
+	RecipientID int64
+	Sum         float64
+	CreatedAt   time.Time
+}
+
+type Hold struct {
+	ID        int64
+	UserID    int64
+	OrderID   int64
+	Sum       float64
+	Status    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Referral struct {
+	ID         int64
+	ReferrerID int64
+	RefereeID  int64
+	Status     string
+	CreatedAt  time.Time
+	RewardedAt sql.NullTime
+}
+
+type ReferralInfo struct {
+	Referral
+	RefereeLogin string
+}
+
+type Campaign struct {
+	ID           int64
+	Name         string
+	Kind         string
+	Value        float64
+	StartsAt     time.Time
+	EndsAt       time.Time
+	NewUsersOnly bool
+	Tiers        []string
+	CreatedAt    time.Time
+}
+
+type UserEvent struct {
+	ID        int64
+	UserID    int64
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type Webhook struct {
+	ID        int64
+	UserID    int64
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int64
+	NextAttemptAt  time.Time
+	LastStatusCode int64
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+type DueDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+	Active bool
+}
+
+type OutboxEvent struct {
+	ID        int64
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// StatementEntry is a single balance change, the first entry of a statement has kind OPENING
+// and the sum of all changes before the period as amount. ID is the row id in the table of the kind.
+type StatementEntry struct {
+	ID      int64
+	At      time.Time
+	Kind    string
+	OrderID *int64
+	Source  string
+	Amount  float64
+}
+
+// StatementCursor is the last entry of a statement page, the zero cursor starts with the opening balance.
+type StatementCursor struct {
+	At   time.Time
+	Kind string
+	ID   int64
+}
+
+// BalanceMismatch is a user whose balance or withdrawn differs from the sums of the ledger.
+type BalanceMismatch struct {
+	UserID          int64
+	Login           string
+	Balance         float64
+	Withdrawn       float64
+	LedgerBalance   float64
+	LedgerWithdrawn float64
+}
+
+// ListFilter narrows GetOrders and GetWithdrawals, nil fields are not applied.
+type ListFilter struct {
+	Statuses  []string
+	From      *time.Time
+	To        *time.Time
+	MinAmount *float64
+	MaxAmount *float64
+	Desc      bool
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+// CreateOrders inserts the orders that are not uploaded yet and returns their ids,
+// concurrent uploads of the same order do not fail the transaction.
+// The ids are inserted in ascending order, so concurrent batches take the row locks in the same order
+// and can't deadlock.
+func (tx *Tx) CreateOrders(ctx context.Context, userID int64, orderIDs []int64) (created []int64, err error) {
+	var rows pgx.Rows
+
+	orderIDs = slices.Clone(orderIDs)
+	slices.Sort(orderIDs)
+	orderIDs = slices.Compact(orderIDs)
+
+	const query = `
+		INSERT INTO "order" (id, status, user_id)
+		SELECT ids.id, $2, $3
+		FROM unnest($1::bigint[]) AS ids(id)
+		ORDER BY ids.id
+		ON CONFLICT (id) DO NOTHING
+		RETURNING  "order".id;
+	`
+	rows, err = tx.Query(ctx, query, orderIDs, models.NEW.String(), userID)
+	if err != nil {
+		return created, fmt.Errorf("CreateOrders failed, %w", err)
+	}
+
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return created, fmt.Errorf("CreateOrders failed, %w", err)
+		}
+		created = append(created, id)
+	}
+	if err = rows.Err(); err != nil {
+		return created, fmt.Errorf("CreateOrders failed, %w", err)
+	}
+	tx.logger.Debugf("Create orders, count, %v", len(created))
+	return created, nil
+}
+
+// GetOrderOwners returns user id by order id for the existing orders.
+func (tx *Tx) GetOrderOwners(ctx context.Context, orderIDs []int64) (owners map[int64]int64, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT o.id, o.user_id
+		FROM "order" o
+		WHERE o.id = ANY($1::bigint[]);
+	`
+	rows, err = tx.Query(ctx, query, orderIDs)
+	if err != nil {
+		return owners, fmt.Errorf("get order owners failed, %w", err)
+	}
+
+	owners = make(map[int64]int64, len(orderIDs))
+	for rows.Next() {
+		var orderID, userID int64
+		if err = rows.Scan(&orderID, &userID); err != nil {
+			return owners, fmt.Errorf("get order owners failed, %w", err)
+		}
+		owners[orderID] = userID
+	}
+	if err = rows.Err(); err != nil {
+		return owners, fmt.Errorf("get order owners failed, %w", err)
+	}
+
+	return owners, nil
+}
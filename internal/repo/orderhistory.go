@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateOrderStatus(
+	ctx context.Context,
+	orderID int64,
+	status string,
+	accrual float64,
+) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "order_status_history" (order_id, status, accrual)
+		VALUES ($1, $2, $3)
+		RETURNING  "order_status_history".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		orderID, status, accrual,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateOrderStatus failed, %w", err)
+	}
+	tx.logger.Debugf("Create order status, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetOrderStatusHistory(
+	ctx context.Context,
+	orderID int64,
+) (history []models.OrderStatusChange, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT h.id, h.order_id, h.status, h.accrual, h.created_at
+		FROM "order_status_history" h
+		WHERE h.order_id = $1
+		ORDER BY h.id;
+	`
+	rows, err = tx.Query(ctx, query, orderID)
+	if err != nil {
+		return history, fmt.Errorf("get order status history failed, %w", err)
+	}
+
+	for rows.Next() {
+		var h models.OrderStatusChange
+		err = rows.Scan(
+			&h.ID,
+			&h.OrderID,
+			&h.Status,
+			&h.Accrual,
+			&h.CreatedAt,
+		)
+		if err != nil {
+			return history, fmt.Errorf("get order status history failed, %w", err)
+		}
+		history = append(history, h)
+	}
+	if err = rows.Err(); err != nil {
+		return history, fmt.Errorf("get order status history failed, %w", err)
+	}
+
+	return history, nil
+}
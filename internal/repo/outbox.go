@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "outbox_event" (type, payload)
+		VALUES ($1, $2)
+		RETURNING  "outbox_event".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		eventType, payload,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateOutboxEvent failed, %w", err)
+	}
+	tx.logger.Debugf("Create outbox event, id, %v", id)
+	return
+}
+
+// ClaimPendingOutboxEvents leases not yet dispatched events until leaseUntil,
+// so the next claims skip them while they are being sent. The events are returned in id order.
+func (tx *Tx) ClaimPendingOutboxEvents(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	limit int64,
+) (events []models.OutboxEvent, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		WITH claimed AS (
+		    UPDATE "outbox_event" e
+		    SET leased_until = $2
+		    WHERE e.id IN (
+		        SELECT q.id
+		        FROM "outbox_event" q
+		        WHERE q.dispatched_at IS NULL
+		          AND (q.leased_until IS NULL OR q.leased_until <= $1)
+		        ORDER BY q.id
+		        LIMIT $3
+		        FOR UPDATE SKIP LOCKED
+		    )
+		    RETURNING e.id, e.type, e.payload, e.created_at
+		)
+		SELECT c.id, c.type, c.payload, c.created_at
+		FROM claimed c
+		ORDER BY c.id;
+	`
+	rows, err = tx.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return events, fmt.Errorf("claim outbox events failed, %w", err)
+	}
+
+	for rows.Next() {
+		var e models.OutboxEvent
+		err = rows.Scan(
+			&e.ID,
+			&e.Type,
+			&e.Payload,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return events, fmt.Errorf("claim outbox events failed, %w", err)
+		}
+		events = append(events, e)
+	}
+	if err = rows.Err(); err != nil {
+		return events, fmt.Errorf("claim outbox events failed, %w", err)
+	}
+
+	return events, nil
+}
+
+func (tx *Tx) MarkOutboxEventsDispatched(ctx context.Context, ids []int64, at time.Time) (err error) {
+	const query = `
+		UPDATE "outbox_event"
+		SET dispatched_at = $1
+		WHERE "outbox_event".id = ANY($2);
+	`
+
+	tag, err := tx.Exec(ctx, query, at, ids)
+	if err != nil {
+		return fmt.Errorf("MarkOutboxEventsDispatched failed, %w", err)
+	}
+	tx.logger.Debugf("Mark outbox events dispatched, count, %v", tag.RowsAffected())
+	return
+}
+
+func (tx *Tx) DeleteDispatchedOutboxEvents(ctx context.Context, before time.Time) (err error) {
+	const query = `
+		DELETE FROM "outbox_event"
+		WHERE dispatched_at < $1;
+	`
+
+	tag, err := tx.Exec(ctx, query, before)
+	if err != nil {
+		return fmt.Errorf("DeleteDispatchedOutboxEvents failed, %w", err)
+	}
+	tx.logger.Debugf("Delete dispatched outbox events, count, %v", tag.RowsAffected())
+	return
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TakeRateLimit counts a request of the key in the current window, windows are aligned
+// to the epoch by the database clock.
+func (tx *Tx) TakeRateLimit(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+) (count int64, reset time.Time, err error) {
+	const query = `
+		WITH w AS (
+			SELECT to_timestamp(
+			    floor(extract(epoch FROM NOW())::double precision / $2::double precision) * $2::double precision
+			) AS start
+		)
+		INSERT INTO "rate_limit" (key, window_start, window_end, count)
+		SELECT $1, w.start, w.start + make_interval(secs => $2::double precision), 1
+		FROM w
+		ON CONFLICT (key) DO UPDATE
+		SET count = CASE
+		        WHEN "rate_limit".window_start = EXCLUDED.window_start THEN "rate_limit".count + 1
+		        ELSE 1
+		    END,
+		    window_start = EXCLUDED.window_start,
+		    window_end = EXCLUDED.window_end
+		RETURNING "rate_limit".count, "rate_limit".window_end;
+	`
+	err = tx.QueryRow(ctx, query, key, window.Seconds()).Scan(&count, &reset)
+	if err != nil {
+		return count, reset, fmt.Errorf("take rate limit failed, %w", err)
+	}
+	return count, reset, nil
+}
+
+func (tx *Tx) DeleteExpiredRateLimits(ctx context.Context) (err error) {
+	const query = `
+		DELETE FROM "rate_limit"
+		WHERE window_end < NOW();
+	`
+	tag, err := tx.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("delete expired rate limits failed, %w", err)
+	}
+	tx.logger.Debugf("Delete expired rate limits, %v", tag.RowsAffected())
+	return nil
+}
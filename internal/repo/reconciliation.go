@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+// ledgerQuery sums the balance changes like GetStatement and the withdrawals of every user.
+const ledgerQuery = `
+	WITH ledger (user_id, amount) AS (
+		SELECT o.user_id, o.accrual
+		FROM "order" o
+		WHERE o.status = 'PROCESSED' AND o.accrual > 0
+		UNION ALL
+		SELECT b.user_id, b.amount
+		FROM "bonus" b
+		UNION ALL
+		SELECT w.user_id, -w.sum
+		FROM "withdraw" w
+		UNION ALL
+		SELECT t.recipient_id, t.sum
+		FROM "transfer" t
+		UNION ALL
+		SELECT t.sender_id, -t.sum
+		FROM "transfer" t
+		UNION ALL
+		SELECT a.user_id, a.amount
+		FROM "balance_adjustment" a
+	), balances AS (
+		SELECT l.user_id, SUM(l.amount) AS balance
+		FROM ledger l
+		GROUP BY l.user_id
+	), withdrawals AS (
+		SELECT w.user_id, SUM(w.sum) AS withdrawn
+		FROM "withdraw" w
+		GROUP BY w.user_id
+	)
+	SELECT u.id, u.login, u.balance, u.withdrawn, COALESCE(b.balance, 0), COALESCE(w.withdrawn, 0)
+	FROM "user" u
+	LEFT JOIN balances b ON b.user_id = u.id
+	LEFT JOIN withdrawals w ON w.user_id = u.id
+`
+
+// GetBalanceMismatches returns the users whose balance or withdrawn differs from the ledger by more than tolerance.
+func (tx *Tx) GetBalanceMismatches(
+	ctx context.Context,
+	tolerance float64,
+) (mismatches []models.BalanceMismatch, err error) {
+	var rows pgx.Rows
+
+	const query = ledgerQuery + `
+	WHERE ABS(u.balance - COALESCE(b.balance, 0)) > $1
+	   OR ABS(u.withdrawn - COALESCE(w.withdrawn, 0)) > $1
+	ORDER BY u.id;
+	`
+	rows, err = tx.Query(ctx, query, tolerance)
+	if err != nil {
+		return mismatches, fmt.Errorf("get balance mismatches failed, %w", err)
+	}
+
+	for rows.Next() {
+		var m models.BalanceMismatch
+		err = rows.Scan(&m.UserID, &m.Login, &m.Balance, &m.Withdrawn, &m.LedgerBalance, &m.LedgerWithdrawn)
+		if err != nil {
+			return mismatches, fmt.Errorf("get balance mismatches failed, %w", err)
+		}
+		mismatches = append(mismatches, m)
+	}
+	if err = rows.Err(); err != nil {
+		return mismatches, fmt.Errorf("get balance mismatches failed, %w", err)
+	}
+
+	return mismatches, nil
+}
+
+// GetUserLedger returns the user balance and withdrawn next to their ledger sums,
+// the caller locks the user first to get a consistent pair.
+func (tx *Tx) GetUserLedger(ctx context.Context, userID int64) (m models.BalanceMismatch, err error) {
+	const query = ledgerQuery + `
+	WHERE u.id = $1;
+	`
+	err = tx.QueryRow(ctx, query, userID).Scan(
+		&m.UserID,
+		&m.Login,
+		&m.Balance,
+		&m.Withdrawn,
+		&m.LedgerBalance,
+		&m.LedgerWithdrawn,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return m, fmt.Errorf("get user ledger failed, %w", err)
+	}
+	return m, nil
+}
+
+func (tx *Tx) CreateBalanceCorrection(
+	ctx context.Context,
+	userID int64,
+	balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter float64,
+) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "balance_correction" (user_id, balance_before, balance_after, withdrawn_before, withdrawn_after)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING  "balance_correction".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateBalanceCorrection failed, %w", err)
+	}
+	tx.logger.Debugf("Create balance correction, id, %v", id)
+	return
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) GetUserByReferralCode(ctx context.Context, code string) (u models.User, err error) {
+	const query = `
+		SELECT u.id, u.login, u.password, u.balance, u.withdrawn, u.held, u.tier, u.blocked, u.referral_code, u.created_at
+		FROM "user" u
+		WHERE u.referral_code = $1;
+	`
+	err = tx.QueryRow(ctx, query, code).Scan(
+		&u.ID,
+		&u.Login,
+		&u.Password,
+		&u.Balance,
+		&u.Withdrawn,
+		&u.Held,
+		&u.Tier,
+		&u.Blocked,
+		&u.ReferralCode,
+		&u.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return u, fmt.Errorf("get user by referral code failed, %w", err)
+	}
+
+	return u, nil
+}
+
+func (tx *Tx) CreateReferral(ctx context.Context, referrerID, refereeID int64) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "referral" (referrer_id, referee_id)
+		VALUES ($1, $2)
+		RETURNING  "referral".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		referrerID, refereeID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateReferral failed, %w", err)
+	}
+	tx.logger.Debugf("Create referral, id, %v", id)
+	return
+}
+
+func (tx *Tx) CountReferrals(ctx context.Context, referrerID int64) (count int64, err error) {
+	const query = `
+		SELECT COUNT(r.id)
+		FROM "referral" r
+		WHERE r.referrer_id = $1;
+	`
+	err = tx.QueryRow(ctx, query, referrerID).Scan(&count)
+	if err != nil {
+		return count, fmt.Errorf("count referrals failed, %w", err)
+	}
+	return count, nil
+}
+
+func (tx *Tx) GetReferralByReferee(
+	ctx context.Context,
+	refereeID int64,
+	forUpdate bool,
+) (r models.Referral, err error) {
+	var query string
+	if forUpdate {
+		query = `
+		SELECT r.id, r.referrer_id, r.referee_id, r.status, r.created_at, r.rewarded_at
+		FROM "referral" r
+		WHERE r.referee_id = $1
+		FOR UPDATE;
+	`
+	} else {
+		query = `
+		SELECT r.id, r.referrer_id, r.referee_id, r.status, r.created_at, r.rewarded_at
+		FROM "referral" r
+		WHERE r.referee_id = $1;
+	`
+	}
+	err = tx.QueryRow(ctx, query, refereeID).Scan(
+		&r.ID,
+		&r.ReferrerID,
+		&r.RefereeID,
+		&r.Status,
+		&r.CreatedAt,
+		&r.RewardedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return r, fmt.Errorf("get referral failed, %w", err)
+	}
+
+	return r, nil
+}
+
+func (tx *Tx) GetReferrals(ctx context.Context, referrerID int64) (referrals []models.ReferralInfo, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT r.id, r.referrer_id, r.referee_id, r.status, r.created_at, r.rewarded_at, u.login
+		FROM "referral" r
+		JOIN "user" u ON u.id = r.referee_id
+		WHERE r.referrer_id = $1
+		ORDER BY r.created_at;
+	`
+	rows, err = tx.Query(ctx, query, referrerID)
+	if err != nil {
+		return referrals, fmt.Errorf("get referrals failed, %w", err)
+	}
+
+	for rows.Next() {
+		var r models.ReferralInfo
+		err = rows.Scan(
+			&r.ID,
+			&r.ReferrerID,
+			&r.RefereeID,
+			&r.Status,
+			&r.CreatedAt,
+			&r.RewardedAt,
+			&r.RefereeLogin,
+		)
+		if err != nil {
+			return referrals, fmt.Errorf("get referrals failed, %w", err)
+		}
+		referrals = append(referrals, r)
+	}
+	if err = rows.Err(); err != nil {
+		return referrals, fmt.Errorf("get referrals failed, %w", err)
+	}
+
+	return referrals, nil
+}
+
+func (tx *Tx) RewardReferral(ctx context.Context, referralID int64) (err error) {
+	var id int64
+	const query = `
+		UPDATE "referral"
+		SET status = $1, rewarded_at = $2
+		WHERE "referral".id = $3
+		RETURNING  "referral".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		models.REWARDED.String(),
+		time.Now(),
+		referralID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("RewardReferral failed, %w", err)
+	}
+	tx.logger.Debugf("Reward referral, id, %v", id)
+	return
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+// GetStatement streams up to limit user balance changes in [from, to) after the cursor to fn,
+// ordered by time, kind and id. The zero cursor starts with the opening balance.
+func (tx *Tx) GetStatement(
+	ctx context.Context,
+	userID int64,
+	from, to time.Time,
+	after models.StatementCursor,
+	limit int64,
+	fn func(e models.StatementEntry) error,
+) (err error) {
+	var rows pgx.Rows
+
+	const query = `
+		WITH ledger (id, at, kind, order_id, source, amount) AS (
+			SELECT o.id, o.processed_at, 'ACCRUAL', o.id, '', o.accrual
+			FROM "order" o
+			WHERE o.user_id = $1 AND o.status = 'PROCESSED' AND o.accrual > 0
+			UNION ALL
+			SELECT b.id, b.created_at, 'BONUS', b.order_id, b.source, b.amount
+			FROM "bonus" b
+			WHERE b.user_id = $1
+			UNION ALL
+			SELECT w.id, w.created_at, 'WITHDRAWAL', w.order_id, '', -w.sum
+			FROM "withdraw" w
+			WHERE w.user_id = $1
+			UNION ALL
+			SELECT t.id, t.created_at, 'TRANSFER_IN', NULL, '', t.sum
+			FROM "transfer" t
+			WHERE t.recipient_id = $1
+			UNION ALL
+			SELECT t.id, t.created_at, 'TRANSFER_OUT', NULL, '', -t.sum
+			FROM "transfer" t
+			WHERE t.sender_id = $1
+			UNION ALL
+			SELECT a.id, a.created_at, 'ADJUSTMENT', NULL, a.reason, a.amount
+			FROM "balance_adjustment" a
+			WHERE a.user_id = $1
+		)
+		SELECT s.id, s.at, s.kind, s.order_id, s.source, s.amount
+		FROM (
+			SELECT 0 AS part, 0::bigint AS id, $2::timestamptz AS at, 'OPENING' AS kind,
+			       NULL::bigint AS order_id, '' AS source, COALESCE(SUM(l.amount), 0) AS amount
+			FROM ledger l
+			WHERE l.at < $2
+			HAVING $7::boolean
+			UNION ALL
+			(
+				SELECT 1, l.id, l.at, l.kind, l.order_id, l.source, l.amount
+				FROM ledger l
+				WHERE l.at >= $2 AND l.at < $3 AND (l.at, l.kind, l.id) > ($4::timestamptz, $5::text, $6::bigint)
+				ORDER BY l.at, l.kind, l.id
+				LIMIT $8
+			)
+		) s
+		ORDER BY s.part, s.at, s.kind, s.id;
+	`
+	first := after == models.StatementCursor{}
+	rows, err = tx.Query(ctx, query, userID, from, to, after.At, after.Kind, after.ID, first, limit)
+	if err != nil {
+		return fmt.Errorf("get statement failed, %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.StatementEntry
+		err = rows.Scan(
+			&e.ID,
+			&e.At,
+			&e.Kind,
+			&e.OrderID,
+			&e.Source,
+			&e.Amount,
+		)
+		if err != nil {
+			return fmt.Errorf("get statement failed, %w", err)
+		}
+		if err = fn(e); err != nil {
+			return fmt.Errorf("get statement failed, %w", err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("get statement failed, %w", err)
+	}
+
+	return nil
+}
@@ -5,28 +5,34 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/storage"
 )
 
-func (db *DB) OpenTransaction(ctx context.Context) (pgx.Tx, error) {
-	tx, err := db.pool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("BeginTx CreateCounterMetric failed, %w", err)
-	}
-	return tx, nil
+// Tx is the storage.Store of a Postgres transaction.
+type Tx struct {
+	pgx.Tx
+
+	logger *logrus.Logger
 }
 
-func (db *DB) Rollback(ctx context.Context, tx pgx.Tx) error {
-	err := tx.Rollback(ctx)
+// WithTx runs fn in a transaction, it is rolled back if fn fails.
+func (db *DB) WithTx(ctx context.Context, fn func(s storage.Store) error) error {
+	pgTx, err := db.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return fmt.Errorf("rollback failed, %w", err)
+		return fmt.Errorf("failed to open transaction, %w", err)
 	}
-	return nil
-}
+	defer func() {
+		// a no-op after the commit
+		_ = pgTx.Rollback(ctx)
+	}()
 
-func (db *DB) Commit(ctx context.Context, tx pgx.Tx) error {
-	err := tx.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("commit failed, %w", err)
+	if err = fn(&Tx{Tx: pgTx, logger: db.logger}); err != nil {
+		return err
+	}
+	if err = pgTx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit, %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (tx *Tx) CreateTransfer(ctx context.Context, senderID, recipientID int64, sum float64) (err error) {
+	var id int64
+
+	const query = `
+		INSERT INTO "transfer" (sender_id, recipient_id, sum)
+		VALUES ($1, $2, $3)
+		RETURNING  "transfer".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		senderID, recipientID, sum,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("CreateTransfer failed, %w", err)
+	}
+	tx.logger.Debugf("Create transfer, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetSentTransfersStat(
+	ctx context.Context,
+	senderID int64,
+	since time.Time,
+) (count int64, sum float64, err error) {
+	const query = `
+		SELECT COUNT(t.id), COALESCE(SUM(t.sum), 0)
+		FROM "transfer" t
+		WHERE t.sender_id = $1
+		  AND t.created_at >= $2;
+	`
+	err = tx.QueryRow(ctx, query, senderID, since).Scan(&count, &sum)
+	if err != nil {
+		return count, sum, fmt.Errorf("get sent transfers stat failed, %w", err)
+	}
+	return count, sum, nil
+}
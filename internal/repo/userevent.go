@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+// GetUserEvents returns the user events with id greater than afterID created since the given time, in id order.
+func (tx *Tx) GetUserEvents(
+	ctx context.Context,
+	userID, afterID int64,
+	since time.Time,
+	limit int64,
+) (events []models.UserEvent, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT e.id, e.user_id, e.type, e.payload, e.created_at
+		FROM "user_event" e
+		WHERE e.user_id = $1
+		  AND e.id > $2
+		  AND e.created_at >= $3
+		ORDER BY e.id
+		LIMIT $4;
+	`
+	rows, err = tx.Query(ctx, query, userID, afterID, since, limit)
+	if err != nil {
+		return events, fmt.Errorf("get user events failed, %w", err)
+	}
+
+	for rows.Next() {
+		var e models.UserEvent
+		err = rows.Scan(
+			&e.ID,
+			&e.UserID,
+			&e.Type,
+			&e.Payload,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return events, fmt.Errorf("get user events failed, %w", err)
+		}
+		events = append(events, e)
+	}
+	if err = rows.Err(); err != nil {
+		return events, fmt.Errorf("get user events failed, %w", err)
+	}
+
+	return events, nil
+}
+
+func (tx *Tx) GetLastUserEventID(ctx context.Context, userID int64) (id int64, err error) {
+	const query = `
+		SELECT COALESCE(MAX(e.id), 0)
+		FROM "user_event" e
+		WHERE e.user_id = $1;
+	`
+	err = tx.QueryRow(ctx, query, userID).Scan(&id)
+	if err != nil {
+		return id, fmt.Errorf("get last user event id failed, %w", err)
+	}
+	return id, nil
+}
+
+func (tx *Tx) DeleteUserEvents(ctx context.Context, before time.Time) (err error) {
+	const query = `
+		DELETE FROM "user_event"
+		WHERE created_at < $1;
+	`
+
+	tag, err := tx.Exec(ctx, query, before)
+	if err != nil {
+		return fmt.Errorf("DeleteUserEvents failed, %w", err)
+	}
+	tx.logger.Debugf("Delete user events, count, %v", tag.RowsAffected())
+	return
+}
+
+// Listen holds a pool connection subscribed to the channel and calls notify for every payload
+// until ctx is done or the connection breaks.
+func (db *DB) Listen(ctx context.Context, channel string, notify func(payload string)) error {
+	conn, err := db.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection, %w", err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("LISTEN %s failed, %w", channel, err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "UNLISTEN *")
+	}()
+	db.logger.Debugf("Listen channel, %s", channel)
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification failed, %w", err)
+		}
+		notify(n.Payload)
+	}
+}
@@ -0,0 +1,316 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateWebhook(
+	ctx context.Context,
+	userID int64,
+	url, secret string,
+	events []string,
+) (w models.Webhook, err error) {
+	const query = `
+		INSERT INTO "webhook" (user_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING  "webhook".id, "webhook".user_id, "webhook".url, "webhook".secret,
+		           "webhook".events, "webhook".active, "webhook".created_at;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		userID, url, secret, events,
+	).Scan(
+		&w.ID,
+		&w.UserID,
+		&w.URL,
+		&w.Secret,
+		&w.Events,
+		&w.Active,
+		&w.CreatedAt,
+	)
+
+	if err != nil {
+		return w, fmt.Errorf("CreateWebhook failed, %w", err)
+	}
+	tx.logger.Debugf("Create webhook, id, %v", w.ID)
+	return
+}
+
+func (tx *Tx) GetWebhook(ctx context.Context, webhookID int64) (w models.Webhook, err error) {
+	const query = `
+		SELECT w.id, w.user_id, w.url, w.secret, w.events, w.active, w.created_at
+		FROM "webhook" w
+		WHERE w.id = $1;
+	`
+	err = tx.QueryRow(ctx, query, webhookID).Scan(
+		&w.ID,
+		&w.UserID,
+		&w.URL,
+		&w.Secret,
+		&w.Events,
+		&w.Active,
+		&w.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = customerrors.ErrNotFound
+			return
+		}
+		return w, fmt.Errorf("get webhook failed, %w", err)
+	}
+	return w, nil
+}
+
+func (tx *Tx) GetWebhooks(ctx context.Context, userID int64) (webhooks []models.Webhook, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT w.id, w.user_id, w.url, w.secret, w.events, w.active, w.created_at
+		FROM "webhook" w
+		WHERE w.user_id = $1
+		  AND w.active
+		ORDER BY w.id;
+	`
+	rows, err = tx.Query(ctx, query, userID)
+	if err != nil {
+		return webhooks, fmt.Errorf("get webhooks failed, %w", err)
+	}
+
+	for rows.Next() {
+		var w models.Webhook
+		err = rows.Scan(
+			&w.ID,
+			&w.UserID,
+			&w.URL,
+			&w.Secret,
+			&w.Events,
+			&w.Active,
+			&w.CreatedAt,
+		)
+		if err != nil {
+			return webhooks, fmt.Errorf("get webhooks failed, %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err = rows.Err(); err != nil {
+		return webhooks, fmt.Errorf("get webhooks failed, %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (tx *Tx) DisableWebhook(ctx context.Context, webhookID int64) (err error) {
+	var id int64
+	const query = `
+		UPDATE "webhook"
+		SET active = false
+		WHERE "webhook".id = $1
+		RETURNING  "webhook".id;
+	`
+
+	err = tx.QueryRow(ctx, query, webhookID).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("DisableWebhook failed, %w", err)
+	}
+	tx.logger.Debugf("Disable webhook, id, %v", id)
+	return
+}
+
+// CreateWebhookDeliveries queues the event for every active webhook of the user subscribed to it.
+func (tx *Tx) CreateWebhookDeliveries(
+	ctx context.Context,
+	userID int64,
+	event string,
+	payload []byte,
+) (err error) {
+	const query = `
+		INSERT INTO "webhook_delivery" (webhook_id, event, payload)
+		SELECT w.id, $2, $3
+		FROM "webhook" w
+		WHERE w.user_id = $1
+		  AND w.active
+		  AND $2 = ANY(w.events);
+	`
+
+	tag, err := tx.Exec(ctx, query, userID, event, payload)
+	if err != nil {
+		return fmt.Errorf("CreateWebhookDeliveries failed, %w", err)
+	}
+	tx.logger.Debugf("Create webhook deliveries, event %s, count, %v", event, tag.RowsAffected())
+	return
+}
+
+func (tx *Tx) GetWebhookDeliveries(
+	ctx context.Context,
+	webhookID, limit int64,
+) (deliveries []models.WebhookDelivery, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		SELECT d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at,
+		       d.last_status_code, d.last_error, d.created_at, d.delivered_at
+		FROM "webhook_delivery" d
+		WHERE d.webhook_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2;
+	`
+	rows, err = tx.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return deliveries, fmt.Errorf("get webhook deliveries failed, %w", err)
+	}
+
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.Event,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		)
+		if err != nil {
+			return deliveries, fmt.Errorf("get webhook deliveries failed, %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err = rows.Err(); err != nil {
+		return deliveries, fmt.Errorf("get webhook deliveries failed, %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RequeueWebhookDelivery schedules the delivery of the webhook for an immediate new round of attempts.
+func (tx *Tx) RequeueWebhookDelivery(ctx context.Context, webhookID, deliveryID int64) (err error) {
+	var id int64
+	const query = `
+		UPDATE "webhook_delivery"
+		SET status = $1, attempts = 0, next_attempt_at = $2
+		WHERE "webhook_delivery".id = $3
+		  AND "webhook_delivery".webhook_id = $4
+		RETURNING  "webhook_delivery".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		models.QUEUED.String(),
+		time.Now(),
+		deliveryID,
+		webhookID,
+	).Scan(&id)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return customerrors.ErrNotFound
+		}
+		return fmt.Errorf("RequeueWebhookDelivery failed, %w", err)
+	}
+	tx.logger.Debugf("Requeue webhook delivery, id, %v", id)
+	return
+}
+
+// ClaimDueWebhookDeliveries leases queued deliveries until leaseUntil,
+// so other instances skip them while they are being sent.
+func (tx *Tx) ClaimDueWebhookDeliveries(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	limit int64,
+) (deliveries []models.DueDelivery, err error) {
+	var rows pgx.Rows
+
+	const query = `
+		UPDATE "webhook_delivery" d
+		SET next_attempt_at = $2
+		FROM "webhook" w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+		      SELECT q.id
+		      FROM "webhook_delivery" q
+		      WHERE q.status = 'QUEUED'
+		        AND q.next_attempt_at <= $1
+		      ORDER BY q.next_attempt_at
+		      LIMIT $3
+		      FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at,
+		          d.last_status_code, d.last_error, d.created_at, d.delivered_at,
+		          w.url, w.secret, w.active;
+	`
+	rows, err = tx.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return deliveries, fmt.Errorf("claim webhook deliveries failed, %w", err)
+	}
+
+	for rows.Next() {
+		var d models.DueDelivery
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.Event,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+			&d.URL,
+			&d.Secret,
+			&d.Active,
+		)
+		if err != nil {
+			return deliveries, fmt.Errorf("claim webhook deliveries failed, %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err = rows.Err(); err != nil {
+		return deliveries, fmt.Errorf("claim webhook deliveries failed, %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (tx *Tx) UpdateWebhookDelivery(
+	ctx context.Context,
+	d models.WebhookDelivery,
+) (err error) {
+	var id int64
+	const query = `
+		UPDATE "webhook_delivery"
+		SET status = $1, attempts = $2, next_attempt_at = $3,
+		    last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE "webhook_delivery".id = $7
+		RETURNING  "webhook_delivery".id;
+	`
+
+	err = tx.QueryRow(ctx, query,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastStatusCode,
+		d.LastError,
+		d.DeliveredAt,
+		d.ID,
+	).Scan(&id)
+
+	if err != nil {
+		return fmt.Errorf("UpdateWebhookDelivery failed, %w", err)
+	}
+	tx.logger.Debugf("Update webhook delivery, id, %v", id)
+	return
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -10,27 +11,52 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/golang-jwt/jwt"
-)
 
-const (
-	tokenTTL = 72 * time.Hour
+	"github.com/NStegura/gophermart/internal/customerrors"
 )
 
 type Service struct {
-	secretKey string
-	tokenTTL  time.Duration
+	mu sync.RWMutex
+	// secretKey signs and verifies tokens, verificationKeys only verify the ones signed before a rotation.
+	secretKey        string
+	verificationKeys []string
+
+	tokenTTL   time.Duration
+	bcryptCost int
 
 	logger *logrus.Logger
 }
 
-func New(secretKey string, logger *logrus.Logger) *Service {
+func New(
+	secretKey string,
+	verificationKeys []string,
+	tokenTTL time.Duration,
+	bcryptCost int,
+	logger *logrus.Logger,
+) *Service {
 	return &Service{
-		secretKey: secretKey,
-		tokenTTL:  tokenTTL,
-		logger:    logger,
+		secretKey:        secretKey,
+		verificationKeys: verificationKeys,
+		tokenTTL:         tokenTTL,
+		bcryptCost:       bcryptCost,
+		logger:           logger,
 	}
 }
 
+// SetKeys rotates the keys, tokens signed by the old key stay valid while it is a verification key.
+func (s *Service) SetKeys(secretKey string, verificationKeys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secretKey = secretKey
+	s.verificationKeys = verificationKeys
+}
+
+func (s *Service) keys() (secretKey string, verificationKeys []string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.secretKey, s.verificationKeys
+}
+
 type tokenClaims struct {
 	UserID int64 `json:"user_id"`
 	jwt.StandardClaims
@@ -47,14 +73,34 @@ func (s *Service) GenerateToken(userID int64) (ss string, err error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	ss, err = token.SignedString([]byte(s.secretKey))
+	secretKey, _ := s.keys()
+	ss, err = token.SignedString([]byte(secretKey))
 	if err != nil {
 		return ss, fmt.Errorf("failed to signed string: %w", err)
 	}
 	return
 }
 
+// ParseToken verifies the token by the secret key, then by the verification keys.
 func (s *Service) ParseToken(accessToken string) (int64, error) {
+	secretKey, verificationKeys := s.keys()
+
+	var err error
+	for _, key := range append([]string{secretKey}, verificationKeys...) {
+		var userID int64
+		userID, err = parseToken(accessToken, key)
+		if err == nil {
+			return userID, nil
+		}
+		var validationErr *jwt.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Errors&jwt.ValidationErrorSignatureInvalid == 0 {
+			break
+		}
+	}
+	return 0, err
+}
+
+func parseToken(accessToken, key string) (int64, error) {
 	token, err := jwt.ParseWithClaims(
 		accessToken,
 		&tokenClaims{},
@@ -62,7 +108,7 @@ func (s *Service) ParseToken(accessToken string) (int64, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, errors.New("invalid signing method")
 			}
-			return []byte(s.secretKey), nil
+			return []byte(key), nil
 		})
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse token with claims, %w", err)
@@ -73,8 +119,11 @@ func (s *Service) ParseToken(accessToken string) (int64, error) {
 	return 0, errors.New("token claims are not of type *tokenClaims or not valid")
 }
 
-func (s *Service) GeneratePasswordHash(password string, complexity int) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), complexity)
+func (s *Service) GeneratePasswordHash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", customerrors.ErrPasswordTooLong
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate password, %w", err)
 	}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+)
+
+func TestService_keyRotation(t *testing.T) {
+	s := New("old key", nil, time.Hour, bcrypt.MinCost, logrus.New())
+	oldToken, err := s.GenerateToken(1)
+	require.NoError(t, err)
+
+	s.SetKeys("new key", []string{"old key"})
+	newToken, err := s.GenerateToken(2)
+	require.NoError(t, err)
+
+	userID, err := s.ParseToken(oldToken)
+	require.NoError(t, err, "token of the previous key is verified")
+	assert.Equal(t, int64(1), userID)
+
+	userID, err = s.ParseToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), userID)
+
+	s.SetKeys("new key", nil)
+	_, err = s.ParseToken(oldToken)
+	assert.Error(t, err, "token of a retired key is rejected")
+
+	_, err = s.ParseToken("not a token")
+	assert.Error(t, err)
+}
+
+func TestService_expiredToken(t *testing.T) {
+	s := New("key", []string{"other key"}, -time.Minute, bcrypt.MinCost, logrus.New())
+	token, err := s.GenerateToken(1)
+	require.NoError(t, err)
+
+	_, err = s.ParseToken(token)
+	assert.ErrorContains(t, err, "expired")
+}
+
+func TestService_passwordHash(t *testing.T) {
+	s := New("key", nil, time.Hour, bcrypt.MinCost, logrus.New())
+	hash, err := s.GeneratePasswordHash("password")
+	require.NoError(t, err)
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, cost)
+	assert.True(t, s.CheckPasswordHash("password", hash))
+	assert.False(t, s.CheckPasswordHash("other", hash))
+
+	_, err = s.GeneratePasswordHash(strings.Repeat("é", 40))
+	require.ErrorIs(t, err, customerrors.ErrPasswordTooLong)
+}
@@ -0,0 +1,234 @@
+package business
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/clients/accrual"
+	accrualModels "github.com/NStegura/gophermart/internal/clients/accrual/models"
+	"github.com/NStegura/gophermart/internal/customerrors"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/jobs/accrualsync"
+	"github.com/NStegura/gophermart/internal/storage/memory"
+)
+
+const (
+	processedOrder int64 = 12345678903
+	invalidOrder   int64 = 79927398713
+	withdrawOrder  int64 = 2377225624
+	holdOrder      int64 = 4561261212345467
+)
+
+// newAccrualServer answers the accrual checks with the orders, unknown ones are not registered.
+func newAccrualServer(t *testing.T, orders ...accrualModels.OrderAccrual) *accrual.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, o := range orders {
+			if path.Base(r.URL.Path) == strconv.FormatInt(o.OrderID, 10) {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(o)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	cli, err := accrual.New(server.URL, logrus.New())
+	require.NoError(t, err)
+	return cli
+}
+
+// newBusiness runs the business and the accrual sync on one memory storage.
+func newBusiness(t *testing.T, orders ...accrualModels.OrderAccrual) (*Business, *accrualsync.Job) {
+	t.Helper()
+	logger := logrus.New()
+	repo := memory.New(logger)
+	b := New(repo, events.NewOutbox(), Config{
+		TierWindow:         time.Hour,
+		HoldTTL:            time.Hour,
+		TransferDailyLimit: 1000,
+		TransferDailyCount: 10,
+	}, logger)
+	job := accrualsync.New(time.Hour, 1, accrualsync.ReferralBonus{}, repo, events.NewOutbox(),
+		newAccrualServer(t, orders...), logger)
+	return b, job
+}
+
+func mustUser(t *testing.T, b *Business, id int64) domenModels.User {
+	t.Helper()
+	user, err := b.GetUserByID(context.Background(), id)
+	require.NoError(t, err)
+	return user
+}
+
+type recordingStatement struct {
+	opening float64
+	entries []domenModels.StatementEntry
+	closing float64
+}
+
+func (r *recordingStatement) Opening(balance float64) error {
+	r.opening = balance
+	return nil
+}
+
+func (r *recordingStatement) Entry(e domenModels.StatementEntry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *recordingStatement) Closing(balance float64) error {
+	r.closing = balance
+	return nil
+}
+
+func TestBusiness_flow(t *testing.T) {
+	ctx := context.Background()
+	b, job := newBusiness(t,
+		accrualModels.OrderAccrual{OrderID: processedOrder, Status: "PROCESSED", Accrual: 500},
+		accrualModels.OrderAccrual{OrderID: invalidOrder, Status: "INVALID"},
+	)
+
+	aliceID, err := b.CreateUser(ctx, "alice", "hash", "")
+	require.NoError(t, err)
+	bobID, err := b.CreateUser(ctx, "bob", "hash", "")
+	require.NoError(t, err)
+	_, err = b.CreateUser(ctx, "alice", "hash", "")
+	require.ErrorIs(t, err, customerrors.ErrAlreadyExists)
+
+	t.Run("Upload", func(t *testing.T) {
+		require.NoError(t, b.CreateOrder(ctx, aliceID, processedOrder))
+		require.NoError(t, b.CreateOrder(ctx, aliceID, invalidOrder))
+		require.ErrorIs(t, b.CreateOrder(ctx, aliceID, processedOrder), customerrors.ErrCurrUserUploaded)
+		require.ErrorIs(t, b.CreateOrder(ctx, bobID, processedOrder), customerrors.ErrAnotherUserUploaded)
+	})
+
+	t.Run("Accrue", func(t *testing.T) {
+		_, err := job.SyncOrder(ctx, processedOrder)
+		require.NoError(t, err)
+		_, err = job.SyncOrder(ctx, invalidOrder)
+		require.NoError(t, err)
+		_, err = job.SyncOrder(ctx, processedOrder)
+		require.ErrorIs(t, err, customerrors.ErrOrderProcessed, "the accrual is credited once")
+
+		orders, err := b.GetOrders(ctx, aliceID, domenModels.ListFilter{})
+		require.NoError(t, err)
+		statuses := make(map[int64]string, len(orders))
+		for _, o := range orders {
+			statuses[o.Number] = o.Status
+		}
+		assert.Equal(t, map[int64]string{processedOrder: "PROCESSED", invalidOrder: "INVALID"}, statuses)
+		assert.Equal(t, 500.0, mustUser(t, b, aliceID).Balance)
+	})
+
+	t.Run("Withdraw", func(t *testing.T) {
+		require.NoError(t, b.CreateWithdraw(ctx, aliceID, withdrawOrder, 100))
+		require.ErrorIs(t, b.CreateWithdraw(ctx, aliceID, withdrawOrder, 1000), customerrors.ErrNotEnoughFunds)
+
+		alice := mustUser(t, b, aliceID)
+		assert.Equal(t, 400.0, alice.Balance)
+		assert.Equal(t, 100.0, alice.Withdrawn)
+	})
+
+	t.Run("Hold", func(t *testing.T) {
+		released, err := b.CreateHold(ctx, aliceID, holdOrder, 300)
+		require.NoError(t, err)
+		assert.Equal(t, 300.0, mustUser(t, b, aliceID).Held)
+		require.ErrorIs(t, b.CreateWithdraw(ctx, aliceID, withdrawOrder, 150), customerrors.ErrNotEnoughFunds,
+			"the held points are not available")
+		require.NoError(t, b.ReleaseHold(ctx, aliceID, released.ID))
+		require.ErrorIs(t, b.CaptureHold(ctx, aliceID, released.ID), customerrors.ErrHoldNotActive)
+
+		captured, err := b.CreateHold(ctx, aliceID, holdOrder, 50)
+		require.NoError(t, err)
+		require.ErrorIs(t, b.CaptureHold(ctx, bobID, captured.ID), customerrors.ErrNotFound)
+		require.NoError(t, b.CaptureHold(ctx, aliceID, captured.ID))
+
+		alice := mustUser(t, b, aliceID)
+		assert.Equal(t, 350.0, alice.Balance)
+		assert.Equal(t, 150.0, alice.Withdrawn)
+		assert.Equal(t, 0.0, alice.Held)
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		require.NoError(t, b.CreateTransfer(ctx, aliceID, "bob", 50))
+		require.ErrorIs(t, b.CreateTransfer(ctx, aliceID, "alice", 1), customerrors.ErrSelfTransfer)
+		require.ErrorIs(t, b.CreateTransfer(ctx, bobID, "alice", 100), customerrors.ErrNotEnoughFunds)
+		require.ErrorIs(t, b.CreateTransfer(ctx, aliceID, "carol", 1), customerrors.ErrNotFound)
+		for _, sum := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+			require.ErrorIs(t, b.CreateTransfer(ctx, aliceID, "bob", sum), customerrors.ErrInvalidSum, sum)
+		}
+
+		assert.Equal(t, 300.0, mustUser(t, b, aliceID).Balance)
+		assert.Equal(t, 50.0, mustUser(t, b, bobID).Balance)
+	})
+
+	t.Run("Statement", func(t *testing.T) {
+		w := &recordingStatement{}
+
+		require.NoError(t, b.WriteStatement(ctx, aliceID, time.Unix(0, 0), time.Now().Add(time.Minute), w))
+
+		kinds := make([]string, 0, len(w.entries))
+		for _, e := range w.entries {
+			kinds = append(kinds, e.Kind)
+		}
+		assert.Equal(t, 0.0, w.opening)
+		assert.ElementsMatch(t, []string{"ACCRUAL", "WITHDRAWAL", "WITHDRAWAL", "TRANSFER_OUT"}, kinds)
+		assert.Equal(t, 300.0, w.closing)
+		assert.Equal(t, 300.0, w.entries[len(w.entries)-1].Balance)
+	})
+}
+
+func TestBusiness_AdjustBalance(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newBusiness(t)
+	userID, err := b.CreateUser(ctx, "alice", "hash", "")
+	require.NoError(t, err)
+
+	for _, amount := range []float64{0, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err = b.AdjustBalance(ctx, userID, amount, "goodwill")
+		require.ErrorIs(t, err, customerrors.ErrAdjustmentInvalid, amount)
+	}
+	_, err = b.AdjustBalance(ctx, userID, 10, " ")
+	require.ErrorIs(t, err, customerrors.ErrAdjustmentInvalid)
+	_, err = b.AdjustBalance(ctx, userID, -10, "chargeback")
+	require.ErrorIs(t, err, customerrors.ErrNotEnoughFunds)
+
+	user, err := b.AdjustBalance(ctx, userID, 10, "goodwill")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, user.Balance)
+}
+
+func TestBusiness_WriteStatement__pages(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newBusiness(t)
+	userID, err := b.CreateUser(ctx, "alice", "hash", "")
+	require.NoError(t, err)
+	from := time.Now()
+	for i := 0; i < statementPageSize+1; i++ {
+		_, err = b.AdjustBalance(ctx, userID, 1, "goodwill")
+		require.NoError(t, err)
+	}
+
+	w := &recordingStatement{}
+	require.NoError(t, b.WriteStatement(ctx, userID, from, time.Now().Add(time.Minute), w))
+
+	require.Len(t, w.entries, statementPageSize+1, "the last page is read after a full one")
+	for i, e := range w.entries {
+		assert.Equal(t, float64(i+1), e.Balance)
+	}
+	assert.Equal(t, float64(statementPageSize+1), w.closing)
+}
@@ -0,0 +1,132 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/promo"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// CreateCampaign validates and stores a promotional campaign.
+func (b *Business) CreateCampaign(
+	ctx context.Context,
+	campaign domenModels.Campaign,
+) (_ domenModels.Campaign, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		if err = b.checkCampaign(ctx, s, campaign); err != nil {
+			return err
+		}
+
+		campaign.ID, err = s.CreateCampaign(ctx, dbModels.Campaign{
+			Name:         campaign.Name,
+			Kind:         campaign.Kind,
+			Value:        campaign.Value,
+			StartsAt:     campaign.StartsAt,
+			EndsAt:       campaign.EndsAt,
+			NewUsersOnly: campaign.NewUsersOnly,
+			Tiers:        campaign.Tiers,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create campaign, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return campaign, fmt.Errorf("failed to create campaign, %w", err)
+	}
+	return campaign, nil
+}
+
+func (b *Business) checkCampaign(ctx context.Context, s storage.Store, campaign domenModels.Campaign) error {
+	if campaign.Name == "" || !campaign.EndsAt.After(campaign.StartsAt) {
+		return customerrors.ErrCampaignInvalid
+	}
+	switch campaign.Kind {
+	case promo.KindMultiplier:
+		if campaign.Value <= 1 {
+			return customerrors.ErrCampaignInvalid
+		}
+	case promo.KindFixed:
+		if campaign.Value <= 0 {
+			return customerrors.ErrCampaignInvalid
+		}
+	default:
+		return customerrors.ErrCampaignInvalid
+	}
+	if len(campaign.Tiers) == 0 {
+		return nil
+	}
+
+	tiers, err := s.GetLoyaltyTiers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty tiers, %w", err)
+	}
+	known := make(map[string]struct{}, len(tiers))
+	for _, t := range tiers {
+		known[t.Name] = struct{}{}
+	}
+	for _, name := range campaign.Tiers {
+		if _, ok := known[name]; !ok {
+			return customerrors.ErrCampaignInvalid
+		}
+	}
+	return nil
+}
+
+func (b *Business) GetCampaigns(ctx context.Context) (campaigns []domenModels.Campaign, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbCampaigns, err := s.GetCampaigns(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get campaigns, %w", err)
+		}
+		for _, c := range dbCampaigns {
+			campaigns = append(campaigns, domenModels.Campaign{
+				ID:           c.ID,
+				Name:         c.Name,
+				Kind:         c.Kind,
+				Value:        c.Value,
+				StartsAt:     c.StartsAt,
+				EndsAt:       c.EndsAt,
+				NewUsersOnly: c.NewUsersOnly,
+				Tiers:        c.Tiers,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return campaigns, fmt.Errorf("failed to get campaigns, %w", err)
+	}
+	return campaigns, nil
+}
+
+// EndCampaign stops the campaign now, a campaign that has not started yet never becomes active.
+func (b *Business) EndCampaign(ctx context.Context, campaignID int64) error {
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		campaign, err := s.GetCampaign(ctx, campaignID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get campaign, %w", err)
+		}
+
+		now := time.Now()
+		if !campaign.EndsAt.After(now) {
+			return customerrors.ErrCampaignFinished
+		}
+		endsAt := now
+		if campaign.StartsAt.After(now) {
+			endsAt = campaign.StartsAt
+		}
+		if err = s.EndCampaign(ctx, campaignID, endsAt); err != nil {
+			return fmt.Errorf("failed to end campaign, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to end campaign, %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// CreateHold reserves sum on the user balance until the hold is captured, released or expired.
+func (b *Business) CreateHold(
+	ctx context.Context,
+	userID, orderID int64,
+	sum float64,
+) (hold domenModels.Hold, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		if err = b.checkWithdraw(ctx, s, user, sum); err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(b.config.HoldTTL)
+		holdID, err := s.CreateHold(ctx, userID, orderID, sum, expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create hold, %w", err)
+		}
+		if err = s.UpdateUserHeld(ctx, userID, user.Held+sum); err != nil {
+			return fmt.Errorf("failed to update user held, %w", err)
+		}
+		user.Held += sum
+		if err = b.publishBalance(ctx, s, user); err != nil {
+			return err
+		}
+
+		hold = domenModels.Hold{
+			ID:        holdID,
+			OrderID:   orderID,
+			Sum:       sum,
+			Status:    dbModels.HELD.String(),
+			ExpiresAt: expiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return hold, fmt.Errorf("failed to create hold, %w", err)
+	}
+	return hold, nil
+}
+
+// CaptureHold turns the hold into a withdrawal.
+func (b *Business) CaptureHold(ctx context.Context, userID, holdID int64) error {
+	return b.finishHold(ctx, userID, holdID, dbModels.CAPTURED)
+}
+
+// ReleaseHold returns the held points to the available balance.
+func (b *Business) ReleaseHold(ctx context.Context, userID, holdID int64) error {
+	return b.finishHold(ctx, userID, holdID, dbModels.RELEASED)
+}
+
+func (b *Business) finishHold(ctx context.Context, userID, holdID int64, status dbModels.HoldStatus) error {
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		hold, err := s.GetHold(ctx, holdID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get hold, %w", err)
+		}
+		if hold.UserID != userID {
+			return customerrors.ErrNotFound
+		}
+		if hold.Status != dbModels.HELD.String() || !hold.ExpiresAt.After(time.Now()) {
+			return customerrors.ErrHoldNotActive
+		}
+
+		user, err := s.GetUserByID(ctx, userID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+
+		if err = s.UpdateHoldStatus(ctx, hold.ID, status.String()); err != nil {
+			return fmt.Errorf("failed to update hold status, %w", err)
+		}
+		if err = s.UpdateUserHeld(ctx, user.ID, user.Held-hold.Sum); err != nil {
+			return fmt.Errorf("failed to update user held, %w", err)
+		}
+		if status == dbModels.CAPTURED {
+			err = s.UpdateUserBalance(ctx, user.ID, user.Balance-hold.Sum, user.Withdrawn+hold.Sum)
+			if err != nil {
+				return fmt.Errorf("failed to update user balance, %w", err)
+			}
+			if err = s.CreateWithdraw(ctx, user.ID, hold.OrderID, hold.Sum); err != nil {
+				return fmt.Errorf("failed to create withdraw, %w", err)
+			}
+
+			user.Held -= hold.Sum
+			user.Balance -= hold.Sum
+			user.Withdrawn += hold.Sum
+			return b.publishWithdrawal(ctx, s, user, hold.OrderID, hold.Sum)
+		}
+
+		user.Held -= hold.Sum
+		return b.publishBalance(ctx, s, user)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to finish hold, %w", err)
+	}
+	return nil
+}
@@ -3,19 +3,50 @@ package models
 import "time"
 
 type User struct {
-	ID        int64
-	Login     string
-	Password  string
-	Balance   float64
-	Withdrawn float64
-	CreatedAt time.Time
+	ID           int64
+	Login        string
+	Password     string
+	Balance      float64
+	Withdrawn    float64
+	Held         float64
+	Tier         string
+	Blocked      bool
+	ReferralCode string
+	CreatedAt    time.Time
 }
 
 type Order struct {
-	Number     int64
-	Status     string
-	Accrual    float64
-	UploadedAt time.Time
+	Number      int64
+	Status      string
+	Accrual     float64
+	UploadedAt  time.Time
+	ProcessedAt *time.Time
+	CheckedAt   time.Time
+}
+
+// OrderStatusChange is a status transition recorded by the accrual sync.
+type OrderStatusChange struct {
+	Status  string
+	Accrual float64
+	At      time.Time
+}
+
+type OrderDetail struct {
+	Order
+	History []OrderStatusChange
+}
+
+// Batch upload results of a single order.
+const (
+	OrderAccepted          = "ACCEPTED"
+	OrderAlreadyUploaded   = "ALREADY_UPLOADED"
+	OrderUploadedByAnother = "CONFLICT"
+	OrderInvalid           = "INVALID"
+)
+
+type OrderResult struct {
+	Number int64
+	Result string
 }
 
 type Withdraw struct {
@@ -23,3 +54,109 @@ type Withdraw struct {
 	Sum       float64
 	CreatedAt time.Time
 }
+
+type Tier struct {
+	Name          string
+	Multiplier    float64
+	Volume        float64
+	NextTier      string
+	NextThreshold float64
+	Progress      float64
+}
+
+type Hold struct {
+	ID        int64
+	OrderID   int64
+	Sum       float64
+	Status    string
+	ExpiresAt time.Time
+}
+
+type Referral struct {
+	Login      string
+	Status     string
+	CreatedAt  time.Time
+	RewardedAt *time.Time
+}
+
+type Referrals struct {
+	Code      string
+	Rewarded  int64
+	Referrals []Referral
+}
+
+type Campaign struct {
+	ID           int64
+	Name         string
+	Kind         string
+	Value        float64
+	StartsAt     time.Time
+	EndsAt       time.Time
+	NewUsersOnly bool
+	Tiers        []string
+}
+
+type UserEvent struct {
+	ID        int64
+	Type      string
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type Webhook struct {
+	ID        int64
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int64
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int64
+	NextAttemptAt  time.Time
+	LastStatusCode int64
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// Statement entry kinds.
+const (
+	StatementAccrual     = "ACCRUAL"
+	StatementBonus       = "BONUS"
+	StatementWithdrawal  = "WITHDRAWAL"
+	StatementTransferIn  = "TRANSFER_IN"
+	StatementTransferOut = "TRANSFER_OUT"
+	StatementAdjustment  = "ADJUSTMENT"
+)
+
+type StatementEntry struct {
+	At      time.Time
+	Kind    string
+	OrderID *int64
+	Source  string
+	Amount  float64
+	Balance float64
+}
+
+// StatementWriter receives the statement while it is read, so it is never held in memory.
+type StatementWriter interface {
+	Opening(balance float64) error
+	Entry(e StatementEntry) error
+	Closing(balance float64) error
+}
+
+// ListFilter narrows orders and withdrawals lists, nil fields are not applied.
+// Statuses apply to orders only, amount is the order accrual or the withdrawal sum.
+type ListFilter struct {
+	Statuses  []string
+	From      *time.Time
+	To        *time.Time
+	MinAmount *float64
+	MaxAmount *float64
+	Desc      bool
+}
@@ -0,0 +1,98 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// requeueStatuses can be requeued, PROCESSED orders are never, their accrual is already credited.
+var requeueStatuses = []string{
+	dbModels.NEW.String(),
+	dbModels.PROCESSING.String(),
+	dbModels.INVALID.String(),
+}
+
+// AdjustBalance credits or, with a negative amount, debits the user balance by an operator
+// and records the reason, the debit can't touch the held points.
+func (b *Business) AdjustBalance(
+	ctx context.Context,
+	userID int64,
+	amount float64,
+	reason string,
+) (u domenModels.User, err error) {
+	reason = strings.TrimSpace(reason)
+	if amount == 0 || math.IsNaN(amount) || math.IsInf(amount, 0) || reason == "" {
+		return u, customerrors.ErrAdjustmentInvalid
+	}
+
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user for update, %w", err)
+		}
+		if user.Balance+amount < user.Held {
+			return customerrors.ErrNotEnoughFunds
+		}
+
+		user.Balance += amount
+		if err = s.UpdateUserBalance(ctx, user.ID, user.Balance, user.Withdrawn); err != nil {
+			return fmt.Errorf("failed to update user balance, %w", err)
+		}
+		if _, err = s.CreateBalanceAdjustment(ctx, user.ID, amount, reason); err != nil {
+			return fmt.Errorf("failed to create balance adjustment, %w", err)
+		}
+		if err = b.publishBalance(ctx, s, user); err != nil {
+			return err
+		}
+		u = domenModels.User(user)
+		return nil
+	})
+	if err != nil {
+		return u, fmt.Errorf("failed to adjust balance, %w", err)
+	}
+	return u, nil
+}
+
+// RequeueOrders resets the orders in status not checked for olderThan to NEW for the accrual sync.
+func (b *Business) RequeueOrders(
+	ctx context.Context,
+	status string,
+	olderThan time.Duration,
+) (orders []domenModels.Order, err error) {
+	if status == dbModels.PROCESSED.String() {
+		return orders, customerrors.ErrOrderProcessed
+	}
+	if !slices.Contains(requeueStatuses, status) {
+		return orders, fmt.Errorf("status must be one of %s", strings.Join(requeueStatuses, ", "))
+	}
+
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbOrders, err := s.RequeueOrders(ctx, []string{status}, time.Now().Add(-olderThan))
+		if err != nil {
+			return fmt.Errorf("failed to requeue orders, %w", err)
+		}
+		for _, dbOrder := range dbOrders {
+			if dbOrder.Status != status {
+				err = s.CreateOrderStatus(ctx, dbOrder.ID, dbOrder.Status, 0)
+				if err != nil {
+					return fmt.Errorf("failed to record order status, %w", err)
+				}
+			}
+			orders = append(orders, orderFromDB(dbOrder))
+		}
+		return nil
+	})
+	if err != nil {
+		return orders, fmt.Errorf("failed to requeue orders, %w", err)
+	}
+	return orders, nil
+}
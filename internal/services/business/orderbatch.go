@@ -0,0 +1,68 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// CreateOrders uploads the orders in one transaction, orderIDs must be already checked with Luhn.
+// Results follow the orderIDs order, a repeated number is reported as already uploaded.
+func (b *Business) CreateOrders(
+	ctx context.Context,
+	userID int64,
+	orderIDs []int64,
+) (results []domenModels.OrderResult, err error) {
+	var (
+		created []int64
+		owners  map[int64]int64
+	)
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		created, err = s.CreateOrders(ctx, userID, orderIDs)
+		if err != nil {
+			return fmt.Errorf("failed to create orders, %w", err)
+		}
+		for _, orderID := range created {
+			err = b.publisher.Publish(ctx, s, events.OrderUploaded, events.OrderUploadedData{
+				UserID: userID,
+				Number: strconv.FormatInt(orderID, 10),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to publish order uploaded, %w", err)
+			}
+		}
+
+		owners, err = s.GetOrderOwners(ctx, orderIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get order owners, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return results, fmt.Errorf("failed to create orders, %w", err)
+	}
+
+	accepted := make(map[int64]bool, len(created))
+	for _, orderID := range created {
+		accepted[orderID] = true
+	}
+	results = make([]domenModels.OrderResult, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		result := domenModels.OrderResult{Number: orderID}
+		switch {
+		case accepted[orderID]:
+			result.Result = domenModels.OrderAccepted
+			accepted[orderID] = false
+		case owners[orderID] == userID:
+			result.Result = domenModels.OrderAlreadyUploaded
+		default:
+			result.Result = domenModels.OrderUploadedByAnother
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
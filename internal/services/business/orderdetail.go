@@ -0,0 +1,41 @@
+package business
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// GetOrder returns the user order with its status history, orders of other users are not found.
+func (b *Business) GetOrder(ctx context.Context, userID, orderID int64) (order domenModels.OrderDetail, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbOrder, err := s.GetOrder(ctx, orderID, false)
+		if err != nil {
+			return fmt.Errorf("failed to get order, %w", err)
+		}
+		if dbOrder.UserID != userID {
+			return customerrors.ErrNotFound
+		}
+		dbHistory, err := s.GetOrderStatusHistory(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to get order status history, %w", err)
+		}
+
+		order.Order = orderFromDB(dbOrder)
+		for _, h := range dbHistory {
+			order.History = append(order.History, domenModels.OrderStatusChange{
+				Status:  h.Status,
+				Accrual: h.Accrual,
+				At:      h.CreatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return order, fmt.Errorf("failed to get order, %w", err)
+	}
+	return order, nil
+}
@@ -0,0 +1,53 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// publishBalance queues balance.changed with the already updated user balance.
+func (b *Business) publishBalance(ctx context.Context, s storage.Store, user dbModels.User) error {
+	err := webhooks.Enqueue(ctx, s, user.ID, webhooks.EventBalanceChanged, webhooks.Balance{
+		Current:   user.Balance,
+		Withdrawn: user.Withdrawn,
+		Held:      user.Held,
+		Available: user.Balance - user.Held,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish balance, %w", err)
+	}
+	return nil
+}
+
+// publishWithdrawal publishes WithdrawalCreated and queues withdrawal.created and balance.changed
+// with the already updated user balance.
+func (b *Business) publishWithdrawal(
+	ctx context.Context,
+	s storage.Store,
+	user dbModels.User,
+	orderID int64,
+	sum float64,
+) error {
+	err := b.publisher.Publish(ctx, s, events.WithdrawalCreated, events.WithdrawalCreatedData{
+		UserID: user.ID,
+		Order:  strconv.FormatInt(orderID, 10),
+		Sum:    sum,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish withdrawal created, %w", err)
+	}
+	err = webhooks.Enqueue(ctx, s, user.ID, webhooks.EventWithdrawalCreated, webhooks.Withdrawal{
+		Order: strconv.FormatInt(orderID, 10),
+		Sum:   sum,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish withdrawal, %w", err)
+	}
+	return b.publishBalance(ctx, s, user)
+}
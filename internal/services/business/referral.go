@@ -0,0 +1,95 @@
+package business
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const (
+	referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	referralCodeLen      = 8
+)
+
+func newReferralCode() (string, error) {
+	code := make([]byte, referralCodeLen)
+	alphabetLen := big.NewInt(int64(len(referralCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code, %w", err)
+		}
+		code[i] = referralCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (b *Business) getReferrer(ctx context.Context, s storage.Store, code string) (referrer dbModels.User, err error) {
+	referrer, err = s.GetUserByReferralCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNotFound) {
+			return referrer, customerrors.ErrReferralInvalid
+		}
+		return referrer, fmt.Errorf("failed to get referrer, %w", err)
+	}
+	if referrer.Blocked {
+		return referrer, customerrors.ErrReferralInvalid
+	}
+
+	if b.config.ReferralMaxPerReferrer > 0 {
+		// the referrer lock makes concurrent registrations with the code count one after another
+		referrer, err = s.GetUserByID(ctx, referrer.ID, true) // for_update
+		if err != nil {
+			return referrer, fmt.Errorf("failed to lock referrer, %w", err)
+		}
+		var count int64
+		count, err = s.CountReferrals(ctx, referrer.ID)
+		if err != nil {
+			return referrer, fmt.Errorf("failed to count referrals, %w", err)
+		}
+		if count >= b.config.ReferralMaxPerReferrer {
+			return referrer, customerrors.ErrReferralLimit
+		}
+	}
+	return referrer, nil
+}
+
+func (b *Business) GetReferrals(ctx context.Context, userID int64) (referrals domenModels.Referrals, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbUser, err := s.GetUserByID(ctx, userID, false)
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		dbReferrals, err := s.GetReferrals(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get referrals, %w", err)
+		}
+
+		referrals.Code = dbUser.ReferralCode
+		for _, dbReferral := range dbReferrals {
+			referral := domenModels.Referral{
+				Login:     dbReferral.RefereeLogin,
+				Status:    dbReferral.Status,
+				CreatedAt: dbReferral.CreatedAt,
+			}
+			if dbReferral.RewardedAt.Valid {
+				rewardedAt := dbReferral.RewardedAt.Time
+				referral.RewardedAt = &rewardedAt
+				referrals.Rewarded++
+			}
+			referrals.Referrals = append(referrals.Referrals, referral)
+		}
+		return nil
+	})
+	if err != nil {
+		return referrals, fmt.Errorf("failed to get referrals, %w", err)
+	}
+	return referrals, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,15 +12,42 @@ import (
 	"github.com/NStegura/gophermart/internal/customerrors"
 	dbModels "github.com/NStegura/gophermart/internal/repo/models"
 	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/loyalty"
+	"github.com/NStegura/gophermart/internal/services/withdrawrules"
+	"github.com/NStegura/gophermart/internal/storage"
 )
 
+const day = 24 * time.Hour
+
+type Config struct {
+	TierWindow time.Duration
+	HoldTTL    time.Duration
+
+	TransferDailyLimit float64
+	TransferDailyCount int64
+
+	WithdrawLimits withdrawrules.Limits
+
+	ReferralMaxPerReferrer int64
+}
+
 type Business struct {
-	repo   Repository
-	logger *logrus.Logger
+	repo          Repository
+	publisher     events.Publisher
+	config        Config
+	withdrawRules *withdrawrules.Engine
+	logger        *logrus.Logger
 }
 
-func New(repo Repository, logger *logrus.Logger) *Business {
-	return &Business{repo: repo, logger: logger}
+func New(repo Repository, publisher events.Publisher, config Config, logger *logrus.Logger) *Business {
+	return &Business{
+		repo:          repo,
+		publisher:     publisher,
+		config:        config,
+		withdrawRules: withdrawrules.New(config.WithdrawLimits),
+		logger:        logger,
+	}
 }
 
 func (b *Business) Ping(ctx context.Context) error {
@@ -30,173 +58,304 @@ func (b *Business) Ping(ctx context.Context) error {
 	return nil
 }
 
-func (b *Business) CreateUser(ctx context.Context, login, password string) (id int64, err error) {
-	tx, err := b.repo.OpenTransaction(ctx)
-	if err != nil {
-		return id, fmt.Errorf("failed to open transaction, %w", err)
-	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
+func (b *Business) CreateUser(ctx context.Context, login, password, referralCode string) (id int64, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		_, err = s.GetUserByLogin(ctx, login)
+		if err == nil {
+			return customerrors.ErrAlreadyExists
+		}
+		if !errors.Is(err, customerrors.ErrNotFound) {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
 
-	_, err = b.repo.GetUserByLogin(ctx, tx, login)
-	if err != nil {
-		if errors.Is(err, customerrors.ErrNotFound) {
-			id, err = b.repo.CreateUser(ctx, tx, login, password)
+		var referrer dbModels.User
+		if referralCode != "" {
+			referrer, err = b.getReferrer(ctx, s, referralCode)
 			if err != nil {
-				return id, fmt.Errorf("failed to create user, %w", err)
+				return err
 			}
-			return
 		}
-		return id, fmt.Errorf("failed to get user, %w", err)
-	}
-	return id, customerrors.ErrAlreadyExists
-}
 
-func (b *Business) GetUserByLogin(ctx context.Context, login string) (u domenModels.User, err error) {
-	tx, err := b.repo.OpenTransaction(ctx)
+		code, err := newReferralCode()
+		if err != nil {
+			return err
+		}
+		id, err = s.CreateUser(ctx, login, password, code)
+		if err != nil {
+			return fmt.Errorf("failed to create user, %w", err)
+		}
+
+		if referralCode != "" {
+			if err = s.CreateReferral(ctx, referrer.ID, id); err != nil {
+				return fmt.Errorf("failed to create referral, %w", err)
+			}
+		}
+		err = b.publisher.Publish(ctx, s, events.UserRegistered, events.UserRegisteredData{
+			UserID: id,
+			Login:  login,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish user registered, %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return u, fmt.Errorf("failed to open transaction, %w", err)
+		return id, fmt.Errorf("failed to create user, %w", err)
 	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
+	return id, nil
+}
 
-	dbUser, err := b.repo.GetUserByLogin(ctx, tx, login)
+func (b *Business) GetUserByLogin(ctx context.Context, login string) (u domenModels.User, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbUser, err := s.GetUserByLogin(ctx, login)
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		u = domenModels.User(dbUser)
+		return nil
+	})
 	if err != nil {
 		return u, fmt.Errorf("failed to get user, %w", err)
 	}
-	return domenModels.User(dbUser), nil
+	return u, nil
 }
 
 func (b *Business) GetUserByID(ctx context.Context, id int64) (u domenModels.User, err error) {
-	tx, err := b.repo.OpenTransaction(ctx)
-	if err != nil {
-		return u, fmt.Errorf("failed to open transaction, %w", err)
-	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
-
-	dbUser, err := b.repo.GetUserByID(ctx, tx, id, false)
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbUser, err := s.GetUserByID(ctx, id, false)
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		u = domenModels.User(dbUser)
+		return nil
+	})
 	if err != nil {
 		return u, fmt.Errorf("failed to get user, %w", err)
 	}
-	return domenModels.User(dbUser), nil
+	return u, nil
 }
 
-func (b *Business) CreateOrder(ctx context.Context, userID, orderID int64) error {
-	var dbOrder dbModels.Order
-
-	tx, err := b.repo.OpenTransaction(ctx)
+// GetUserVersion returns the version of the user balance and orders, it changes with any of them.
+func (b *Business) GetUserVersion(ctx context.Context, userID int64) (version int64, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		version, err = s.GetUserVersion(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user version, %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open transaction, %w", err)
+		return version, fmt.Errorf("failed to get user version, %w", err)
 	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
+	return version, nil
+}
 
-	dbOrder, err = b.repo.GetOrder(ctx, tx, orderID, false)
-	if err != nil {
-		if errors.Is(err, customerrors.ErrNotFound) {
-			err = b.repo.CreateOrder(ctx, tx, userID, orderID)
-			if err != nil {
-				return fmt.Errorf("failed to create user, %w", err)
+func (b *Business) CreateOrder(ctx context.Context, userID, orderID int64) error {
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbOrder, err := s.GetOrder(ctx, orderID, false)
+		if err != nil {
+			if errors.Is(err, customerrors.ErrNotFound) {
+				err = s.CreateOrder(ctx, userID, orderID)
+				if err != nil {
+					return fmt.Errorf("failed to create user, %w", err)
+				}
+				err = b.publisher.Publish(ctx, s, events.OrderUploaded, events.OrderUploadedData{
+					UserID: userID,
+					Number: strconv.FormatInt(orderID, 10),
+				})
+				if err != nil {
+					return fmt.Errorf("failed to publish order uploaded, %w", err)
+				}
+				return nil
 			}
-			return nil
+			return fmt.Errorf("failed to get user, %w", err)
 		}
-		return fmt.Errorf("failed to get user, %w", err)
-	}
-	if dbOrder.UserID != userID {
-		return customerrors.ErrAnotherUserUploaded
+		if dbOrder.UserID != userID {
+			return customerrors.ErrAnotherUserUploaded
+		}
+		return customerrors.ErrCurrUserUploaded
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create order, %w", err)
 	}
-	return customerrors.ErrCurrUserUploaded
+	return nil
 }
 
-func (b *Business) GetOrders(ctx context.Context, userID int64) (orders []domenModels.Order, err error) {
-	tx, err := b.repo.OpenTransaction(ctx)
+func (b *Business) GetOrders(
+	ctx context.Context,
+	userID int64,
+	filter domenModels.ListFilter,
+) (orders []domenModels.Order, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbOrders, err := s.GetOrders(ctx, userID, dbModels.ListFilter(filter))
+		if err != nil {
+			return fmt.Errorf("failed to get orders, %w", err)
+		}
+		for _, dbOrder := range dbOrders {
+			orders = append(orders, orderFromDB(dbOrder))
+		}
+		return nil
+	})
 	if err != nil {
-		return orders, fmt.Errorf("failed to open transaction, %w", err)
+		return orders, fmt.Errorf("failed to get orders, %w", err)
 	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
+	return orders, nil
+}
 
-	dbOrders, err := b.repo.GetOrders(ctx, tx, userID)
-	if err != nil {
-		return orders, fmt.Errorf("failed to get orders, %w", err)
+// orderFromDB converts the order row, updated_at is bumped by every accrual check.
+func orderFromDB(dbOrder dbModels.Order) domenModels.Order {
+	order := domenModels.Order{
+		Number:     dbOrder.ID,
+		Status:     dbOrder.Status,
+		Accrual:    dbOrder.Accrual.Float64,
+		UploadedAt: dbOrder.UploadedAt,
+		CheckedAt:  dbOrder.UpdatedAt,
 	}
-	for _, dbOrder := range dbOrders {
-		var convertedUpdatedAt time.Time
-		convertedUpdatedAt, err = time.Parse(time.RFC3339, dbOrder.UpdatedAt.Format(time.RFC3339))
-		if err != nil {
-			return orders, fmt.Errorf("failed to convert UpdatedAt to RFC3339")
-		}
-		orders = append(orders, domenModels.Order{
-			Number:     dbOrder.ID,
-			Status:     dbOrder.Status,
-			Accrual:    dbOrder.Accrual.Float64,
-			UploadedAt: convertedUpdatedAt,
-		})
+	if dbOrder.ProcessedAt.Valid {
+		processedAt := dbOrder.ProcessedAt.Time
+		order.ProcessedAt = &processedAt
 	}
-	return orders, nil
+	return order
 }
 
 func (b *Business) CreateWithdraw(ctx context.Context, userID int64, orderID int64, sum float64) error {
-	tx, err := b.repo.OpenTransaction(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to open transaction, %w", err)
-	}
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, true)
+		if err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		if err = b.checkWithdraw(ctx, s, user, sum); err != nil {
+			return err
+		}
 
-	user, err := b.repo.GetUserByID(ctx, tx, userID, true)
-	if err != nil {
-		_ = b.repo.Rollback(ctx, tx)
-		return fmt.Errorf("failed to get user, %w", err)
-	}
-	if user.Balance < sum {
-		_ = b.repo.Rollback(ctx, tx)
-		return customerrors.ErrNotEnoughFunds
-	}
+		err = s.UpdateUserBalance(ctx, user.ID, user.Balance-sum, user.Withdrawn+sum)
+		if err != nil {
+			return fmt.Errorf("failed to create withdraw, %w", err)
+		}
 
-	err = b.repo.UpdateUserBalance(ctx, tx, user.ID, user.Balance-sum, user.Withdrawn+sum)
-	if err != nil {
-		_ = b.repo.Rollback(ctx, tx)
-		return fmt.Errorf("failed to create withdraw, %w", err)
-	}
+		err = s.CreateWithdraw(ctx, userID, orderID, sum)
+		if err != nil {
+			return fmt.Errorf("failed to create withdraw, %w", err)
+		}
 
-	err = b.repo.CreateWithdraw(ctx, tx, userID, orderID, sum)
+		user.Balance -= sum
+		user.Withdrawn += sum
+		if err = b.publishWithdrawal(ctx, s, user, orderID, sum); err != nil {
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		_ = b.repo.Rollback(ctx, tx)
 		return fmt.Errorf("failed to create withdraw, %w", err)
 	}
-	_ = b.repo.Commit(ctx, tx)
 	return nil
 }
 
-func (b *Business) GetWithdrawals(ctx context.Context, userID int64) (withdrawals []domenModels.Withdraw, err error) {
-	tx, err := b.repo.OpenTransaction(ctx)
+// checkWithdraw validates a withdrawal of sum against the user locked in the unit of work s.
+func (b *Business) checkWithdraw(ctx context.Context, s storage.Store, user dbModels.User, sum float64) error {
+	if user.Blocked {
+		return customerrors.ErrUserBlocked
+	}
+
+	now := time.Now()
+	stat, err := s.GetWithdrawStat(ctx, user.ID,
+		now.Add(-time.Hour),
+		now.UTC().Truncate(day),
+		time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC),
+	)
 	if err != nil {
-		return withdrawals, fmt.Errorf("failed to open transaction, %w", err)
+		return fmt.Errorf("failed to get withdraw stat, %w", err)
+	}
+	if err = b.withdrawRules.Check(withdrawrules.Request{
+		Sum:          sum,
+		Now:          now,
+		RegisteredAt: user.CreatedAt,
+		Stat:         withdrawrules.Stat(stat),
+	}); err != nil {
+		return fmt.Errorf("withdraw rejected, %w", err)
 	}
-	defer func() {
-		_ = b.repo.Commit(ctx, tx)
-	}()
 
-	dbWithdrawals, err := b.repo.GetWithdrawals(ctx, tx, userID)
+	if user.Balance-user.Held < sum {
+		return customerrors.ErrNotEnoughFunds
+	}
+	return nil
+}
+
+func (b *Business) GetWithdrawals(
+	ctx context.Context,
+	userID int64,
+	filter domenModels.ListFilter,
+) (withdrawals []domenModels.Withdraw, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbWithdrawals, err := s.GetWithdrawals(ctx, userID, dbModels.ListFilter(filter))
+		if err != nil {
+			return fmt.Errorf("failed to get withdrawals, %w", err)
+		}
+		for _, dbWithdraw := range dbWithdrawals {
+			withdrawals = append(withdrawals, domenModels.Withdraw{
+				OrderID:   dbWithdraw.OrderID,
+				Sum:       dbWithdraw.Sum,
+				CreatedAt: dbWithdraw.CreatedAt,
+			})
+		}
+		return nil
+	})
 	if err != nil {
 		return withdrawals, fmt.Errorf("failed to get withdrawals, %w", err)
 	}
-	for _, dbWithdraw := range dbWithdrawals {
-		var convertedCreatedAt time.Time
-		convertedCreatedAt, err = time.Parse(time.RFC3339, dbWithdraw.CreatedAt.Format(time.RFC3339))
+	return withdrawals, nil
+}
+
+func (b *Business) GetUserTier(ctx context.Context, userID int64) (t domenModels.Tier, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbUser, err := s.GetUserByID(ctx, userID, false)
 		if err != nil {
-			return withdrawals, fmt.Errorf("failed to convert UpdatedAt to RFC3339")
+			return fmt.Errorf("failed to get user, %w", err)
 		}
-		withdrawals = append(withdrawals, domenModels.Withdraw{
-			OrderID:   dbWithdraw.OrderID,
-			Sum:       dbWithdraw.Sum,
-			CreatedAt: convertedCreatedAt,
-		})
+		dbTiers, err := s.GetLoyaltyTiers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get loyalty tiers, %w", err)
+		}
+		volume, err := s.GetUserAccrualVolume(ctx, userID, time.Now().Add(-b.config.TierWindow))
+		if err != nil {
+			return fmt.Errorf("failed to get accrual volume, %w", err)
+		}
+
+		tiers := make([]loyalty.Tier, 0, len(dbTiers))
+		for _, dbTier := range dbTiers {
+			tiers = append(tiers, loyalty.Tier(dbTier))
+		}
+
+		// the stored tier is recalculated nightly, progress is based on the live volume
+		var (
+			current = loyalty.Tier{Name: dbUser.Tier, Multiplier: 1}
+			next    loyalty.Tier
+			hasNext bool
+		)
+		for i, tier := range tiers {
+			if tier.Name == dbUser.Tier {
+				current = tier
+				if hasNext = i+1 < len(tiers); hasNext {
+					next = tiers[i+1]
+				}
+			}
+		}
+
+		t = domenModels.Tier{
+			Name:       current.Name,
+			Multiplier: current.Multiplier,
+			Volume:     volume,
+			Progress:   1,
+		}
+		if hasNext {
+			t.NextTier = next.Name
+			t.NextThreshold = next.Threshold
+			t.Progress = loyalty.Progress(current, next, volume)
+		}
+		return nil
+	})
+	if err != nil {
+		return t, fmt.Errorf("failed to get user tier, %w", err)
 	}
-	return withdrawals, nil
+	return t, nil
 }
@@ -0,0 +1,75 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const (
+	statementOpening  = "OPENING"
+	statementPageSize = 500
+	centsInPoint      = 100
+)
+
+// WriteStatement streams the balance changes in [from, to) with a running balance.
+// The entries are read in pages of statementPageSize, each in its own unit of work,
+// so a slow client holds a unit of work for one page only.
+func (b *Business) WriteStatement(
+	ctx context.Context,
+	userID int64,
+	from, to time.Time,
+	w domenModels.StatementWriter,
+) error {
+	var (
+		balance float64
+		after   dbModels.StatementCursor
+	)
+	for {
+		var n int64
+		err := b.repo.WithTx(ctx, func(s storage.Store) error {
+			n = 0
+			err := s.GetStatement(ctx, userID, from, to, after, statementPageSize, func(e dbModels.StatementEntry) error {
+				if e.Kind == statementOpening {
+					balance = roundCents(e.Amount)
+					return w.Opening(balance)
+				}
+				n++
+				after = dbModels.StatementCursor{At: e.At, Kind: e.Kind, ID: e.ID}
+				balance = roundCents(balance + e.Amount)
+				return w.Entry(domenModels.StatementEntry{
+					At:      e.At,
+					Kind:    e.Kind,
+					OrderID: e.OrderID,
+					Source:  e.Source,
+					Amount:  e.Amount,
+					Balance: balance,
+				})
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get statement, %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write statement page, %w", err)
+		}
+		if n < statementPageSize {
+			break
+		}
+	}
+
+	if err := w.Closing(balance); err != nil {
+		return fmt.Errorf("failed to write closing balance, %w", err)
+	}
+	return nil
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*centsInPoint) / centsInPoint
+}
@@ -0,0 +1,89 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// CreateTransfer moves sum from the sender to the recipient, the sum must be positive and finite.
+func (b *Business) CreateTransfer(ctx context.Context, senderID int64, recipientLogin string, sum float64) error {
+	if math.IsNaN(sum) || math.IsInf(sum, 0) || sum <= 0 {
+		return customerrors.ErrInvalidSum
+	}
+
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		recipient, err := s.GetUserByLogin(ctx, recipientLogin)
+		if err != nil {
+			if errors.Is(err, customerrors.ErrNotFound) {
+				return customerrors.ErrNotFound
+			}
+			return fmt.Errorf("failed to get recipient, %w", err)
+		}
+		if recipient.ID == senderID {
+			return customerrors.ErrSelfTransfer
+		}
+
+		// lock users in id order, so opposite transfers can't deadlock
+		firstID, secondID := senderID, recipient.ID
+		if firstID > secondID {
+			firstID, secondID = secondID, firstID
+		}
+		locked := make(map[int64]dbModels.User, 2)
+		for _, id := range []int64{firstID, secondID} {
+			var user dbModels.User
+			user, err = s.GetUserByID(ctx, id, true) // for_update
+			if err != nil {
+				return fmt.Errorf("failed to get user for update, %w", err)
+			}
+			locked[id] = user
+		}
+		sender, recipient := locked[senderID], locked[recipient.ID]
+
+		if sender.Blocked || recipient.Blocked {
+			return customerrors.ErrUserBlocked
+		}
+
+		count, sent, err := s.GetSentTransfersStat(ctx, senderID, time.Now().UTC().Truncate(day))
+		if err != nil {
+			return fmt.Errorf("failed to get sent transfers, %w", err)
+		}
+		if (b.config.TransferDailyLimit > 0 && sent+sum > b.config.TransferDailyLimit) ||
+			(b.config.TransferDailyCount > 0 && count+1 > b.config.TransferDailyCount) {
+			return customerrors.ErrTransferLimit
+		}
+
+		if sender.Balance-sender.Held < sum {
+			return customerrors.ErrNotEnoughFunds
+		}
+
+		if err = s.UpdateUserBalance(ctx, sender.ID, sender.Balance-sum, sender.Withdrawn); err != nil {
+			return fmt.Errorf("failed to update sender balance, %w", err)
+		}
+		if err = s.UpdateUserBalance(ctx, recipient.ID, recipient.Balance+sum, recipient.Withdrawn); err != nil {
+			return fmt.Errorf("failed to update recipient balance, %w", err)
+		}
+		if err = s.CreateTransfer(ctx, sender.ID, recipient.ID, sum); err != nil {
+			return fmt.Errorf("failed to create transfer, %w", err)
+		}
+
+		sender.Balance -= sum
+		recipient.Balance += sum
+		for _, u := range []dbModels.User{sender, recipient} {
+			if err = b.publishBalance(ctx, s, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create transfer, %w", err)
+	}
+	return nil
+}
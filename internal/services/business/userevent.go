@@ -0,0 +1,52 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// GetUserEvents returns up to limit user events with id greater than afterID created since the given time.
+func (b *Business) GetUserEvents(
+	ctx context.Context,
+	userID, afterID int64,
+	since time.Time,
+	limit int64,
+) (events []domenModels.UserEvent, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbEvents, err := s.GetUserEvents(ctx, userID, afterID, since, limit)
+		if err != nil {
+			return fmt.Errorf("failed to get user events, %w", err)
+		}
+		for _, e := range dbEvents {
+			events = append(events, domenModels.UserEvent{
+				ID:        e.ID,
+				Type:      e.Type,
+				Payload:   e.Payload,
+				CreatedAt: e.CreatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return events, fmt.Errorf("failed to get user events, %w", err)
+	}
+	return events, nil
+}
+
+func (b *Business) GetLastUserEventID(ctx context.Context, userID int64) (id int64, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		id, err = s.GetLastUserEventID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get last user event id, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return id, fmt.Errorf("failed to get last user event id, %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,206 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	dbModels "github.com/NStegura/gophermart/internal/repo/models"
+	domenModels "github.com/NStegura/gophermart/internal/services/business/models"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const (
+	maxWebhooksPerUser  = 10
+	webhookDeliveryPage = 100
+)
+
+// CreateWebhook registers the url for the events, the returned secret signs every delivery.
+func (b *Business) CreateWebhook(
+	ctx context.Context,
+	userID int64,
+	rawURL string,
+	events []string,
+) (webhook domenModels.Webhook, err error) {
+	events, err = checkWebhook(ctx, rawURL, events)
+	if err != nil {
+		return webhook, err
+	}
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return webhook, fmt.Errorf("failed to create webhook, %w", err)
+	}
+
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		// lock the user, so concurrent requests can't exceed the limit
+		if _, err = s.GetUserByID(ctx, userID, true); err != nil {
+			return fmt.Errorf("failed to get user, %w", err)
+		}
+		existing, err := s.GetWebhooks(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks, %w", err)
+		}
+		if len(existing) >= maxWebhooksPerUser {
+			return customerrors.ErrWebhookLimit
+		}
+
+		dbWebhook, err := s.CreateWebhook(ctx, userID, rawURL, secret, events)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook, %w", err)
+		}
+		webhook = domenModels.Webhook{
+			ID:        dbWebhook.ID,
+			URL:       dbWebhook.URL,
+			Secret:    dbWebhook.Secret,
+			Events:    dbWebhook.Events,
+			CreatedAt: dbWebhook.CreatedAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return webhook, fmt.Errorf("failed to create webhook, %w", err)
+	}
+	return webhook, nil
+}
+
+// checkWebhook validates the url and returns the events without duplicates.
+// The host must resolve to public addresses only, the delivery job checks them again on every dial.
+func checkWebhook(ctx context.Context, rawURL string, events []string) ([]string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, customerrors.ErrWebhookInvalid
+	}
+	if err = webhooks.CheckHost(ctx, u.Hostname()); err != nil {
+		return nil, customerrors.ErrWebhookInvalid
+	}
+	if len(events) == 0 {
+		return nil, customerrors.ErrWebhookInvalid
+	}
+
+	seen := make(map[string]struct{}, len(events))
+	unique := make([]string, 0, len(events))
+	for _, event := range events {
+		if !webhooks.Valid(event) {
+			return nil, customerrors.ErrWebhookInvalid
+		}
+		if _, ok := seen[event]; ok {
+			continue
+		}
+		seen[event] = struct{}{}
+		unique = append(unique, event)
+	}
+	return unique, nil
+}
+
+func (b *Business) GetWebhooks(ctx context.Context, userID int64) (webhooks []domenModels.Webhook, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		dbWebhooks, err := s.GetWebhooks(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks, %w", err)
+		}
+		for _, w := range dbWebhooks {
+			webhooks = append(webhooks, domenModels.Webhook{
+				ID:        w.ID,
+				URL:       w.URL,
+				Events:    w.Events,
+				CreatedAt: w.CreatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return webhooks, fmt.Errorf("failed to get webhooks, %w", err)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook disables the webhook, its queued deliveries are dropped by the delivery job.
+func (b *Business) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		if _, err := b.getUserWebhook(ctx, s, userID, webhookID); err != nil {
+			return err
+		}
+		if err := s.DisableWebhook(ctx, webhookID); err != nil {
+			return fmt.Errorf("failed to disable webhook, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook, %w", err)
+	}
+	return nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries of the webhook.
+func (b *Business) GetWebhookDeliveries(
+	ctx context.Context,
+	userID, webhookID int64,
+) (deliveries []domenModels.WebhookDelivery, err error) {
+	err = b.repo.WithTx(ctx, func(s storage.Store) error {
+		if _, err = b.getUserWebhook(ctx, s, userID, webhookID); err != nil {
+			return err
+		}
+		dbDeliveries, err := s.GetWebhookDeliveries(ctx, webhookID, webhookDeliveryPage)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook deliveries, %w", err)
+		}
+		for _, d := range dbDeliveries {
+			delivery := domenModels.WebhookDelivery{
+				ID:             d.ID,
+				Event:          d.Event,
+				Payload:        d.Payload,
+				Status:         d.Status,
+				Attempts:       d.Attempts,
+				NextAttemptAt:  d.NextAttemptAt,
+				LastStatusCode: d.LastStatusCode,
+				LastError:      d.LastError,
+				CreatedAt:      d.CreatedAt,
+			}
+			if d.DeliveredAt.Valid {
+				deliveredAt := d.DeliveredAt.Time
+				delivery.DeliveredAt = &deliveredAt
+			}
+			deliveries = append(deliveries, delivery)
+		}
+		return nil
+	})
+	if err != nil {
+		return deliveries, fmt.Errorf("failed to get webhook deliveries, %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues the delivery again with a fresh attempts budget.
+func (b *Business) RedeliverWebhook(ctx context.Context, userID, webhookID, deliveryID int64) error {
+	err := b.repo.WithTx(ctx, func(s storage.Store) error {
+		if _, err := b.getUserWebhook(ctx, s, userID, webhookID); err != nil {
+			return err
+		}
+		if err := s.RequeueWebhookDelivery(ctx, webhookID, deliveryID); err != nil {
+			return fmt.Errorf("failed to requeue webhook delivery, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to redeliver webhook, %w", err)
+	}
+	return nil
+}
+
+// getUserWebhook hides webhooks of other users and deleted ones behind ErrNotFound.
+func (b *Business) getUserWebhook(
+	ctx context.Context,
+	s storage.Store,
+	userID, webhookID int64,
+) (webhook dbModels.Webhook, err error) {
+	webhook, err = s.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return webhook, fmt.Errorf("failed to get webhook, %w", err)
+	}
+	if webhook.UserID != userID || !webhook.Active {
+		return webhook, customerrors.ErrNotFound
+	}
+	return webhook, nil
+}
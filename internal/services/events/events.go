@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	OrderUploaded     = "OrderUploaded"
+	OrderProcessed    = "OrderProcessed"
+	WithdrawalCreated = "WithdrawalCreated"
+	UserRegistered    = "UserRegistered"
+)
+
+// Event is what sinks receive, Data holds one of the payloads below.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type UserRegisteredData struct {
+	UserID int64  `json:"user_id"`
+	Login  string `json:"login"`
+}
+
+type OrderUploadedData struct {
+	UserID int64  `json:"user_id"`
+	Number string `json:"number"`
+}
+
+type OrderProcessedData struct {
+	UserID  int64   `json:"user_id"`
+	Number  string  `json:"number"`
+	Accrual float64 `json:"accrual"`
+}
+
+type WithdrawalCreatedData struct {
+	UserID int64   `json:"user_id"`
+	Order  string  `json:"order"`
+	Sum    float64 `json:"sum"`
+}
+
+// Publisher records an event as part of the caller's transaction,
+// it is delivered to the sinks only if the transaction commits.
+type Publisher interface {
+	Publish(ctx context.Context, s Store, eventType string, data any) error
+}
+
+// Store is the part of the unit of work the events are written with.
+type Store interface {
+	CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) (err error)
+}
+
+// Outbox is the Publisher writing to the outbox_event table.
+type Outbox struct{}
+
+func NewOutbox() *Outbox {
+	return &Outbox{}
+}
+
+func (o *Outbox) Publish(ctx context.Context, s Store, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s, %w", eventType, err)
+	}
+	if err = s.CreateOutboxEvent(ctx, eventType, payload); err != nil {
+		return fmt.Errorf("failed to publish %s, %w", eventType, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	filePerm = 0o644
+	maxDrain = 64 << 10
+)
+
+// Sink receives dispatched events. A failed Send is retried with the same batch,
+// so sinks must tolerate duplicates.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, events []Event) error
+}
+
+type LogSink struct {
+	logger *logrus.Logger
+}
+
+func NewLogSink(logger *logrus.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Send(_ context.Context, events []Event) error {
+	for _, e := range events {
+		s.logger.WithFields(logrus.Fields{
+			"event_id":   e.ID,
+			"event_type": e.Type,
+		}).Info(string(e.Data))
+	}
+	return nil
+}
+
+// FileSink appends events to a file as newline delimited JSON.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) Send(_ context.Context, events []Event) (err error) {
+	body, err := ndjson(events)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open %s, %w", s.path, err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close %s, %w", s.path, cerr)
+		}
+	}()
+
+	if _, err = f.Write(body); err != nil {
+		return fmt.Errorf("failed to write %s, %w", s.path, err)
+	}
+	if err = f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s, %w", s.path, err)
+	}
+	return nil
+}
+
+// HTTPSink posts every batch as newline delimited JSON, any status outside 2xx is an error.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPSink) Send(ctx context.Context, events []Event) error {
+	body, err := ndjson(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request, %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send events, %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func ndjson(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return nil, fmt.Errorf("failed to encode event %v, %w", e.ID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testEvents = []Event{
+	{ID: 1, Type: UserRegistered, Data: json.RawMessage(`{"user_id":1,"login":"gopher"}`)},
+	{ID: 2, Type: OrderUploaded, Data: json.RawMessage(`{"user_id":1,"number":"1234567897"}`)},
+}
+
+func TestFileSink_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink := NewFileSink(path)
+
+	require.NoError(t, sink.Send(context.Background(), testEvents[:1]))
+	require.NoError(t, sink.Send(context.Background(), testEvents[1:]))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+
+	var got []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		got = append(got, e)
+	}
+	require.Equal(t, testEvents, got)
+}
+
+func TestHTTPSink_Send(t *testing.T) {
+	var body string
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(b)
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	sink := NewHTTPSink(ts.URL, time.Second)
+
+	require.NoError(t, sink.Send(context.Background(), testEvents))
+	require.Len(t, strings.Split(strings.TrimSpace(body), "\n"), len(testEvents))
+
+	status = http.StatusServiceUnavailable
+	require.Error(t, sink.Send(context.Background(), testEvents))
+}
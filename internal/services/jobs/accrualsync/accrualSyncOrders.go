@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,7 +14,12 @@ import (
 
 	"github.com/NStegura/gophermart/internal/clients/accrual"
 	accrualModels "github.com/NStegura/gophermart/internal/clients/accrual/models"
+	"github.com/NStegura/gophermart/internal/customerrors"
 	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/services/loyalty"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
 )
 
 var (
@@ -19,10 +27,16 @@ var (
 )
 
 type Job struct {
+	mu        sync.Mutex
 	frequency time.Duration
 	rateLimit int
+	// rescheduled wakes Start up to apply the new frequency.
+	rescheduled chan struct{}
+
+	referralBonus ReferralBonus
 
 	repo       Repository
+	publisher  events.Publisher
 	accrualCli AccrualCli
 	logger     *logrus.Logger
 }
@@ -30,24 +44,51 @@ type Job struct {
 func New(
 	frequency time.Duration,
 	rateLimit int,
+	referralBonus ReferralBonus,
 	repo Repository,
+	publisher events.Publisher,
 	accrualCli *accrual.Client,
 	logger *logrus.Logger) *Job {
 	return &Job{
-		frequency:  frequency,
-		rateLimit:  rateLimit,
-		repo:       repo,
-		accrualCli: accrualCli,
-		logger:     logger,
+		frequency:     frequency,
+		rateLimit:     rateLimit,
+		rescheduled:   make(chan struct{}, 1),
+		referralBonus: referralBonus,
+		repo:          repo,
+		publisher:     publisher,
+		accrualCli:    accrualCli,
+		logger:        logger,
+	}
+}
+
+// SetSchedule changes the sync frequency and the number of concurrent accrual requests of a running job.
+func (j *Job) SetSchedule(frequency time.Duration, rateLimit int) {
+	j.mu.Lock()
+	j.frequency, j.rateLimit = frequency, rateLimit
+	j.mu.Unlock()
+
+	select {
+	case j.rescheduled <- struct{}{}:
+	default:
 	}
 }
 
+func (j *Job) schedule() (frequency time.Duration, rateLimit int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.frequency, j.rateLimit
+}
+
 func (j *Job) Start(ctx context.Context) error {
-	timer := time.NewTicker(j.frequency)
+	frequency, _ := j.schedule()
+	timer := time.NewTicker(frequency)
 	defer timer.Stop()
 	i := 0
 	for {
 		select {
+		case <-j.rescheduled:
+			frequency, _ = j.schedule()
+			timer.Reset(frequency)
 		case <-timer.C:
 			i++
 			j.logger.Infof("[JOB|%v] Sync order info", i)
@@ -58,8 +99,9 @@ func (j *Job) Start(ctx context.Context) error {
 			}
 			responceCh := make(chan accrualModels.OrderAccrual, len(ordersToSyncCh))
 
+			_, rateLimit := j.schedule()
 			var wg sync.WaitGroup
-			for w := 1; w <= j.rateLimit; w++ {
+			for w := 1; w <= rateLimit; w++ {
 				wg.Add(1)
 				j.getAccrualOrdersResp(ctx, &wg, ordersToSyncCh, responceCh)
 			}
@@ -82,21 +124,49 @@ func (j *Job) Start(ctx context.Context) error {
 		}
 	}
 }
-func (j *Job) getOrdersToSync(ctx context.Context) (chan models.Order, error) {
-	tx, err := j.repo.OpenTransaction(ctx)
+
+// SyncOrder checks the order in the accrual system now, out of the schedule.
+// Processed orders are final and are not checked again.
+func (j *Job) SyncOrder(ctx context.Context, orderID int64) (accrualOrder accrualModels.OrderAccrual, err error) {
+	var order models.Order
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		order, err = s.GetOrder(ctx, orderID, false)
+		if err != nil {
+			return fmt.Errorf("failed to get order, %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open transaction, %w", err)
+		return accrualOrder, fmt.Errorf("failed to get order, %w", err)
+	}
+	if order.Status == models.PROCESSED.String() {
+		return accrualOrder, customerrors.ErrOrderProcessed
 	}
-	defer func() {
-		_ = j.repo.Commit(ctx, tx)
-	}()
 
-	ordersToSync, err := j.repo.GetNotProcessedOrders(ctx, tx)
-	if len(ordersToSync) == 0 {
-		return nil, ErrNotFoundOrders
+	accrualOrder, err = j.accrualCli.GetOrder(ctx, orderID)
+	if err != nil {
+		return accrualOrder, fmt.Errorf("failed to get order accrual, %w", err)
 	}
+	if err = j.updateOrder(ctx, accrualOrder); err != nil {
+		return accrualOrder, err
+	}
+	return accrualOrder, nil
+}
+
+func (j *Job) getOrdersToSync(ctx context.Context) (chan models.Order, error) {
+	var ordersToSync []models.Order
+	err := j.repo.WithTx(ctx, func(s storage.Store) (err error) {
+		ordersToSync, err = s.GetNotProcessedOrders(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get not porocessed orders from db: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get not porocessed orders from db: %w", err)
+		return nil, fmt.Errorf("failed to get orders to sync, %w", err)
+	}
+	if len(ordersToSync) == 0 {
+		return nil, ErrNotFoundOrders
 	}
 	ordersToSyncCh := make(chan models.Order, len(ordersToSync))
 
@@ -131,38 +201,200 @@ func (j *Job) getAccrualOrdersResp(
 }
 
 func (j *Job) updateOrder(ctx context.Context, accrualOrder accrualModels.OrderAccrual) error {
-	tx, err := j.repo.OpenTransaction(ctx)
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		order, err := s.GetOrder(ctx, accrualOrder.OrderID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get order for update, %w", err)
+		}
+		// a forced sync can race the scheduled one, the accrual must not be credited twice
+		if order.Status == models.PROCESSED.String() {
+			return customerrors.ErrOrderProcessed
+		}
+
+		var (
+			referral    models.Referral
+			hasReferral bool
+		)
+		if accrualOrder.Status == accrualModels.PROCESSED.String() {
+			referral, hasReferral, err = j.getPendingReferral(ctx, s, order.UserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		userIDs := []int64{order.UserID}
+		if hasReferral {
+			userIDs = append(userIDs, referral.ReferrerID)
+		}
+		locked, err := j.lockUsers(ctx, s, userIDs...)
+		if err != nil {
+			return err
+		}
+		users := maps.Clone(locked)
+		user := users[order.UserID]
+
+		if accrualOrder.Status == accrualModels.REGISTERED.String() {
+			accrualOrder.Status = models.NEW.String()
+		}
+		if err = s.UpdateOrder(ctx, order.ID, accrualOrder.Accrual, accrualOrder.Status); err != nil {
+			return fmt.Errorf("failed to update order, %w", err)
+		}
+		if order.Status != accrualOrder.Status {
+			err = s.CreateOrderStatus(ctx, order.ID, accrualOrder.Status, accrualOrder.Accrual)
+			if err != nil {
+				return fmt.Errorf("failed to record order status, %w", err)
+			}
+		}
+		if err = j.publishOrder(ctx, s, order, accrualOrder); err != nil {
+			return err
+		}
+		if accrualOrder.Accrual != 0 {
+			var tier models.LoyaltyTier
+			tier, err = s.GetLoyaltyTier(ctx, user.Tier)
+			if err != nil {
+				return fmt.Errorf("failed to get user tier, %w", err)
+			}
+			if err = j.credit(ctx, s, users, user.ID, order.ID, "", accrualOrder.Accrual); err != nil {
+				return err
+			}
+			bonus := loyalty.Bonus(accrualOrder.Accrual, tier.Multiplier)
+			if err = j.credit(ctx, s, users, user.ID, order.ID, loyalty.SourcePrefix+tier.Name, bonus); err != nil {
+				return err
+			}
+		}
+		// every processed order takes part in the campaigns, the campaign kind decides what a zero accrual earns
+		if accrualOrder.Status == models.PROCESSED.String() {
+			if err = j.applyCampaigns(ctx, s, users, order, accrualOrder.Accrual); err != nil {
+				return err
+			}
+		}
+		if hasReferral {
+			if err = j.rewardReferral(ctx, s, users, referral, order.ID); err != nil {
+				return err
+			}
+		}
+		if err = j.saveBalances(ctx, s, locked, users); err != nil {
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open transaction, %w", err)
+		return fmt.Errorf("failed to update order, %w", err)
 	}
+	return nil
+}
 
-	order, err := j.repo.GetOrder(ctx, tx, accrualOrder.OrderID, true) // for_update
-	if err != nil {
-		_ = j.repo.Rollback(ctx, tx)
-		return fmt.Errorf("failed to get order for update, %w", err)
+// publishOrder queues order.processed or order.invalid when the order reaches the final status
+// and publishes OrderProcessed.
+func (j *Job) publishOrder(
+	ctx context.Context,
+	s storage.Store,
+	order models.Order,
+	accrualOrder accrualModels.OrderAccrual,
+) error {
+	if order.Status == accrualOrder.Status {
+		return nil
 	}
 
-	user, err := j.repo.GetUserByID(ctx, tx, order.UserID, true) // for_update
+	var event string
+	switch accrualOrder.Status {
+	case models.PROCESSED.String():
+		event = webhooks.EventOrderProcessed
+	case models.INVALID.String():
+		event = webhooks.EventOrderInvalid
+	default:
+		return nil
+	}
+	err := webhooks.Enqueue(ctx, s, order.UserID, event, webhooks.Order{
+		Number:  strconv.FormatInt(order.ID, 10),
+		Status:  accrualOrder.Status,
+		Accrual: accrualOrder.Accrual,
+	})
 	if err != nil {
-		_ = j.repo.Rollback(ctx, tx)
-		return fmt.Errorf("failed to get order for update, %w", err)
+		return fmt.Errorf("failed to publish order, %w", err)
 	}
-	if accrualOrder.Status == accrualModels.REGISTERED.String() {
-		accrualOrder.Status = models.NEW.String()
+	if event != webhooks.EventOrderProcessed {
+		return nil
 	}
-	if err = j.repo.UpdateOrder(ctx, tx, order.ID, accrualOrder.Accrual, accrualOrder.Status); err != nil {
-		_ = j.repo.Rollback(ctx, tx)
-		return fmt.Errorf("failed to update order, %w", err)
+	err = j.publisher.Publish(ctx, s, events.OrderProcessed, events.OrderProcessedData{
+		UserID:  order.UserID,
+		Number:  strconv.FormatInt(order.ID, 10),
+		Accrual: accrualOrder.Accrual,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish order processed, %w", err)
 	}
-	if accrualOrder.Accrual != 0 {
-		if err = j.repo.UpdateUserBalance(ctx, tx, user.ID, user.Balance+accrualOrder.Accrual, user.Withdrawn); err != nil {
-			_ = j.repo.Rollback(ctx, tx)
-			return fmt.Errorf("failed to update user balance, %w", err)
+	return nil
+}
+
+// lockUsers selects users for update in id order, so concurrent jobs can't deadlock.
+func (j *Job) lockUsers(ctx context.Context, s storage.Store, ids ...int64) (map[int64]models.User, error) {
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	users := make(map[int64]models.User, len(ids))
+	for _, id := range ids {
+		if _, ok := users[id]; ok {
+			continue
+		}
+		user, err := s.GetUserByID(ctx, id, true) // for_update
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user for update, %w", err)
 		}
+		users[id] = user
 	}
+	return users, nil
+}
 
-	if err = j.repo.Commit(ctx, tx); err != nil {
-		return fmt.Errorf("failef to commit, %w", err)
+// credit adds amount to the locked user balance, non-empty source records it as a bonus.
+// Balances are written once by saveBalances.
+func (j *Job) credit(
+	ctx context.Context,
+	s storage.Store,
+	users map[int64]models.User,
+	userID, orderID int64,
+	source string,
+	amount float64,
+) error {
+	if amount == 0 {
+		return nil
+	}
+	if source != "" {
+		if err := s.CreateBonus(ctx, userID, orderID, source, amount); err != nil {
+			return fmt.Errorf("failed to create %s bonus, %w", source, err)
+		}
+	}
+	user := users[userID]
+	user.Balance += amount
+	users[userID] = user
+	return nil
+}
+
+// saveBalances updates the users whose balance differs from the locked one, in id order,
+// and queues balance.changed for them.
+func (j *Job) saveBalances(ctx context.Context, s storage.Store, locked, users map[int64]models.User) error {
+	ids := make([]int64, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+
+	for _, id := range ids {
+		user := users[id]
+		if user.Balance == locked[id].Balance {
+			continue
+		}
+		if err := s.UpdateUserBalance(ctx, id, user.Balance, user.Withdrawn); err != nil {
+			return fmt.Errorf("failed to update user balance, %w", err)
+		}
+		err := webhooks.Enqueue(ctx, s, id, webhooks.EventBalanceChanged, webhooks.Balance{
+			Current:   user.Balance,
+			Withdrawn: user.Withdrawn,
+			Held:      user.Held,
+			Available: user.Balance - user.Held,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish balance, %w", err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,43 @@
+package accrualsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/promo"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// applyCampaigns credits the bonuses of campaigns active when the order was uploaded.
+func (j *Job) applyCampaigns(
+	ctx context.Context,
+	s storage.Store,
+	users map[int64]models.User,
+	order models.Order,
+	accrual float64,
+) error {
+	campaigns, err := s.GetActiveCampaigns(ctx, order.UploadedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get active campaigns, %w", err)
+	}
+
+	user := users[order.UserID]
+	for _, c := range campaigns {
+		campaign := promo.Campaign{
+			ID:           c.ID,
+			Kind:         c.Kind,
+			Value:        c.Value,
+			StartsAt:     c.StartsAt,
+			NewUsersOnly: c.NewUsersOnly,
+			Tiers:        c.Tiers,
+		}
+		if !campaign.Eligible(user.CreatedAt, user.Tier) {
+			continue
+		}
+		if err = j.credit(ctx, s, users, user.ID, order.ID, campaign.Source(), campaign.Bonus(accrual)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
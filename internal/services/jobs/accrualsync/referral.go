@@ -0,0 +1,57 @@
+package accrualsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const referralSource = "REFERRAL"
+
+type ReferralBonus struct {
+	Referrer float64
+	Referee  float64
+}
+
+// getPendingReferral locks the not yet rewarded referral of the referee, if there is one.
+func (j *Job) getPendingReferral(ctx context.Context, s storage.Store, refereeID int64) (models.Referral, bool, error) {
+	referral, err := s.GetReferralByReferee(ctx, refereeID, true) // for_update
+	if err != nil {
+		if errors.Is(err, customerrors.ErrNotFound) {
+			return referral, false, nil
+		}
+		return referral, false, fmt.Errorf("failed to get referral for update, %w", err)
+	}
+	if referral.Status != models.PENDING.String() || referral.ReferrerID == referral.RefereeID {
+		return referral, false, nil
+	}
+	return referral, true, nil
+}
+
+// rewardReferral credits both parties once the referee's first order is processed.
+func (j *Job) rewardReferral(
+	ctx context.Context,
+	s storage.Store,
+	users map[int64]models.User,
+	referral models.Referral,
+	orderID int64,
+) error {
+	if err := j.credit(ctx, s, users, referral.RefereeID, orderID, referralSource, j.referralBonus.Referee); err != nil {
+		return err
+	}
+	if !users[referral.ReferrerID].Blocked {
+		err := j.credit(ctx, s, users, referral.ReferrerID, orderID, referralSource, j.referralBonus.Referrer)
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.RewardReferral(ctx, referral.ID); err != nil {
+		return fmt.Errorf("failed to reward referral, %w", err)
+	}
+	j.logger.Debugf("Referral %v rewarded", referral.ID)
+	return nil
+}
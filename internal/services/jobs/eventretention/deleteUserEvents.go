@@ -0,0 +1,64 @@
+package eventretention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// Job deletes the user events older than the retention, the event stream can't resume from them anyway.
+type Job struct {
+	frequency time.Duration
+	retention time.Duration
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+func New(
+	frequency time.Duration,
+	retention time.Duration,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		frequency: frequency,
+		retention: retention,
+		repo:      repo,
+		logger:    logger,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	timer := time.NewTicker(j.frequency)
+	defer timer.Stop()
+	i := 0
+	for {
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Debugf("[JOB|%v] Delete user events", i)
+			if err := j.cleanup(ctx); err != nil {
+				j.logger.Errorf("failed to clean up user events: %s", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (j *Job) cleanup(ctx context.Context) error {
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		if err := s.DeleteUserEvents(ctx, time.Now().Add(-j.retention)); err != nil {
+			return fmt.Errorf("failed to delete user events, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user events, %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package holdexpiry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const batchSize = 100
+
+type Job struct {
+	frequency time.Duration
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+func New(
+	frequency time.Duration,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		frequency: frequency,
+		repo:      repo,
+		logger:    logger,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	timer := time.NewTicker(j.frequency)
+	defer timer.Stop()
+	i := 0
+	for {
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Debugf("[JOB|%v] Expire holds", i)
+			holds, err := j.getExpiredHolds(ctx)
+			if err != nil {
+				j.logger.Errorf("failed to get expired holds: %s", err)
+				continue
+			}
+			for _, hold := range holds {
+				if err = j.expireHold(ctx, hold.ID); err != nil {
+					j.logger.Error(err)
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (j *Job) getExpiredHolds(ctx context.Context) (holds []models.Hold, err error) {
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		holds, err = s.GetExpiredHolds(ctx, time.Now(), batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get expired holds from db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return holds, fmt.Errorf("failed to get expired holds, %w", err)
+	}
+	return holds, nil
+}
+
+func (j *Job) expireHold(ctx context.Context, holdID int64) error {
+	var expired bool
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		hold, err := s.GetHold(ctx, holdID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get hold for update, %w", err)
+		}
+		if hold.Status != models.HELD.String() {
+			return nil
+		}
+
+		user, err := s.GetUserByID(ctx, hold.UserID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user for update, %w", err)
+		}
+		if err = s.UpdateHoldStatus(ctx, hold.ID, models.EXPIRED.String()); err != nil {
+			return fmt.Errorf("failed to update hold status, %w", err)
+		}
+		if err = s.UpdateUserHeld(ctx, user.ID, user.Held-hold.Sum); err != nil {
+			return fmt.Errorf("failed to update user held, %w", err)
+		}
+		err = webhooks.Enqueue(ctx, s, user.ID, webhooks.EventBalanceChanged, webhooks.Balance{
+			Current:   user.Balance,
+			Withdrawn: user.Withdrawn,
+			Held:      user.Held - hold.Sum,
+			Available: user.Balance - user.Held + hold.Sum,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish balance, %w", err)
+		}
+		expired = true
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to expire hold, %w", err)
+	}
+	if expired {
+		j.logger.Debugf("Hold %v expired", holdID)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package holdexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+	"github.com/NStegura/gophermart/internal/storage/memory"
+)
+
+func TestJob_expireHolds(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New(logrus.New())
+	job := New(time.Hour, repo, logrus.New())
+
+	var userID, expiredID, activeID int64
+	err := repo.WithTx(ctx, func(s storage.Store) (err error) {
+		if userID, err = s.CreateUser(ctx, "alice", "hash", "CODE"); err != nil {
+			return err
+		}
+		if err = s.UpdateUserBalance(ctx, userID, 100, 0); err != nil {
+			return err
+		}
+		if expiredID, err = s.CreateHold(ctx, userID, 12345678903, 30, time.Now().Add(-time.Minute)); err != nil {
+			return err
+		}
+		if activeID, err = s.CreateHold(ctx, userID, 79927398713, 20, time.Now().Add(time.Hour)); err != nil {
+			return err
+		}
+		return s.UpdateUserHeld(ctx, userID, 50)
+	})
+	require.NoError(t, err)
+
+	holds, err := job.getExpiredHolds(ctx)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, expiredID, holds[0].ID)
+	require.NoError(t, job.expireHold(ctx, expiredID))
+	require.NoError(t, job.expireHold(ctx, expiredID), "an expired hold is released once")
+
+	err = repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, false)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, user.Balance)
+		assert.Equal(t, 20.0, user.Held)
+
+		expired, err := s.GetHold(ctx, expiredID, false)
+		require.NoError(t, err)
+		assert.Equal(t, models.EXPIRED.String(), expired.Status)
+		active, err := s.GetHold(ctx, activeID, false)
+		require.NoError(t, err)
+		assert.Equal(t, models.HELD.String(), active.Status)
+		return nil
+	})
+	require.NoError(t, err)
+}
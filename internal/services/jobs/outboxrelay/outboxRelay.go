@@ -0,0 +1,134 @@
+package outboxrelay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const batchSize = 100
+
+type Job struct {
+	frequency time.Duration
+	retention time.Duration
+	lease     time.Duration
+	sinks     []events.Sink
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+// New takes the sink timeout to lease a batch long enough for every sink to send it.
+func New(
+	frequency time.Duration,
+	retention time.Duration,
+	sinkTimeout time.Duration,
+	sinks []events.Sink,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		frequency: frequency,
+		retention: retention,
+		lease:     sinkTimeout * time.Duration(len(sinks)+1),
+		sinks:     sinks,
+		repo:      repo,
+		logger:    logger,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	timer := time.NewTicker(j.frequency)
+	defer timer.Stop()
+	i := 0
+	for {
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Debugf("[JOB|%v] Relay outbox events", i)
+			for {
+				n, err := j.relay(ctx)
+				if err != nil {
+					j.logger.Errorf("failed to relay outbox events: %s", err)
+					break
+				}
+				if n < batchSize {
+					break
+				}
+			}
+			if err := j.cleanup(ctx); err != nil {
+				j.logger.Errorf("failed to clean up outbox events: %s", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// relay leases one batch, sends it to every sink outside the transaction and then marks it dispatched.
+// A failed sink gets the whole batch again once the lease is over.
+func (j *Job) relay(ctx context.Context) (n int, err error) {
+	var pending []models.OutboxEvent
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		now := time.Now()
+		pending, err = s.ClaimPendingOutboxEvents(ctx, now, now.Add(j.lease), batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox events, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events, %w", err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	batch := make([]events.Event, 0, len(pending))
+	ids := make([]int64, 0, len(pending))
+	for _, e := range pending {
+		batch = append(batch, events.Event{
+			ID:        e.ID,
+			Type:      e.Type,
+			CreatedAt: e.CreatedAt,
+			Data:      e.Payload,
+		})
+		ids = append(ids, e.ID)
+	}
+
+	for _, sink := range j.sinks {
+		if err = sink.Send(ctx, batch); err != nil {
+			return 0, fmt.Errorf("%s sink failed, %w", sink.Name(), err)
+		}
+	}
+
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		if err := s.MarkOutboxEventsDispatched(ctx, ids, time.Now()); err != nil {
+			return fmt.Errorf("failed to mark outbox events dispatched, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events dispatched, %w", err)
+	}
+	j.logger.Debugf("Relayed %v outbox events", len(batch))
+	return len(batch), nil
+}
+
+func (j *Job) cleanup(ctx context.Context) error {
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		if err := s.DeleteDispatchedOutboxEvents(ctx, time.Now().Add(-j.retention)); err != nil {
+			return fmt.Errorf("failed to delete dispatched outbox events, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clean up outbox events, %w", err)
+	}
+	return nil
+}
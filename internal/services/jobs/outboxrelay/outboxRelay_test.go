@@ -0,0 +1,76 @@
+package outboxrelay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/services/events"
+	"github.com/NStegura/gophermart/internal/storage"
+	"github.com/NStegura/gophermart/internal/storage/memory"
+)
+
+// recordingSink opens a unit of work on every send, the batch must be sent outside the relay one.
+type recordingSink struct {
+	repo Repository
+	err  error
+	sent []events.Event
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Send(ctx context.Context, batch []events.Event) error {
+	err := s.repo.WithTx(ctx, func(storage.Store) error {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, batch...)
+	return nil
+}
+
+func TestJob_relay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	repo := memory.New(logrus.New())
+	sink := &recordingSink{repo: repo, err: errors.New("unavailable")}
+	job := New(time.Hour, time.Hour, 0, []events.Sink{sink}, repo, logrus.New())
+
+	err := repo.WithTx(ctx, func(s storage.Store) error {
+		for _, number := range []string{"12345678903", "79927398713"} {
+			err := events.NewOutbox().Publish(ctx, s, events.OrderUploaded, events.OrderUploadedData{UserID: 1, Number: number})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = job.relay(ctx)
+	require.Error(t, err)
+	assert.Empty(t, sink.sent)
+
+	sink.err = nil
+	n, err := job.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "the failed batch is sent again once the lease is over")
+	require.Len(t, sink.sent, 2)
+	assert.Equal(t, events.OrderUploaded, sink.sent[0].Type)
+	assert.JSONEq(t, `{"user_id": 1, "number": "12345678903"}`, string(sink.sent[0].Data))
+
+	n, err = job.relay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "dispatched events are not sent again")
+}
@@ -0,0 +1,11 @@
+package reconciliation
+
+import (
+	"context"
+
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+type Repository interface {
+	WithTx(ctx context.Context, fn func(s storage.Store) error) error
+}
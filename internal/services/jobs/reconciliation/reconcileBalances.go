@@ -0,0 +1,243 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// Modes of the reconciliation: report only finds the mismatches, dry-run also proposes
+// the corrections and apply makes them.
+const (
+	ModeReport = "report"
+	ModeDryRun = "dry-run"
+	ModeApply  = "apply"
+)
+
+const centsInPoint = 100
+
+var Modes = []string{ModeReport, ModeDryRun, ModeApply}
+
+// errBelowHeld skips a correction that would leave the held points uncovered,
+// the holds have to be released or the ledger fixed by an operator first.
+var errBelowHeld = errors.New("ledger balance is below the held points, correction is not applied")
+
+// Report is the reconciliation result, it is logged and printed as JSON.
+type Report struct {
+	Mode        string       `json:"mode"`
+	CheckedAt   time.Time    `json:"checked_at"`
+	Tolerance   float64      `json:"tolerance"`
+	Mismatches  []Mismatch   `json:"mismatches"`
+	Corrections []Correction `json:"corrections,omitempty"`
+}
+
+// Mismatch is a user whose balance or withdrawn is not the sum of the ledger,
+// the ledger is the accruals, bonuses, withdrawals, transfers and adjustments.
+type Mismatch struct {
+	UserID            int64   `json:"user_id"`
+	Login             string  `json:"login"`
+	Balance           float64 `json:"balance"`
+	ExpectedBalance   float64 `json:"expected_balance"`
+	BalanceDiff       float64 `json:"balance_diff"`
+	Withdrawn         float64 `json:"withdrawn"`
+	ExpectedWithdrawn float64 `json:"expected_withdrawn"`
+	WithdrawnDiff     float64 `json:"withdrawn_diff"`
+}
+
+// Correction sets the user balance and withdrawn to the ledger sums.
+type Correction struct {
+	UserID        int64   `json:"user_id"`
+	BalanceFrom   float64 `json:"balance_from"`
+	BalanceTo     float64 `json:"balance_to"`
+	WithdrawnFrom float64 `json:"withdrawn_from"`
+	WithdrawnTo   float64 `json:"withdrawn_to"`
+	Applied       bool    `json:"applied"`
+	Error         string  `json:"error,omitempty"`
+}
+
+type Job struct {
+	frequency time.Duration
+	mode      string
+	tolerance float64
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+// New creates a job that reconciles the balances every frequency in mode,
+// differences up to tolerance are float drift and are ignored.
+func New(
+	frequency time.Duration,
+	mode string,
+	tolerance float64,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		frequency: frequency,
+		mode:      mode,
+		tolerance: tolerance,
+		repo:      repo,
+		logger:    logger,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	timer := time.NewTicker(j.frequency)
+	defer timer.Stop()
+	i := 0
+	for {
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Infof("[JOB|%v] Reconcile balances", i)
+			report, err := j.Reconcile(ctx, j.mode)
+			if err != nil {
+				j.logger.Errorf("failed to reconcile balances: %s", err)
+				continue
+			}
+			if len(report.Mismatches) == 0 {
+				continue
+			}
+			data, err := json.Marshal(report)
+			if err != nil {
+				j.logger.Errorf("failed to marshal reconciliation report: %s", err)
+				continue
+			}
+			j.logger.Warnf("balance mismatches: %s", data)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Reconcile finds the users whose balance differs from the ledger, in dry-run and apply modes
+// it adds the corrections, apply makes them one user at a time.
+func (j *Job) Reconcile(ctx context.Context, mode string) (report Report, err error) {
+	if !slices.Contains(Modes, mode) {
+		return report, fmt.Errorf("unknown reconciliation mode %q", mode)
+	}
+	report = Report{Mode: mode, CheckedAt: time.Now().UTC(), Tolerance: j.tolerance, Mismatches: []Mismatch{}}
+
+	mismatches, err := j.getMismatches(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, m := range mismatches {
+		report.Mismatches = append(report.Mismatches, newMismatch(m))
+		if mode == ModeReport {
+			continue
+		}
+
+		correction := newCorrection(m)
+		if mode == ModeApply {
+			correction, err = j.correct(ctx, m.UserID)
+			if err != nil {
+				correction.Error = err.Error()
+				j.logger.Error(err)
+			}
+		}
+		report.Corrections = append(report.Corrections, correction)
+	}
+	return report, nil
+}
+
+func (j *Job) getMismatches(ctx context.Context) (mismatches []models.BalanceMismatch, err error) {
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		mismatches, err = s.GetBalanceMismatches(ctx, j.tolerance)
+		if err != nil {
+			return fmt.Errorf("failed to get balance mismatches, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return mismatches, fmt.Errorf("failed to get balance mismatches, %w", err)
+	}
+	return mismatches, nil
+}
+
+// correct checks the locked user again, the balance could change since the report query.
+// A balance below the held points is not written, the correction is reported with errBelowHeld.
+func (j *Job) correct(ctx context.Context, userID int64) (c Correction, err error) {
+	c.UserID = userID
+
+	var applied bool
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user for update, %w", err)
+		}
+		ledger, err := s.GetUserLedger(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user ledger, %w", err)
+		}
+		c = newCorrection(ledger)
+		if math.Abs(c.BalanceTo-c.BalanceFrom) <= j.tolerance && math.Abs(c.WithdrawnTo-c.WithdrawnFrom) <= j.tolerance {
+			return nil
+		}
+		if c.BalanceTo < user.Held {
+			return fmt.Errorf("user %v held %v, %w", userID, user.Held, errBelowHeld)
+		}
+
+		if err = s.UpdateUserBalance(ctx, userID, c.BalanceTo, c.WithdrawnTo); err != nil {
+			return fmt.Errorf("failed to update user balance, %w", err)
+		}
+		err = s.CreateBalanceCorrection(ctx, userID, c.BalanceFrom, c.BalanceTo, c.WithdrawnFrom, c.WithdrawnTo)
+		if err != nil {
+			return fmt.Errorf("failed to create balance correction, %w", err)
+		}
+		err = webhooks.Enqueue(ctx, s, userID, webhooks.EventBalanceChanged, webhooks.Balance{
+			Current:   c.BalanceTo,
+			Withdrawn: c.WithdrawnTo,
+			Held:      user.Held,
+			Available: c.BalanceTo - user.Held,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish balance, %w", err)
+		}
+		applied = true
+		return nil
+	})
+	if err != nil {
+		return c, fmt.Errorf("failed to correct user balance, %w", err)
+	}
+	c.Applied = applied
+	return c, nil
+}
+
+func newMismatch(m models.BalanceMismatch) Mismatch {
+	return Mismatch{
+		UserID:            m.UserID,
+		Login:             m.Login,
+		Balance:           m.Balance,
+		ExpectedBalance:   m.LedgerBalance,
+		BalanceDiff:       m.Balance - m.LedgerBalance,
+		Withdrawn:         m.Withdrawn,
+		ExpectedWithdrawn: m.LedgerWithdrawn,
+		WithdrawnDiff:     m.Withdrawn - m.LedgerWithdrawn,
+	}
+}
+
+// newCorrection rounds the ledger sums to cents, so the float drift of the sums is not written.
+func newCorrection(m models.BalanceMismatch) Correction {
+	return Correction{
+		UserID:        m.UserID,
+		BalanceFrom:   m.Balance,
+		BalanceTo:     roundCents(m.LedgerBalance),
+		WithdrawnFrom: m.Withdrawn,
+		WithdrawnTo:   roundCents(m.LedgerWithdrawn),
+	}
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*centsInPoint) / centsInPoint
+}
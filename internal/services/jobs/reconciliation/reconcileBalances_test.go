@@ -0,0 +1,143 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+	mock_reconciliation "github.com/NStegura/gophermart/mocks/services/jobs/reconciliation"
+	mock_storage "github.com/NStegura/gophermart/mocks/storage"
+)
+
+const tolerance = 0.005
+
+// newJob returns the job and the store of its units of work, every WithTx runs on it.
+func newJob(t *testing.T) (*Job, *mock_storage.MockStore) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	repo := mock_reconciliation.NewMockRepository(ctrl)
+	store := mock_storage.NewMockStore(ctrl)
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(s storage.Store) error) error {
+			return fn(store)
+		},
+	).AnyTimes()
+	return New(time.Hour, ModeReport, tolerance, repo, logrus.New()), store
+}
+
+func expectMismatches(store *mock_storage.MockStore, mismatches ...models.BalanceMismatch) {
+	store.EXPECT().GetBalanceMismatches(gomock.Any(), tolerance).Return(mismatches, nil)
+}
+
+var doubleCredited = models.BalanceMismatch{
+	UserID:          1,
+	Login:           "login",
+	Balance:         200,
+	Withdrawn:       10,
+	LedgerBalance:   99.99999999999999,
+	LedgerWithdrawn: 10,
+}
+
+func TestJob_Reconcile_report(t *testing.T) {
+	job, store := newJob(t)
+	expectMismatches(store, doubleCredited)
+
+	report, err := job.Reconcile(context.Background(), ModeReport)
+	require.NoError(t, err)
+	assert.Equal(t, ModeReport, report.Mode)
+	assert.Empty(t, report.Corrections)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, Mismatch{
+		UserID:            1,
+		Login:             "login",
+		Balance:           200,
+		ExpectedBalance:   99.99999999999999,
+		BalanceDiff:       200 - 99.99999999999999,
+		Withdrawn:         10,
+		ExpectedWithdrawn: 10,
+	}, report.Mismatches[0])
+}
+
+func TestJob_Reconcile_dryRun(t *testing.T) {
+	job, store := newJob(t)
+	expectMismatches(store, doubleCredited)
+
+	report, err := job.Reconcile(context.Background(), ModeDryRun)
+	require.NoError(t, err)
+	assert.Equal(t, []Correction{
+		{UserID: 1, BalanceFrom: 200, BalanceTo: 100, WithdrawnFrom: 10, WithdrawnTo: 10},
+	}, report.Corrections, "the ledger is rounded to cents, nothing is applied")
+}
+
+func TestJob_Reconcile_apply(t *testing.T) {
+	t.Run("Balance is set to the ledger", func(t *testing.T) {
+		job, store := newJob(t)
+		expectMismatches(store, doubleCredited)
+		store.EXPECT().GetUserByID(gomock.Any(), int64(1), true).Return(models.User{ID: 1, Held: 30}, nil)
+		store.EXPECT().GetUserLedger(gomock.Any(), int64(1)).Return(doubleCredited, nil)
+		store.EXPECT().UpdateUserBalance(gomock.Any(), int64(1), 100.0, 10.0).Return(nil)
+		store.EXPECT().CreateBalanceCorrection(gomock.Any(), int64(1), 200.0, 100.0, 10.0, 10.0).Return(nil)
+		store.EXPECT().CreateWebhookDeliveries(gomock.Any(), int64(1), "balance.changed", gomock.Any()).Return(nil)
+
+		report, err := job.Reconcile(context.Background(), ModeApply)
+		require.NoError(t, err)
+		assert.Equal(t, []Correction{
+			{UserID: 1, BalanceFrom: 200, BalanceTo: 100, WithdrawnFrom: 10, WithdrawnTo: 10, Applied: true},
+		}, report.Corrections)
+	})
+
+	t.Run("Balance fixed since the report is skipped", func(t *testing.T) {
+		job, store := newJob(t)
+		expectMismatches(store, doubleCredited)
+		fixed := doubleCredited
+		fixed.Balance = 100
+		store.EXPECT().GetUserByID(gomock.Any(), int64(1), true).Return(models.User{ID: 1}, nil)
+		store.EXPECT().GetUserLedger(gomock.Any(), int64(1)).Return(fixed, nil)
+
+		report, err := job.Reconcile(context.Background(), ModeApply)
+		require.NoError(t, err)
+		require.Len(t, report.Corrections, 1)
+		assert.False(t, report.Corrections[0].Applied)
+		assert.Empty(t, report.Corrections[0].Error)
+	})
+
+	t.Run("Balance below the held points is not applied", func(t *testing.T) {
+		job, store := newJob(t)
+		expectMismatches(store, doubleCredited)
+		store.EXPECT().GetUserByID(gomock.Any(), int64(1), true).Return(models.User{ID: 1, Held: 150}, nil)
+		store.EXPECT().GetUserLedger(gomock.Any(), int64(1)).Return(doubleCredited, nil)
+
+		report, err := job.Reconcile(context.Background(), ModeApply)
+		require.NoError(t, err)
+		require.Len(t, report.Corrections, 1)
+		assert.False(t, report.Corrections[0].Applied)
+		assert.Equal(t, 100.0, report.Corrections[0].BalanceTo)
+		assert.Contains(t, report.Corrections[0].Error, errBelowHeld.Error())
+	})
+
+	t.Run("Failed correction is reported", func(t *testing.T) {
+		job, store := newJob(t)
+		expectMismatches(store, doubleCredited)
+		store.EXPECT().GetUserByID(gomock.Any(), int64(1), true).Return(models.User{}, errors.New("locked"))
+
+		report, err := job.Reconcile(context.Background(), ModeApply)
+		require.NoError(t, err)
+		require.Len(t, report.Corrections, 1)
+		assert.False(t, report.Corrections[0].Applied)
+		assert.Contains(t, report.Corrections[0].Error, "locked")
+	})
+}
+
+func TestJob_Reconcile_unknownMode(t *testing.T) {
+	job, _ := newJob(t)
+	_, err := job.Reconcile(context.Background(), "fix")
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package tierrecalc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/loyalty"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const day = 24 * time.Hour
+
+type Job struct {
+	runAt  time.Duration
+	window time.Duration
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+// New creates a job that recalculates user tiers once a day at runAt past midnight UTC,
+// using the accrual volume of the last window.
+func New(
+	runAt time.Duration,
+	window time.Duration,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		runAt:  runAt,
+		window: window,
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	i := 0
+	for {
+		timer := time.NewTimer(j.untilNextRun(time.Now()))
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Infof("[JOB|%v] Recalculate loyalty tiers", i)
+			if err := j.Recalculate(ctx); err != nil {
+				j.logger.Errorf("failed to recalculate tiers: %s", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		}
+	}
+}
+
+func (j *Job) untilNextRun(now time.Time) time.Duration {
+	now = now.UTC()
+	next := now.Truncate(day).Add(j.runAt)
+	if !next.After(now) {
+		next = next.Add(day)
+	}
+	return next.Sub(now)
+}
+
+// Recalculate moves every user to the tier matching the accrual volume of the window.
+func (j *Job) Recalculate(ctx context.Context) error {
+	tiers, volumes, err := j.getVolumes(ctx)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, v := range volumes {
+		current, _, _ := loyalty.Resolve(tiers, v.Volume)
+		if current.Name == "" || current.Name == v.Tier {
+			continue
+		}
+		if err = j.updateTier(ctx, v.UserID, current.Name, v.Volume); err != nil {
+			j.logger.Error(err)
+			continue
+		}
+		changed++
+	}
+	j.logger.Infof("Loyalty tiers recalculated, changed %v of %v users", changed, len(volumes))
+	return nil
+}
+
+func (j *Job) getVolumes(ctx context.Context) (tiers []loyalty.Tier, volumes []models.UserVolume, err error) {
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		dbTiers, err := s.GetLoyaltyTiers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get loyalty tiers, %w", err)
+		}
+		tiers = make([]loyalty.Tier, 0, len(dbTiers))
+		for _, dbTier := range dbTiers {
+			tiers = append(tiers, loyalty.Tier(dbTier))
+		}
+
+		volumes, err = s.GetAccrualVolumes(ctx, time.Now().Add(-j.window))
+		if err != nil {
+			return fmt.Errorf("failed to get accrual volumes, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return tiers, volumes, fmt.Errorf("failed to get accrual volumes, %w", err)
+	}
+	return tiers, volumes, nil
+}
+
+func (j *Job) updateTier(ctx context.Context, userID int64, tier string, volume float64) error {
+	var oldTier string
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		user, err := s.GetUserByID(ctx, userID, true) // for_update
+		if err != nil {
+			return fmt.Errorf("failed to get user for update, %w", err)
+		}
+		if user.Tier == tier {
+			return nil
+		}
+		oldTier = user.Tier
+
+		if err = s.UpdateUserTier(ctx, userID, tier); err != nil {
+			return fmt.Errorf("failed to update user tier, %w", err)
+		}
+		if err = s.CreateTierHistory(ctx, userID, user.Tier, tier, volume); err != nil {
+			return fmt.Errorf("failed to create tier history, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update user tier, %w", err)
+	}
+	if oldTier != "" {
+		j.logger.Debugf("User %v moved from %s to %s tier", userID, oldTier, tier)
+	}
+	return nil
+}
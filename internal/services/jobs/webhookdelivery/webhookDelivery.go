@@ -0,0 +1,207 @@
+package webhookdelivery
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/services/webhooks"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+const (
+	batchSize = 20
+	maxDrain  = 64 << 10
+
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+// the errors saved to the delivery log, the transport errors may tell too much about the network.
+var (
+	errForbidden  = errors.New("address is not public")
+	errTimeout    = errors.New("request timed out")
+	errConnection = errors.New("connection failed")
+)
+
+type Job struct {
+	frequency      time.Duration
+	requestTimeout time.Duration
+
+	client *http.Client
+	repo   Repository
+	logger *logrus.Logger
+}
+
+func New(
+	frequency time.Duration,
+	requestTimeout time.Duration,
+	repo Repository,
+	logger *logrus.Logger) *Job {
+	return &Job{
+		frequency:      frequency,
+		requestTimeout: requestTimeout,
+		client: &http.Client{
+			Transport: newTransport(),
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// newTransport dials only public addresses and never a proxy, the proxy address would be checked instead.
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: dialKeepAlive,
+		Control:   webhooks.Control,
+	}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	timer := time.NewTicker(j.frequency)
+	defer timer.Stop()
+	i := 0
+	for {
+		select {
+		case <-timer.C:
+			i++
+			j.logger.Debugf("[JOB|%v] Deliver webhooks", i)
+			deliveries, err := j.claimDeliveries(ctx)
+			if err != nil {
+				j.logger.Errorf("failed to claim webhook deliveries: %s", err)
+				continue
+			}
+			for _, delivery := range deliveries {
+				if err = j.deliver(ctx, delivery); err != nil {
+					j.logger.Error(err)
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// claimDeliveries leases a batch long enough to send it even if every request times out.
+func (j *Job) claimDeliveries(ctx context.Context) (deliveries []models.DueDelivery, err error) {
+	err = j.repo.WithTx(ctx, func(s storage.Store) error {
+		now := time.Now()
+		leaseUntil := now.Add(time.Duration(batchSize+1) * j.requestTimeout)
+		deliveries, err = s.ClaimDueWebhookDeliveries(ctx, now, leaseUntil, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries from db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return deliveries, fmt.Errorf("failed to claim webhook deliveries, %w", err)
+	}
+	return deliveries, nil
+}
+
+func (j *Job) deliver(ctx context.Context, due models.DueDelivery) error {
+	delivery := due.WebhookDelivery
+	now := time.Now()
+
+	if !due.Active {
+		delivery.Status = models.FAILED.String()
+		delivery.LastError = "webhook is deleted"
+	} else {
+		delivery.Attempts++
+		statusCode, err := j.send(ctx, due)
+		delivery.LastStatusCode = int64(statusCode)
+		switch {
+		case err == nil:
+			delivery.Status = models.DELIVERED.String()
+			delivery.LastError = ""
+			delivery.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+		case delivery.Attempts >= webhooks.MaxAttempts:
+			delivery.Status = models.FAILED.String()
+			delivery.LastError = err.Error()
+		default:
+			delivery.LastError = err.Error()
+			delivery.NextAttemptAt = now.Add(webhooks.Backoff(delivery.Attempts))
+		}
+	}
+
+	err := j.repo.WithTx(ctx, func(s storage.Store) error {
+		if err := s.UpdateWebhookDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to update webhook delivery, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery, %w", err)
+	}
+	j.logger.Debugf("Webhook delivery %v is %s after %v attempts", delivery.ID, delivery.Status, delivery.Attempts)
+	return nil
+}
+
+// send posts the signed envelope, any status outside 2xx is an error.
+func (j *Job) send(ctx context.Context, due models.DueDelivery) (int, error) {
+	body, err := json.Marshal(webhooks.Envelope{
+		ID:        due.ID,
+		Event:     due.Event,
+		CreatedAt: due.CreatedAt,
+		Data:      due.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal envelope, %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, j.requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, due.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request, %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.EventHeader, due.Event)
+	req.Header.Set(webhooks.DeliveryHeader, strconv.FormatInt(due.ID, 10))
+	req.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(due.Secret, timestamp, body))
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		j.logger.Debugf("Webhook delivery %v failed: %s", due.ID, err)
+		switch {
+		case errors.Is(err, webhooks.ErrForbiddenAddress):
+			return 0, errForbidden
+		case errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err):
+			return 0, errTimeout
+		default:
+			return 0, errConnection
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,49 @@
+package loyalty
+
+import "math"
+
+const (
+	SourcePrefix = "TIER:"
+
+	centsFactor = 100
+)
+
+type Tier struct {
+	Name       string
+	Threshold  float64
+	Multiplier float64
+}
+
+// Resolve returns the highest tier reached by volume and the tier following it.
+// Tiers must be sorted by threshold ascending.
+func Resolve(tiers []Tier, volume float64) (current Tier, next Tier, hasNext bool) {
+	for i, t := range tiers {
+		if volume < t.Threshold {
+			if i == 0 {
+				return Tier{Multiplier: 1}, t, true
+			}
+			return tiers[i-1], t, true
+		}
+	}
+	if len(tiers) == 0 {
+		return Tier{Multiplier: 1}, Tier{}, false
+	}
+	return tiers[len(tiers)-1], Tier{}, false
+}
+
+// Progress returns the share of the way from the current tier to the next one.
+func Progress(current, next Tier, volume float64) float64 {
+	span := next.Threshold - current.Threshold
+	if span <= 0 {
+		return 1
+	}
+	return math.Min(math.Max((volume-current.Threshold)/span, 0), 1)
+}
+
+// Bonus returns the extra points the multiplier adds on top of accrual.
+func Bonus(accrual, multiplier float64) float64 {
+	if multiplier <= 1 {
+		return 0
+	}
+	return math.Round(accrual*(multiplier-1)*centsFactor) / centsFactor
+}
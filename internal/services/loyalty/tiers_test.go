@@ -0,0 +1,66 @@
+package loyalty
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	tiers := []Tier{
+		{Name: "BRONZE", Threshold: 0, Multiplier: 1},
+		{Name: "SILVER", Threshold: 1000, Multiplier: 1.05},
+		{Name: "GOLD", Threshold: 5000, Multiplier: 1.1},
+	}
+
+	tests := []struct {
+		name     string
+		volume   float64
+		current  string
+		next     string
+		hasNext  bool
+		progress float64
+	}{
+		{
+			name:     "bronze",
+			volume:   250,
+			current:  "BRONZE",
+			next:     "SILVER",
+			hasNext:  true,
+			progress: 0.25,
+		},
+		{
+			name:     "silver on threshold",
+			volume:   1000,
+			current:  "SILVER",
+			next:     "GOLD",
+			hasNext:  true,
+			progress: 0,
+		},
+		{
+			name:     "gold",
+			volume:   7000,
+			current:  "GOLD",
+			hasNext:  false,
+			progress: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current, next, hasNext := Resolve(tiers, test.volume)
+			require.Equal(t, test.current, current.Name)
+			require.Equal(t, test.next, next.Name)
+			require.Equal(t, test.hasNext, hasNext)
+			if hasNext {
+				require.InDelta(t, test.progress, Progress(current, next, test.volume), 1e-9)
+			}
+		})
+	}
+}
+
+func TestBonus(t *testing.T) {
+	require.Equal(t, float64(0), Bonus(500, 1))
+	require.Equal(t, 25.0, Bonus(500, 1.05))
+	require.Equal(t, 0.12, Bonus(1.23, 1.1))
+}
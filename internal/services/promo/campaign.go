@@ -0,0 +1,62 @@
+package promo
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	KindMultiplier = "MULTIPLIER"
+	KindFixed      = "FIXED"
+
+	SourcePrefix = "CAMPAIGN:"
+
+	centsFactor = 100
+)
+
+type Campaign struct {
+	ID           int64
+	Kind         string
+	Value        float64
+	StartsAt     time.Time
+	NewUsersOnly bool
+	Tiers        []string
+}
+
+// Eligible reports whether the user may get the campaign bonus.
+// New users are the ones registered after the campaign start.
+func (c Campaign) Eligible(registeredAt time.Time, tier string) bool {
+	if c.NewUsersOnly && registeredAt.Before(c.StartsAt) {
+		return false
+	}
+	if len(c.Tiers) == 0 {
+		return true
+	}
+	for _, t := range c.Tiers {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// Bonus returns the points the campaign adds on top of the base accrual.
+// A multiplier adds nothing to a zero accrual, a fixed bonus is paid for any processed order.
+func (c Campaign) Bonus(accrual float64) float64 {
+	switch c.Kind {
+	case KindMultiplier:
+		if c.Value <= 1 {
+			return 0
+		}
+		return math.Round(accrual*(c.Value-1)*centsFactor) / centsFactor
+	case KindFixed:
+		return c.Value
+	default:
+		return 0
+	}
+}
+
+func (c Campaign) Source() string {
+	return SourcePrefix + strconv.FormatInt(c.ID, 10)
+}
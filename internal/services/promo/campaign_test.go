@@ -0,0 +1,64 @@
+package promo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaign_Eligible(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		campaign     Campaign
+		registeredAt time.Time
+		tier         string
+		eligible     bool
+	}{
+		{
+			name:         "everyone",
+			campaign:     Campaign{StartsAt: start},
+			registeredAt: start.Add(-time.Hour),
+			tier:         "BRONZE",
+			eligible:     true,
+		},
+		{
+			name:         "new users only, old user",
+			campaign:     Campaign{StartsAt: start, NewUsersOnly: true},
+			registeredAt: start.Add(-time.Hour),
+			tier:         "BRONZE",
+			eligible:     false,
+		},
+		{
+			name:         "new users only, new user",
+			campaign:     Campaign{StartsAt: start, NewUsersOnly: true},
+			registeredAt: start.Add(time.Hour),
+			tier:         "BRONZE",
+			eligible:     true,
+		},
+		{
+			name:         "tier mismatch",
+			campaign:     Campaign{StartsAt: start, Tiers: []string{"GOLD"}},
+			registeredAt: start,
+			tier:         "SILVER",
+			eligible:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.eligible, test.campaign.Eligible(test.registeredAt, test.tier))
+		})
+	}
+}
+
+func TestCampaign_Bonus(t *testing.T) {
+	require.Equal(t, 500.0, Campaign{Kind: KindMultiplier, Value: 2}.Bonus(500))
+	require.Equal(t, 100.0, Campaign{Kind: KindFixed, Value: 100}.Bonus(500))
+	require.Equal(t, 0.0, Campaign{Kind: KindMultiplier, Value: 2}.Bonus(0))
+	require.Equal(t, 100.0, Campaign{Kind: KindFixed, Value: 100}.Bonus(0))
+	require.Equal(t, 0.0, Campaign{Kind: "UNKNOWN", Value: 100}.Bonus(500))
+	require.Equal(t, "CAMPAIGN:7", Campaign{ID: 7}.Source())
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type window struct {
+	end   time.Time
+	count int64
+}
+
+// Memory counts requests in fixed windows of this instance only.
+type Memory struct {
+	mu        sync.Mutex
+	windows   map[string]window
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		windows: make(map[string]window),
+		now:     time.Now,
+	}
+}
+
+func (m *Memory) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.end) {
+		w = window{end: now.Truncate(limit.Window).Add(limit.Window)}
+	}
+	w.count++
+	m.windows[key] = w
+
+	return newResult(limit, w.count, w.end), nil
+}
+
+// sweep drops the finished windows, so keys of gone clients don't pile up.
+func (m *Memory) sweep(now time.Time) {
+	for key, w := range m.windows {
+		if !now.Before(w.end) {
+			delete(m.windows, key)
+		}
+	}
+	m.lastSweep = now
+}
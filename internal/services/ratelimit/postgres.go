@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// Postgres counts requests in the rate_limit table shared by every instance.
+// Windows are aligned by the database clock, so instances with skewed clocks agree on them.
+type Postgres struct {
+	mu        sync.Mutex
+	lastSweep time.Time
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+func NewPostgres(repo Repository, logger *logrus.Logger) *Postgres {
+	return &Postgres{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (p *Postgres) Take(ctx context.Context, key string, limit Limit) (res Result, err error) {
+	var (
+		count int64
+		reset time.Time
+	)
+	err = p.repo.WithTx(ctx, func(s storage.Store) error {
+		count, reset, err = s.TakeRateLimit(ctx, key, limit.Window)
+		if err != nil {
+			return fmt.Errorf("failed to take rate limit, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to take rate limit token, %w", err)
+	}
+
+	p.sweep(ctx)
+	return newResult(limit, count, reset), nil
+}
+
+// sweep deletes the finished windows at most once per sweepInterval of this instance.
+func (p *Postgres) sweep(ctx context.Context) {
+	p.mu.Lock()
+	if time.Since(p.lastSweep) < sweepInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastSweep = time.Now()
+	p.mu.Unlock()
+
+	err := p.repo.WithTx(ctx, func(s storage.Store) error {
+		if err := s.DeleteExpiredRateLimits(ctx); err != nil {
+			return fmt.Errorf("failed to delete expired rate limits, %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		p.logger.Error(err)
+	}
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per fixed Window, zero value disables it.
+type Limit struct {
+	Requests int64
+	Window   time.Duration
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText makes Limit a config file, env and flag value.
+func (l *Limit) UnmarshalText(text []byte) (err error) {
+	*l, err = ParseLimit(string(text))
+	return err
+}
+
+// ParseLimit parses "<requests>/<window>" like "10/1m", empty string or "0" disables the limit.
+func ParseLimit(s string) (l Limit, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return l, nil
+	}
+
+	requests, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return l, fmt.Errorf("rate limit %q must look like 10/1m", s)
+	}
+	l.Requests, err = strconv.ParseInt(requests, 10, 64)
+	if err != nil || l.Requests < 0 {
+		return l, fmt.Errorf("rate limit %q requests must be a non-negative integer", s)
+	}
+	l.Window, err = time.ParseDuration(window)
+	if err != nil || l.Window < time.Second {
+		return l, fmt.Errorf("rate limit %q window must be a duration of at least 1s", s)
+	}
+	return l, nil
+}
+
+// Result is the key budget after a request was taken from it.
+type Result struct {
+	Limit     int64
+	Remaining int64
+	Reset     time.Time
+	Allowed   bool
+}
+
+func newResult(limit Limit, count int64, reset time.Time) Result {
+	return Result{
+		Limit:     limit.Requests,
+		Remaining: max(limit.Requests-count, 0),
+		Reset:     reset,
+		Allowed:   count <= limit.Requests,
+	}
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/storage"
+	mock_ratelimit "github.com/NStegura/gophermart/mocks/services/ratelimit"
+	mock_storage "github.com/NStegura/gophermart/mocks/storage"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "10/1m", want: Limit{Requests: 10, Window: time.Minute}},
+		{in: " 5/30s ", want: Limit{Requests: 5, Window: 30 * time.Second}},
+		{in: "10", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "ten/1m", wantErr: true},
+		{in: "10/100ms", wantErr: true},
+		{in: "10/minute", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := ParseLimit(test.in)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMemory_Take(t *testing.T) {
+	start := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	now := start.Add(10 * time.Second)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	for i, want := range []Result{
+		{Limit: 2, Remaining: 1, Reset: start.Add(time.Minute), Allowed: true},
+		{Limit: 2, Remaining: 0, Reset: start.Add(time.Minute), Allowed: true},
+		{Limit: 2, Remaining: 0, Reset: start.Add(time.Minute), Allowed: false},
+	} {
+		res, err := m.Take(context.Background(), "a", limit)
+		require.NoError(t, err)
+		require.Equal(t, want, res, i)
+	}
+
+	res, err := m.Take(context.Background(), "b", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "keys have their own budgets")
+
+	now = start.Add(time.Minute + 10*time.Second)
+	res, err = m.Take(context.Background(), "a", limit)
+	require.NoError(t, err)
+	require.Equal(t, Result{Limit: 2, Remaining: 1, Reset: start.Add(2 * time.Minute), Allowed: true}, res)
+	require.Len(t, m.windows, 1, "the finished window of b is swept")
+}
+
+func TestPostgres_Take(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_ratelimit.NewMockRepository(ctrl)
+	store := mock_storage.NewMockStore(ctrl)
+	repo.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(s storage.Store) error) error {
+			return fn(store)
+		},
+	).AnyTimes()
+	p := NewPostgres(repo, logrus.New())
+	limit := Limit{Requests: 3, Window: time.Minute}
+	reset := time.Now().Add(time.Minute)
+
+	gomock.InOrder(
+		store.EXPECT().TakeRateLimit(gomock.Any(), "key", time.Minute).Return(int64(4), reset, nil),
+		store.EXPECT().DeleteExpiredRateLimits(gomock.Any()).Return(nil),
+	)
+	res, err := p.Take(context.Background(), "key", limit)
+	require.NoError(t, err)
+	require.Equal(t, Result{Limit: 3, Remaining: 0, Reset: reset, Allowed: false}, res)
+
+	store.EXPECT().TakeRateLimit(gomock.Any(), "key", time.Minute).
+		Return(int64(0), time.Time{}, errors.New("db is down"))
+	_, err = p.Take(context.Background(), "key", limit)
+	require.Error(t, err)
+}
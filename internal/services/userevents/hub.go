@@ -0,0 +1,105 @@
+package userevents
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Channel is notified by the user_event insert trigger with the user id as payload.
+const Channel = "user_event"
+
+// Hub fans out Postgres notifications to the subscribers of the same user.
+// A notification only tells the subscriber to read new events from the user_event table,
+// so events written by any instance reach every instance.
+type Hub struct {
+	retry time.Duration
+
+	mu   sync.Mutex
+	subs map[int64]map[chan struct{}]struct{}
+
+	repo   Repository
+	logger *logrus.Logger
+}
+
+func New(retry time.Duration, repo Repository, logger *logrus.Logger) *Hub {
+	return &Hub{
+		retry:  retry,
+		subs:   make(map[int64]map[chan struct{}]struct{}),
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (h *Hub) Start(ctx context.Context) error {
+	for {
+		err := h.repo.Listen(ctx, Channel, h.notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		h.logger.Errorf("user events listener stopped, reconnecting in %s: %s", h.retry, err)
+		h.notifyAll()
+
+		select {
+		case <-time.After(h.retry):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Subscribe returns a channel signalled when the user may have new events.
+func (h *Hub) Subscribe(userID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+	}
+}
+
+func (h *Hub) notify(payload string) {
+	userID, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		h.logger.Warningf("unexpected user event payload %q", payload)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[userID] {
+		signal(ch)
+	}
+}
+
+// notifyAll wakes every subscriber, notifications may have been lost while reconnecting.
+func (h *Hub) notifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
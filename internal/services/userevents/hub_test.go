@@ -0,0 +1,32 @@
+package userevents
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_notify(t *testing.T) {
+	h := New(time.Second, nil, logrus.New())
+
+	ch1, unsubscribe1 := h.Subscribe(1)
+	ch2, unsubscribe2 := h.Subscribe(2)
+	defer unsubscribe2()
+
+	h.notify("1")
+	h.notify("1")
+	h.notify("not a user")
+
+	require.Len(t, ch1, 1)
+	require.Len(t, ch2, 0)
+
+	unsubscribe1()
+	<-ch1
+	h.notify("1")
+	require.Len(t, ch1, 0)
+
+	h.notifyAll()
+	require.Len(t, ch2, 1)
+}
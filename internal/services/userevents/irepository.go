@@ -0,0 +1,9 @@
+package userevents
+
+import (
+	"context"
+)
+
+type Repository interface {
+	Listen(ctx context.Context, channel string, notify func(payload string)) error
+}
@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for webhook hosts inside the private network,
+// the deliveries must not reach the services next to gophermart.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// reserved are the ranges not covered by the netip predicates.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// Public reports whether the address may receive deliveries.
+// Loopback, private, link-local (with the cloud metadata 169.254.169.254) and reserved addresses may not.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range reserved {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost resolves the host and fails if any of its addresses is not public.
+func CheckHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s, %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !Public(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// Control is the net.Dialer hook checking the address actually dialed,
+// so a DNS record changed after the registration can't point the delivery inside.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse dialed address, %w", err)
+	}
+	if !Public(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	EventOrderProcessed    = "order.processed"
+	EventOrderInvalid      = "order.invalid"
+	EventWithdrawalCreated = "withdrawal.created"
+	EventBalanceChanged    = "balance.changed"
+
+	SignatureHeader = "X-Gophermart-Signature"
+	TimestampHeader = "X-Gophermart-Timestamp"
+	EventHeader     = "X-Gophermart-Event"
+	DeliveryHeader  = "X-Gophermart-Delivery"
+
+	MaxAttempts = 10
+
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+	secretLen   = 32
+)
+
+var events = map[string]struct{}{
+	EventOrderProcessed:    {},
+	EventOrderInvalid:      {},
+	EventWithdrawalCreated: {},
+	EventBalanceChanged:    {},
+}
+
+type Order struct {
+	Number  string  `json:"number"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual"`
+}
+
+type Withdrawal struct {
+	Order string  `json:"order"`
+	Sum   float64 `json:"sum"`
+}
+
+type Balance struct {
+	Current   float64 `json:"current"`
+	Withdrawn float64 `json:"withdrawn"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+}
+
+// Envelope is the body posted to the webhook url.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Store is the part of the unit of work the deliveries are written with.
+type Store interface {
+	CreateWebhookDeliveries(ctx context.Context, userID int64, event string, payload []byte) (err error)
+}
+
+func Valid(event string) bool {
+	_, ok := events[event]
+	return ok
+}
+
+// Enqueue writes the event to the delivery outbox in the caller's transaction.
+func Enqueue(ctx context.Context, s Store, userID int64, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload, %w", event, err)
+	}
+	if err = s.CreateWebhookDeliveries(ctx, userID, event, payload); err != nil {
+		return fmt.Errorf("failed to enqueue %s, %w", event, err)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" prefixed with the algorithm.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed ones.
+func Backoff(attempts int64) time.Duration {
+	delay := backoffBase
+	for i := int64(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= backoffMax {
+			return backoffMax
+		}
+	}
+	return delay
+}
+
+func NewSecret() (string, error) {
+	b := make([]byte, secretLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret, %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
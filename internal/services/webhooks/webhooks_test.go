@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	sig := Sign("secret", 1700000000, []byte(`{"id":1}`))
+
+	require.Equal(t, "sha256=", sig[:7])
+	require.Len(t, sig, 7+64)
+	require.Equal(t, sig, Sign("secret", 1700000000, []byte(`{"id":1}`)))
+	require.NotEqual(t, sig, Sign("other", 1700000000, []byte(`{"id":1}`)))
+	require.NotEqual(t, sig, Sign("secret", 1700000001, []byte(`{"id":1}`)))
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int64
+		delay    time.Duration
+	}{
+		{attempts: 0, delay: 30 * time.Second},
+		{attempts: 1, delay: 30 * time.Second},
+		{attempts: 2, delay: time.Minute},
+		{attempts: 5, delay: 8 * time.Minute},
+		{attempts: 20, delay: 6 * time.Hour},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.delay, Backoff(test.attempts))
+	}
+}
+
+func TestValid(t *testing.T) {
+	require.True(t, Valid(EventOrderProcessed))
+	require.False(t, Valid("order.created"))
+}
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr   string
+		public bool
+	}{
+		{addr: "93.184.216.34", public: true},
+		{addr: "2606:2800:220:1::1", public: true},
+		{addr: "127.0.0.1", public: false},
+		{addr: "10.1.2.3", public: false},
+		{addr: "172.16.0.1", public: false},
+		{addr: "192.168.1.1", public: false},
+		{addr: "169.254.169.254", public: false},
+		{addr: "100.64.0.1", public: false},
+		{addr: "0.0.0.0", public: false},
+		{addr: "::1", public: false},
+		{addr: "fd00:ec2::254", public: false},
+		{addr: "fe80::1", public: false},
+		{addr: "::ffff:127.0.0.1", public: false},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.public, Public(netip.MustParseAddr(test.addr)), test.addr)
+	}
+}
+
+func TestControl(t *testing.T) {
+	require.NoError(t, Control("tcp4", "93.184.216.34:443", nil))
+	require.ErrorIs(t, Control("tcp4", "169.254.169.254:80", nil), ErrForbiddenAddress)
+	require.ErrorIs(t, Control("tcp6", "[::1]:8080", nil), ErrForbiddenAddress)
+}
+
+func TestCheckHost(t *testing.T) {
+	require.NoError(t, CheckHost(context.Background(), "93.184.216.34"))
+	require.ErrorIs(t, CheckHost(context.Background(), "127.0.0.1"), ErrForbiddenAddress)
+	require.ErrorIs(t, CheckHost(context.Background(), "localhost"), ErrForbiddenAddress)
+}
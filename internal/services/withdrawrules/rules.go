@@ -0,0 +1,109 @@
+package withdrawrules
+
+import (
+	"math"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+)
+
+// Limits configures the engine, zero value of a limit disables its rule.
+type Limits struct {
+	MinSum      float64
+	MaxSum      float64
+	DailyCap    float64
+	MonthlyCap  float64
+	HourlyCount int64
+	CoolingOff  time.Duration
+}
+
+// Stat is the user withdrawal activity the caps are checked against.
+type Stat struct {
+	HourCount int64
+	DaySum    float64
+	MonthSum  float64
+}
+
+type Request struct {
+	Sum          float64
+	Now          time.Time
+	RegisteredAt time.Time
+	Stat         Stat
+}
+
+type Rule func(req Request) error
+
+type Engine struct {
+	rules []Rule
+}
+
+func New(limits Limits) *Engine {
+	e := &Engine{rules: []Rule{positiveSum}}
+
+	if limits.CoolingOff > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Now.Sub(req.RegisteredAt) < limits.CoolingOff {
+				return customerrors.ErrWithdrawCoolingOff
+			}
+			return nil
+		})
+	}
+	if limits.MinSum > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Sum < limits.MinSum {
+				return customerrors.ErrWithdrawBelowMin
+			}
+			return nil
+		})
+	}
+	if limits.MaxSum > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Sum > limits.MaxSum {
+				return customerrors.ErrWithdrawAboveMax
+			}
+			return nil
+		})
+	}
+	if limits.HourlyCount > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Stat.HourCount+1 > limits.HourlyCount {
+				return customerrors.ErrWithdrawRateLimit
+			}
+			return nil
+		})
+	}
+	if limits.DailyCap > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Stat.DaySum+req.Sum > limits.DailyCap {
+				return customerrors.ErrWithdrawDailyCap
+			}
+			return nil
+		})
+	}
+	if limits.MonthlyCap > 0 {
+		e.rules = append(e.rules, func(req Request) error {
+			if req.Stat.MonthSum+req.Sum > limits.MonthlyCap {
+				return customerrors.ErrWithdrawMonthlyCap
+			}
+			return nil
+		})
+	}
+	return e
+}
+
+// Check returns the error of the first violated rule.
+func (e *Engine) Check(req Request) error {
+	for _, rule := range e.rules {
+		if err := rule(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func positiveSum(req Request) error {
+	if math.IsNaN(req.Sum) || math.IsInf(req.Sum, 0) || req.Sum <= 0 {
+		return customerrors.ErrInvalidSum
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package withdrawrules
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+)
+
+func TestEngine_Check(t *testing.T) {
+	now := time.Now()
+	engine := New(Limits{
+		MinSum:      10,
+		MaxSum:      1000,
+		DailyCap:    1500,
+		MonthlyCap:  5000,
+		HourlyCount: 3,
+		CoolingOff:  time.Hour,
+	})
+
+	tests := []struct {
+		name string
+		req  Request
+		err  error
+	}{
+		{
+			name: "ok",
+			req:  Request{Sum: 100, Now: now, RegisteredAt: now.Add(-2 * time.Hour)},
+			err:  nil,
+		},
+		{
+			name: "negative sum",
+			req:  Request{Sum: -1, Now: now, RegisteredAt: now.Add(-2 * time.Hour)},
+			err:  customerrors.ErrInvalidSum,
+		},
+		{
+			name: "NaN sum",
+			req:  Request{Sum: math.NaN(), Now: now, RegisteredAt: now.Add(-2 * time.Hour)},
+			err:  customerrors.ErrInvalidSum,
+		},
+		{
+			name: "cooling off",
+			req:  Request{Sum: 100, Now: now, RegisteredAt: now.Add(-time.Minute)},
+			err:  customerrors.ErrWithdrawCoolingOff,
+		},
+		{
+			name: "below min",
+			req:  Request{Sum: 5, Now: now, RegisteredAt: now.Add(-2 * time.Hour)},
+			err:  customerrors.ErrWithdrawBelowMin,
+		},
+		{
+			name: "above max",
+			req:  Request{Sum: 1001, Now: now, RegisteredAt: now.Add(-2 * time.Hour)},
+			err:  customerrors.ErrWithdrawAboveMax,
+		},
+		{
+			name: "hourly count",
+			req: Request{Sum: 100, Now: now, RegisteredAt: now.Add(-2 * time.Hour),
+				Stat: Stat{HourCount: 3}},
+			err: customerrors.ErrWithdrawRateLimit,
+		},
+		{
+			name: "daily cap",
+			req: Request{Sum: 600, Now: now, RegisteredAt: now.Add(-2 * time.Hour),
+				Stat: Stat{DaySum: 1000, MonthSum: 1000}},
+			err: customerrors.ErrWithdrawDailyCap,
+		},
+		{
+			name: "monthly cap",
+			req: Request{Sum: 600, Now: now, RegisteredAt: now.Add(-2 * time.Hour),
+				Stat: Stat{MonthSum: 4500}},
+			err: customerrors.ErrWithdrawMonthlyCap,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := engine.Check(test.req)
+			if test.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, test.err))
+		})
+	}
+}
+
+func TestEngine_CheckDisabledLimits(t *testing.T) {
+	engine := New(Limits{})
+	require.NoError(t, engine.Check(Request{Sum: 1e9, Stat: Stat{HourCount: 1e6}}))
+	require.ErrorIs(t, engine.Check(Request{Sum: 0}), customerrors.ErrInvalidSum)
+}
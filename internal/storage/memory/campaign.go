@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateCampaign(_ context.Context, c models.Campaign) (id int64, err error) {
+	if c.EndsAt.Before(c.StartsAt) || c.Value <= 0 {
+		return id, fmt.Errorf("CreateCampaign failed, %w", errCheck)
+	}
+
+	c.ID = tx.nextID("campaign")
+	c.Tiers = slices.Clone(c.Tiers)
+	if c.Tiers == nil {
+		c.Tiers = []string{}
+	}
+	c.CreatedAt = time.Now()
+	put(tx, tx.db.campaigns, c.ID, c)
+	tx.logger.Debugf("Create campaign, id, %v", c.ID)
+	return c.ID, nil
+}
+
+func (tx *Tx) GetCampaign(_ context.Context, campaignID int64, _ bool) (c models.Campaign, err error) {
+	c, ok := tx.db.campaigns[campaignID]
+	if !ok {
+		return c, customerrors.ErrNotFound
+	}
+	c.Tiers = slices.Clone(c.Tiers)
+	return c, nil
+}
+
+func (tx *Tx) GetCampaigns(_ context.Context) (campaigns []models.Campaign, err error) {
+	campaigns = tx.queryCampaigns(func(models.Campaign) bool { return true })
+	sort.SliceStable(campaigns, func(i, j int) bool {
+		return campaigns[i].StartsAt.After(campaigns[j].StartsAt)
+	})
+	return campaigns, nil
+}
+
+func (tx *Tx) GetActiveCampaigns(_ context.Context, at time.Time) (campaigns []models.Campaign, err error) {
+	return tx.queryCampaigns(func(c models.Campaign) bool {
+		return !c.StartsAt.After(at) && c.EndsAt.After(at)
+	}), nil
+}
+
+// queryCampaigns returns copies of the campaigns matching the filter in id order.
+func (tx *Tx) queryCampaigns(match func(c models.Campaign) bool) (campaigns []models.Campaign) {
+	for _, c := range rows(tx.db.campaigns) {
+		if match(c) {
+			c.Tiers = slices.Clone(c.Tiers)
+			campaigns = append(campaigns, c)
+		}
+	}
+	return campaigns
+}
+
+func (tx *Tx) EndCampaign(_ context.Context, campaignID int64, endsAt time.Time) (err error) {
+	c, ok := tx.db.campaigns[campaignID]
+	if !ok {
+		return fmt.Errorf("EndCampaign failed, %w", errNoRows)
+	}
+	if endsAt.Before(c.StartsAt) {
+		return fmt.Errorf("EndCampaign failed, %w", errCheck)
+	}
+	c.EndsAt = endsAt
+	put(tx, tx.db.campaigns, campaignID, c)
+	tx.logger.Debugf("End campaign, id, %v", campaignID)
+	return
+}
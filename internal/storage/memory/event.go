@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+type outboxEvent struct {
+	models.OutboxEvent
+	leasedUntil  time.Time
+	dispatchedAt *time.Time
+}
+
+func (tx *Tx) GetUserEvents(
+	_ context.Context,
+	userID, afterID int64,
+	since time.Time,
+	limit int64,
+) (events []models.UserEvent, err error) {
+	for _, e := range rows(tx.db.userEvents) {
+		if int64(len(events)) == limit {
+			break
+		}
+		if e.UserID == userID && e.ID > afterID && !e.CreatedAt.Before(since) {
+			e.Payload = slices.Clone(e.Payload)
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (tx *Tx) GetLastUserEventID(_ context.Context, userID int64) (id int64, err error) {
+	for _, e := range tx.db.userEvents {
+		if e.UserID == userID && e.ID > id {
+			id = e.ID
+		}
+	}
+	return id, nil
+}
+
+func (tx *Tx) DeleteUserEvents(_ context.Context, before time.Time) (err error) {
+	var count int
+	for id, e := range tx.db.userEvents {
+		if e.CreatedAt.Before(before) {
+			remove(tx, tx.db.userEvents, id)
+			count++
+		}
+	}
+	tx.logger.Debugf("Delete user events, count, %v", count)
+	return
+}
+
+func (tx *Tx) CreateOutboxEvent(_ context.Context, eventType string, payload []byte) (err error) {
+	id := tx.nextID("outbox_event")
+	put(tx, tx.db.outbox, id, outboxEvent{
+		OutboxEvent: models.OutboxEvent{
+			ID:        id,
+			Type:      eventType,
+			Payload:   slices.Clone(payload),
+			CreatedAt: time.Now(),
+		},
+	})
+	tx.logger.Debugf("Create outbox event, id, %v", id)
+	return
+}
+
+// ClaimPendingOutboxEvents leases not yet dispatched events until leaseUntil and returns them in id order.
+func (tx *Tx) ClaimPendingOutboxEvents(
+	_ context.Context,
+	now, leaseUntil time.Time,
+	limit int64,
+) (events []models.OutboxEvent, err error) {
+	for _, e := range rows(tx.db.outbox) {
+		if int64(len(events)) == limit {
+			break
+		}
+		if e.dispatchedAt != nil || e.leasedUntil.After(now) {
+			continue
+		}
+		e.leasedUntil = leaseUntil
+		put(tx, tx.db.outbox, e.ID, e)
+
+		e.Payload = slices.Clone(e.Payload)
+		events = append(events, e.OutboxEvent)
+	}
+	return events, nil
+}
+
+func (tx *Tx) MarkOutboxEventsDispatched(_ context.Context, ids []int64, at time.Time) (err error) {
+	var count int
+	for _, id := range ids {
+		e, ok := tx.db.outbox[id]
+		if !ok {
+			continue
+		}
+		e.dispatchedAt = &at
+		put(tx, tx.db.outbox, id, e)
+		count++
+	}
+	tx.logger.Debugf("Mark outbox events dispatched, count, %v", count)
+	return
+}
+
+func (tx *Tx) DeleteDispatchedOutboxEvents(_ context.Context, before time.Time) (err error) {
+	var count int
+	for id, e := range tx.db.outbox {
+		if e.dispatchedAt != nil && e.dispatchedAt.Before(before) {
+			remove(tx, tx.db.outbox, id)
+			count++
+		}
+	}
+	tx.logger.Debugf("Delete dispatched outbox events, count, %v", count)
+	return
+}
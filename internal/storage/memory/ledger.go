@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+type adjustment struct {
+	ID        int64
+	UserID    int64
+	Amount    float64
+	Reason    string
+	CreatedAt time.Time
+}
+
+type correction struct {
+	ID              int64
+	UserID          int64
+	BalanceBefore   float64
+	BalanceAfter    float64
+	WithdrawnBefore float64
+	WithdrawnAfter  float64
+	CreatedAt       time.Time
+}
+
+type ledgerEntry struct {
+	userID int64
+	models.StatementEntry
+}
+
+// ledger returns every balance change of every user, the same ones GetStatement and the reconciliation sum up.
+func (tx *Tx) ledger() (entries []ledgerEntry) {
+	add := func(userID int64, e models.StatementEntry) {
+		entries = append(entries, ledgerEntry{userID: userID, StatementEntry: e})
+	}
+
+	for _, o := range rows(tx.db.orders) {
+		if o.Status == models.PROCESSED.String() && o.Accrual.Float64 > 0 {
+			orderID := o.ID
+			add(o.UserID, models.StatementEntry{
+				ID: o.ID, At: o.ProcessedAt.Time, Kind: "ACCRUAL", OrderID: &orderID, Amount: o.Accrual.Float64,
+			})
+		}
+	}
+	for _, b := range rows(tx.db.bonuses) {
+		add(b.UserID, models.StatementEntry{
+			ID: b.ID, At: b.CreatedAt, Kind: "BONUS", OrderID: b.OrderID, Source: b.Source, Amount: b.Amount,
+		})
+	}
+	for _, w := range rows(tx.db.withdrawals) {
+		orderID := w.OrderID
+		add(w.UserID, models.StatementEntry{
+			ID: w.ID, At: w.CreatedAt, Kind: "WITHDRAWAL", OrderID: &orderID, Amount: -w.Sum,
+		})
+	}
+	for _, t := range rows(tx.db.transfers) {
+		add(t.RecipientID, models.StatementEntry{ID: t.ID, At: t.CreatedAt, Kind: "TRANSFER_IN", Amount: t.Sum})
+		add(t.SenderID, models.StatementEntry{ID: t.ID, At: t.CreatedAt, Kind: "TRANSFER_OUT", Amount: -t.Sum})
+	}
+	for _, a := range rows(tx.db.adjustments) {
+		add(a.UserID, models.StatementEntry{
+			ID: a.ID, At: a.CreatedAt, Kind: "ADJUSTMENT", Source: a.Reason, Amount: a.Amount,
+		})
+	}
+	return entries
+}
+
+// GetStatement streams up to limit user balance changes in [from, to) after the cursor to fn,
+// ordered by time, kind and id. The zero cursor starts with the opening balance.
+func (tx *Tx) GetStatement(
+	_ context.Context,
+	userID int64,
+	from, to time.Time,
+	after models.StatementCursor,
+	limit int64,
+	fn func(e models.StatementEntry) error,
+) (err error) {
+	first := after == models.StatementCursor{}
+	opening := models.StatementEntry{At: from, Kind: "OPENING"}
+	var entries []models.StatementEntry
+	for _, e := range tx.ledger() {
+		switch {
+		case e.userID != userID:
+		case e.At.Before(from):
+			opening.Amount += e.Amount
+		case e.At.Before(to) && statementLess(after, statementCursor(e.StatementEntry)):
+			entries = append(entries, e.StatementEntry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return statementLess(statementCursor(entries[i]), statementCursor(entries[j]))
+	})
+	if int64(len(entries)) > limit {
+		entries = entries[:limit]
+	}
+	if first {
+		entries = append([]models.StatementEntry{opening}, entries...)
+	}
+
+	for _, e := range entries {
+		if err = fn(e); err != nil {
+			return fmt.Errorf("get statement failed, %w", err)
+		}
+	}
+	return nil
+}
+
+func statementCursor(e models.StatementEntry) models.StatementCursor {
+	return models.StatementCursor{At: e.At, Kind: e.Kind, ID: e.ID}
+}
+
+// statementLess orders the statement entries by time, kind and id.
+func statementLess(a, b models.StatementCursor) bool {
+	if !a.At.Equal(b.At) {
+		return a.At.Before(b.At)
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.ID < b.ID
+}
+
+func (tx *Tx) CreateBalanceAdjustment(
+	_ context.Context,
+	userID int64,
+	amount float64,
+	reason string,
+) (id int64, err error) {
+	if amount == 0 || reason == "" {
+		return id, fmt.Errorf("CreateBalanceAdjustment failed, %w", errCheck)
+	}
+	if err = tx.checkUser(userID); err != nil {
+		return id, fmt.Errorf("CreateBalanceAdjustment failed, %w", err)
+	}
+
+	id = tx.nextID("balance_adjustment")
+	put(tx, tx.db.adjustments, id, adjustment{
+		ID:        id,
+		UserID:    userID,
+		Amount:    amount,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+	tx.logger.Debugf("Create balance adjustment, id, %v", id)
+	return
+}
+
+// ledgerSums returns the users next to the sums of their ledger in id order.
+func (tx *Tx) ledgerSums() (sums []models.BalanceMismatch) {
+	balances := make(map[int64]float64)
+	for _, e := range tx.ledger() {
+		balances[e.userID] += e.Amount
+	}
+	withdrawn := make(map[int64]float64)
+	for _, w := range tx.db.withdrawals {
+		withdrawn[w.UserID] += w.Sum
+	}
+
+	for _, u := range rows(tx.db.users) {
+		sums = append(sums, models.BalanceMismatch{
+			UserID:          u.ID,
+			Login:           u.Login,
+			Balance:         u.Balance,
+			Withdrawn:       u.Withdrawn,
+			LedgerBalance:   balances[u.ID],
+			LedgerWithdrawn: withdrawn[u.ID],
+		})
+	}
+	return sums
+}
+
+// GetBalanceMismatches returns the users whose balance or withdrawn differs from the ledger by more than tolerance.
+func (tx *Tx) GetBalanceMismatches(
+	_ context.Context,
+	tolerance float64,
+) (mismatches []models.BalanceMismatch, err error) {
+	for _, m := range tx.ledgerSums() {
+		if math.Abs(m.Balance-m.LedgerBalance) > tolerance || math.Abs(m.Withdrawn-m.LedgerWithdrawn) > tolerance {
+			mismatches = append(mismatches, m)
+		}
+	}
+	return mismatches, nil
+}
+
+// GetUserLedger returns the user balance and withdrawn next to their ledger sums.
+func (tx *Tx) GetUserLedger(_ context.Context, userID int64) (m models.BalanceMismatch, err error) {
+	for _, m = range tx.ledgerSums() {
+		if m.UserID == userID {
+			return m, nil
+		}
+	}
+	return models.BalanceMismatch{}, customerrors.ErrNotFound
+}
+
+func (tx *Tx) CreateBalanceCorrection(
+	_ context.Context,
+	userID int64,
+	balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter float64,
+) (err error) {
+	if err = tx.checkUser(userID); err != nil {
+		return fmt.Errorf("CreateBalanceCorrection failed, %w", err)
+	}
+
+	id := tx.nextID("balance_correction")
+	put(tx, tx.db.corrections, id, correction{
+		ID:              id,
+		UserID:          userID,
+		BalanceBefore:   balanceBefore,
+		BalanceAfter:    balanceAfter,
+		WithdrawnBefore: withdrawnBefore,
+		WithdrawnAfter:  withdrawnAfter,
+		CreatedAt:       time.Now(),
+	})
+	tx.logger.Debugf("Create balance correction, id, %v", id)
+	return
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+type tierChange struct {
+	ID        int64
+	UserID    int64
+	OldTier   string
+	NewTier   string
+	Volume    float64
+	CreatedAt time.Time
+}
+
+type bonus struct {
+	ID        int64
+	UserID    int64
+	OrderID   *int64
+	Source    string
+	Amount    float64
+	CreatedAt time.Time
+}
+
+func (tx *Tx) GetLoyaltyTiers(_ context.Context) (tiers []models.LoyaltyTier, err error) {
+	for _, t := range tx.db.tiers {
+		tiers = append(tiers, t)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Threshold < tiers[j].Threshold
+	})
+	return tiers, nil
+}
+
+func (tx *Tx) GetLoyaltyTier(_ context.Context, name string) (t models.LoyaltyTier, err error) {
+	t, ok := tx.db.tiers[name]
+	if !ok {
+		return t, customerrors.ErrNotFound
+	}
+	return t, nil
+}
+
+func (tx *Tx) GetUserAccrualVolume(_ context.Context, userID int64, since time.Time) (volume float64, err error) {
+	return tx.accrualVolumes(since)[userID], nil
+}
+
+func (tx *Tx) GetAccrualVolumes(_ context.Context, since time.Time) (volumes []models.UserVolume, err error) {
+	byUser := tx.accrualVolumes(since)
+	for _, u := range rows(tx.db.users) {
+		volumes = append(volumes, models.UserVolume{
+			UserID: u.ID,
+			Tier:   u.Tier,
+			Volume: byUser[u.ID],
+		})
+	}
+	return volumes, nil
+}
+
+// accrualVolumes sums the accruals of the processed orders uploaded since by user.
+func (tx *Tx) accrualVolumes(since time.Time) map[int64]float64 {
+	volumes := make(map[int64]float64)
+	for _, o := range tx.db.orders {
+		if o.Status == models.PROCESSED.String() && !o.UploadedAt.Before(since) {
+			volumes[o.UserID] += o.Accrual.Float64
+		}
+	}
+	return volumes
+}
+
+func (tx *Tx) CreateTierHistory(
+	_ context.Context,
+	userID int64,
+	oldTier, newTier string,
+	volume float64,
+) (err error) {
+	if err = tx.checkUser(userID); err != nil {
+		return fmt.Errorf("CreateTierHistory failed, %w", err)
+	}
+
+	id := tx.nextID("tier_history")
+	put(tx, tx.db.tierHistory, id, tierChange{
+		ID:        id,
+		UserID:    userID,
+		OldTier:   oldTier,
+		NewTier:   newTier,
+		Volume:    volume,
+		CreatedAt: time.Now(),
+	})
+	tx.logger.Debugf("Create tier history, id, %v", id)
+	return
+}
+
+// CreateBonus credits a bonus of the source, orderID of zero stores a bonus not bound to an order.
+func (tx *Tx) CreateBonus(
+	_ context.Context,
+	userID, orderID int64,
+	source string,
+	amount float64,
+) (err error) {
+	if err = tx.checkUser(userID); err != nil {
+		return fmt.Errorf("CreateBonus failed, %w", err)
+	}
+
+	b := bonus{
+		UserID:    userID,
+		Source:    source,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+	if orderID != 0 {
+		b.OrderID = &orderID
+	}
+	b.ID = tx.nextID("bonus")
+	put(tx, tx.db.bonuses, b.ID, b)
+	tx.logger.Debugf("Create bonus, id, %v", b.ID)
+	return
+}
@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+// the errors of the constraints the Postgres schema checks.
+var (
+	errNoRows     = errors.New("no rows in result set")
+	errUnique     = errors.New("duplicate key value violates unique constraint")
+	errForeignKey = errors.New("insert or update violates foreign key constraint")
+	errCheck      = errors.New("new row violates check constraint")
+)
+
+const userEventChannel = "user_event"
+
+// the loyalty tiers seeded by the migration.
+const (
+	silverThreshold  = 1000
+	silverMultiplier = 1.05
+	goldThreshold    = 5000
+	goldMultiplier   = 1.1
+)
+
+// Storage keeps the data in the process memory, it is lost on restart.
+// Units of work run one at a time, so every read sees the rows as if they were locked for update.
+type Storage struct {
+	sem chan struct{}
+	db  *tables
+
+	mu        sync.Mutex
+	listeners map[string]map[chan string]struct{}
+
+	logger *logrus.Logger
+}
+
+func New(logger *logrus.Logger) *Storage {
+	return &Storage{
+		sem:       make(chan struct{}, 1),
+		db:        newTables(),
+		listeners: make(map[string]map[chan string]struct{}),
+		logger:    logger,
+	}
+}
+
+func (m *Storage) Ping(_ context.Context) error {
+	return nil
+}
+
+func (m *Storage) Shutdown(_ context.Context) {
+	m.logger.Debug("memory storage shutdown")
+}
+
+// WithTx runs fn alone, the changes it made are undone if it fails.
+func (m *Storage) WithTx(ctx context.Context, fn func(s storage.Store) error) error {
+	select {
+	case m.sem <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to open transaction, %w", ctx.Err())
+	}
+
+	tx := &Tx{db: m.db, logger: m.logger}
+	if err := m.run(tx, fn); err != nil {
+		return err
+	}
+	m.notify(tx.notifications)
+	return nil
+}
+
+// run calls fn and releases the storage, the changes are undone unless fn returns nil.
+func (m *Storage) run(tx *Tx, fn func(s storage.Store) error) error {
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+		}
+		<-m.sem
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// Listen calls notify with the payloads sent to the channel by committed units of work until ctx is done.
+func (m *Storage) Listen(ctx context.Context, channel string, notify func(payload string)) error {
+	const buffer = 64
+	ch := make(chan string, buffer)
+
+	m.mu.Lock()
+	if m.listeners[channel] == nil {
+		m.listeners[channel] = make(map[chan string]struct{})
+	}
+	m.listeners[channel][ch] = struct{}{}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.listeners[channel], ch)
+		m.mu.Unlock()
+	}()
+	m.logger.Debugf("Listen channel, %s", channel)
+
+	for {
+		select {
+		case payload := <-ch:
+			notify(payload)
+		case <-ctx.Done():
+			return fmt.Errorf("wait for notification failed, %w", ctx.Err())
+		}
+	}
+}
+
+func (m *Storage) notify(notifications []notification) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, n := range notifications {
+		for ch := range m.listeners[n.channel] {
+			select {
+			case ch <- n.payload:
+			default:
+				m.logger.Warnf("listener of %s is too slow, notification dropped", n.channel)
+			}
+		}
+	}
+}
+
+type notification struct {
+	channel string
+	payload string
+}
+
+// Tx is the storage.Store of a unit of work, it writes to the tables in place
+// and remembers how to undo every change.
+type Tx struct {
+	db            *tables
+	undo          []func()
+	notifications []notification
+
+	logger *logrus.Logger
+}
+
+func (tx *Tx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+	tx.notifications = nil
+}
+
+// put stores the row of the table and keeps the previous one to undo.
+func put[K comparable, V any](tx *Tx, table map[K]V, key K, row V) {
+	old, ok := table[key]
+	tx.undo = append(tx.undo, func() {
+		if ok {
+			table[key] = old
+		} else {
+			delete(table, key)
+		}
+	})
+	table[key] = row
+}
+
+// remove deletes the row of the table and keeps it to undo.
+func remove[K comparable, V any](tx *Tx, table map[K]V, key K) {
+	old, ok := table[key]
+	if !ok {
+		return
+	}
+	tx.undo = append(tx.undo, func() {
+		table[key] = old
+	})
+	delete(table, key)
+}
+
+// rows returns the rows of the table in id order, like a sequence scan of a freshly filled table.
+func rows[V any](table map[int64]V) []V {
+	ids := make([]int64, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	result := make([]V, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, table[id])
+	}
+	return result
+}
+
+// tables are the rows of the Postgres schema, the sequences are not rolled back like in Postgres.
+type tables struct {
+	seq map[string]int64
+
+	users       map[int64]user
+	orders      map[int64]models.Order
+	statuses    map[int64]models.OrderStatusChange
+	withdrawals map[int64]models.Withdraw
+	holds       map[int64]models.Hold
+	transfers   map[int64]models.Transfer
+	tiers       map[string]models.LoyaltyTier
+	tierHistory map[int64]tierChange
+	bonuses     map[int64]bonus
+	referrals   map[int64]models.Referral
+	campaigns   map[int64]models.Campaign
+	userEvents  map[int64]models.UserEvent
+	webhooks    map[int64]models.Webhook
+	deliveries  map[int64]models.WebhookDelivery
+	outbox      map[int64]outboxEvent
+	rateLimits  map[string]rateLimit
+	adjustments map[int64]adjustment
+	corrections map[int64]correction
+}
+
+func newTables() *tables {
+	return &tables{
+		seq:         make(map[string]int64),
+		users:       make(map[int64]user),
+		orders:      make(map[int64]models.Order),
+		statuses:    make(map[int64]models.OrderStatusChange),
+		withdrawals: make(map[int64]models.Withdraw),
+		holds:       make(map[int64]models.Hold),
+		transfers:   make(map[int64]models.Transfer),
+		tiers: map[string]models.LoyaltyTier{
+			"BRONZE": {Name: "BRONZE", Threshold: 0, Multiplier: 1},
+			"SILVER": {Name: "SILVER", Threshold: silverThreshold, Multiplier: silverMultiplier},
+			"GOLD":   {Name: "GOLD", Threshold: goldThreshold, Multiplier: goldMultiplier},
+		},
+		tierHistory: make(map[int64]tierChange),
+		bonuses:     make(map[int64]bonus),
+		referrals:   make(map[int64]models.Referral),
+		campaigns:   make(map[int64]models.Campaign),
+		userEvents:  make(map[int64]models.UserEvent),
+		webhooks:    make(map[int64]models.Webhook),
+		deliveries:  make(map[int64]models.WebhookDelivery),
+		outbox:      make(map[int64]outboxEvent),
+		rateLimits:  make(map[string]rateLimit),
+		adjustments: make(map[int64]adjustment),
+		corrections: make(map[int64]correction),
+	}
+}
+
+// nextID takes the next value of the table sequence.
+func (tx *Tx) nextID(table string) int64 {
+	tx.db.seq[table]++
+	return tx.db.seq[table]
+}
+
+func between(at time.Time, from, to *time.Time) bool {
+	return (from == nil || !at.Before(*from)) && (to == nil || at.Before(*to))
+}
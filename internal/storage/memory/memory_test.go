@@ -0,0 +1,318 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+	"github.com/NStegura/gophermart/internal/storage"
+)
+
+func newUser(t *testing.T, m *Storage, login string) (id int64) {
+	t.Helper()
+	err := m.WithTx(context.Background(), func(s storage.Store) (err error) {
+		id, err = s.CreateUser(context.Background(), login, "hash", "CODE"+login)
+		return err
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func TestWithTx_rollback(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	errFailed := errors.New("failed")
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		require.NoError(t, s.UpdateUserBalance(ctx, userID, 100, 0))
+		require.NoError(t, s.CreateOrder(ctx, userID, 79927398713))
+		_, err := s.CreateUser(ctx, "bob", "hash", "CODEbob")
+		require.NoError(t, err)
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		u, err := s.GetUserByID(ctx, userID, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, u.Balance)
+
+		version, err := s.GetUserVersion(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), version)
+
+		_, err = s.GetOrder(ctx, 79927398713, false)
+		assert.ErrorIs(t, err, customerrors.ErrNotFound)
+		_, err = s.GetUserByLogin(ctx, "bob")
+		assert.ErrorIs(t, err, customerrors.ErrNotFound)
+
+		id, err := s.GetLastUserEventID(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), id)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWithTx_triggers(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		require.NoError(t, s.CreateOrder(ctx, userID, 12345678903))
+		require.NoError(t, s.UpdateOrder(ctx, 12345678903, 500, models.PROCESSED.String()))
+		require.NoError(t, s.UpdateUserBalance(ctx, userID, 500, 0))
+		// an unchanged balance is not an event
+		require.NoError(t, s.UpdateUserBalance(ctx, userID, 500, 0))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		version, err := s.GetUserVersion(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), version)
+
+		o, err := s.GetOrder(ctx, 12345678903, false)
+		require.NoError(t, err)
+		assert.True(t, o.ProcessedAt.Valid)
+
+		events, err := s.GetUserEvents(ctx, userID, 0, time.Time{}, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, "order", events[0].Type)
+		assert.JSONEq(t, `{"number": "12345678903", "status": "PROCESSED", "accrual": 500}`, string(events[0].Payload))
+		assert.Equal(t, "balance", events[1].Type)
+		assert.JSONEq(t, `{"current": 500, "withdrawn": 0, "held": 0, "available": 500}`, string(events[1].Payload))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWithTx_constraints(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		_, err := s.CreateUser(ctx, "alice", "hash", "OTHER")
+		return err
+	})
+	assert.ErrorIs(t, err, errUnique)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		return s.CreateTransfer(ctx, userID, userID, 10)
+	})
+	assert.ErrorIs(t, err, errCheck)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		return s.CreateWithdraw(ctx, userID+1, 1, 10)
+	})
+	assert.ErrorIs(t, err, errForeignKey)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		return s.UpdateUserBalance(ctx, userID+1, 10, 0)
+	})
+	assert.ErrorIs(t, err, errNoRows)
+}
+
+func TestWithTx_serialized(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := m.WithTx(ctx, func(s storage.Store) error {
+				u, err := s.GetUserByID(ctx, userID, true)
+				if err != nil {
+					return err
+				}
+				return s.UpdateUserBalance(ctx, userID, u.Balance+1, u.Withdrawn)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		u, err := s.GetUserByID(ctx, userID, false)
+		require.NoError(t, err)
+		assert.Equal(t, float64(n), u.Balance)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWithTx_canceled(t *testing.T) {
+	m := New(logrus.New())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = m.WithTx(context.Background(), func(storage.Store) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	cancel()
+	err := m.WithTx(ctx, func(storage.Store) error {
+		t.Fatal("unit of work runs next to another one")
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	close(release)
+}
+
+func TestListen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	payloads := make(chan string, 10)
+	done := make(chan error)
+	go func() {
+		done <- m.Listen(ctx, userEventChannel, func(payload string) {
+			payloads <- payload
+		})
+	}()
+	// wait for the listener to subscribe
+	require.Eventually(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.listeners[userEventChannel]) == 1
+	}, time.Second, time.Millisecond)
+
+	_ = m.WithTx(ctx, func(s storage.Store) error {
+		require.NoError(t, s.UpdateUserBalance(ctx, userID, 10, 0))
+		return errors.New("failed")
+	})
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		return s.UpdateUserBalance(ctx, userID, 20, 0)
+	})
+	require.NoError(t, err)
+
+	select {
+	case payload := <-payloads:
+		assert.Equal(t, "1", payload)
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+	assert.Empty(t, payloads, "rolled back unit of work notified")
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestGetStatement(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	alice, bob := newUser(t, m, "alice"), newUser(t, m, "bob")
+
+	from := time.Now()
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		require.NoError(t, s.CreateBonus(ctx, alice, 0, "referral", 50))
+		require.NoError(t, s.CreateTransfer(ctx, alice, bob, 20))
+		require.NoError(t, s.CreateWithdraw(ctx, alice, 2377225624, 10))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// pages of two entries, the opening balance is not counted
+	var (
+		entries []models.StatementEntry
+		after   models.StatementCursor
+	)
+	for page := 0; page < 3; page++ {
+		err = m.WithTx(ctx, func(s storage.Store) error {
+			return s.GetStatement(ctx, alice, from, time.Now().Add(time.Second), after, 2,
+				func(e models.StatementEntry) error {
+					entries = append(entries, e)
+					after = models.StatementCursor{At: e.At, Kind: e.Kind, ID: e.ID}
+					return nil
+				})
+		})
+		require.NoError(t, err)
+	}
+
+	kinds := make([]string, 0, len(entries))
+	var sum float64
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+		sum += e.Amount
+	}
+	assert.Equal(t, []string{"OPENING", "BONUS", "TRANSFER_OUT", "WITHDRAWAL"}, kinds)
+	assert.Equal(t, 20.0, sum)
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		mismatches, err := s.GetBalanceMismatches(ctx, 0.005)
+		require.NoError(t, err)
+		require.Len(t, mismatches, 2)
+		assert.Equal(t, 20.0, mismatches[0].LedgerBalance)
+		assert.Equal(t, 10.0, mismatches[0].LedgerWithdrawn)
+		assert.Equal(t, 20.0, mismatches[1].LedgerBalance)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWithTx_clonesSlices(t *testing.T) {
+	ctx := context.Background()
+	m := New(logrus.New())
+	userID := newUser(t, m, "alice")
+
+	events := []string{"order.processed"}
+	var webhookID int64
+	err := m.WithTx(ctx, func(s storage.Store) error {
+		w, err := s.CreateWebhook(ctx, userID, "http://hook", "secret", events)
+		webhookID = w.ID
+		w.Events[0] = "changed"
+		return err
+	})
+	require.NoError(t, err)
+	events[0] = "changed"
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		w, err := s.GetWebhook(ctx, webhookID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order.processed"}, w.Events)
+		return nil
+	})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(map[string]string{"a": "b"})
+	require.NoError(t, err)
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		return s.CreateWebhookDeliveries(ctx, userID, "order.processed", payload)
+	})
+	require.NoError(t, err)
+	payload[0] = 'x'
+
+	err = m.WithTx(ctx, func(s storage.Store) error {
+		deliveries, err := s.GetWebhookDeliveries(ctx, webhookID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.JSONEq(t, `{"a": "b"}`, string(deliveries[0].Payload))
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+type orderPayload struct {
+	Number  string   `json:"number"`
+	Status  string   `json:"status"`
+	Accrual *float64 `json:"accrual"`
+}
+
+func (tx *Tx) CreateOrder(_ context.Context, userID, orderID int64) (err error) {
+	if err = tx.insertOrder(userID, orderID); err != nil {
+		return fmt.Errorf("CreateOrder failed, %w", err)
+	}
+	tx.logger.Debugf("Create order, id, %v", orderID)
+	return
+}
+
+// CreateOrders inserts the orders that are not uploaded yet and returns their ids.
+func (tx *Tx) CreateOrders(_ context.Context, userID int64, orderIDs []int64) (created []int64, err error) {
+	for _, orderID := range orderIDs {
+		if _, ok := tx.db.orders[orderID]; ok {
+			continue
+		}
+		if err = tx.insertOrder(userID, orderID); err != nil {
+			return created, fmt.Errorf("CreateOrders failed, %w", err)
+		}
+		created = append(created, orderID)
+	}
+	tx.logger.Debugf("Create orders, count, %v", len(created))
+	return created, nil
+}
+
+// insertOrder adds a NEW order bumping the user version like the order_user_version_insert trigger.
+func (tx *Tx) insertOrder(userID, orderID int64) error {
+	if _, ok := tx.db.orders[orderID]; ok {
+		return errUnique
+	}
+	if err := tx.checkUser(userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	put(tx, tx.db.orders, orderID, models.Order{
+		ID:         orderID,
+		Status:     models.NEW.String(),
+		UserID:     userID,
+		UploadedAt: now,
+		UpdatedAt:  now,
+	})
+	tx.bumpVersion(userID)
+	return nil
+}
+
+func (tx *Tx) GetOrder(_ context.Context, orderID int64, _ bool) (o models.Order, err error) {
+	o, ok := tx.db.orders[orderID]
+	if !ok {
+		return o, customerrors.ErrNotFound
+	}
+	return o, nil
+}
+
+func (tx *Tx) GetOrders(
+	_ context.Context,
+	userID int64,
+	filter models.ListFilter,
+) (orders []models.Order, err error) {
+	for _, o := range rows(tx.db.orders) {
+		if o.UserID != userID || !between(o.UploadedAt, filter.From, filter.To) {
+			continue
+		}
+		if filter.Statuses != nil && !slices.Contains(filter.Statuses, o.Status) {
+			continue
+		}
+		if (filter.MinAmount != nil || filter.MaxAmount != nil) && !o.Accrual.Valid {
+			continue
+		}
+		if filter.MinAmount != nil && o.Accrual.Float64 < *filter.MinAmount ||
+			filter.MaxAmount != nil && o.Accrual.Float64 > *filter.MaxAmount {
+			continue
+		}
+		orders = append(orders, o)
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		if filter.Desc {
+			return orders[i].UploadedAt.After(orders[j].UploadedAt)
+		}
+		return orders[i].UploadedAt.Before(orders[j].UploadedAt)
+	})
+	return orders, nil
+}
+
+// GetOrderOwners returns user id by order id for the existing orders.
+func (tx *Tx) GetOrderOwners(_ context.Context, orderIDs []int64) (owners map[int64]int64, err error) {
+	owners = make(map[int64]int64, len(orderIDs))
+	for _, orderID := range orderIDs {
+		if o, ok := tx.db.orders[orderID]; ok {
+			owners[orderID] = o.UserID
+		}
+	}
+	return owners, nil
+}
+
+func (tx *Tx) GetNotProcessedOrders(_ context.Context) (orders []models.Order, err error) {
+	for _, o := range rows(tx.db.orders) {
+		if o.Status == models.NEW.String() || o.Status == models.PROCESSING.String() {
+			orders = append(orders, o)
+		}
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].UploadedAt.Before(orders[j].UploadedAt)
+	})
+	return orders, nil
+}
+
+func (tx *Tx) UpdateOrder(_ context.Context, orderID int64, accrual float64, status string) (err error) {
+	o, ok := tx.db.orders[orderID]
+	if !ok {
+		return fmt.Errorf("UpdateOrder failed, %w", errNoRows)
+	}
+
+	now := time.Now()
+	o.Accrual = sql.NullFloat64{Float64: accrual, Valid: true}
+	o.Status = status
+	o.UpdatedAt = now
+	if (status == models.PROCESSED.String() || status == models.INVALID.String()) && !o.ProcessedAt.Valid {
+		o.ProcessedAt = sql.NullTime{Time: now, Valid: true}
+	}
+	if err = tx.updateOrder(o); err != nil {
+		return fmt.Errorf("UpdateOrder failed, %w", err)
+	}
+	tx.logger.Debugf("UpdateOrder, id, %v", orderID)
+	return
+}
+
+// RequeueOrders resets the orders in the statuses not checked since updatedBefore to NEW,
+// so the accrual sync treats them as just uploaded.
+func (tx *Tx) RequeueOrders(
+	_ context.Context,
+	statuses []string,
+	updatedBefore time.Time,
+) (orders []models.Order, err error) {
+	now := time.Now()
+	for _, o := range rows(tx.db.orders) {
+		if !slices.Contains(statuses, o.Status) || !o.UpdatedAt.Before(updatedBefore) {
+			continue
+		}
+		o.Status = models.NEW.String()
+		o.Accrual = sql.NullFloat64{Valid: true}
+		o.ProcessedAt = sql.NullTime{}
+		o.UpdatedAt = now
+		if err = tx.updateOrder(o); err != nil {
+			return orders, fmt.Errorf("requeue orders failed, %w", err)
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// updateOrder stores the order row doing the work of the order_user_version_update and order_user_event triggers.
+func (tx *Tx) updateOrder(o models.Order) error {
+	old := tx.db.orders[o.ID]
+	put(tx, tx.db.orders, o.ID, o)
+	if old.Status == o.Status && old.Accrual == o.Accrual {
+		return nil
+	}
+
+	tx.bumpVersion(o.UserID)
+	payload := orderPayload{
+		Number: strconv.FormatInt(o.ID, 10),
+		Status: o.Status,
+	}
+	if o.Accrual.Valid {
+		payload.Accrual = &o.Accrual.Float64
+	}
+	return tx.createUserEvent(o.UserID, "order", payload)
+}
+
+func (tx *Tx) CreateOrderStatus(_ context.Context, orderID int64, status string, accrual float64) (err error) {
+	if _, ok := tx.db.orders[orderID]; !ok {
+		return fmt.Errorf("CreateOrderStatus failed, %w", errForeignKey)
+	}
+
+	id := tx.nextID("order_status_history")
+	put(tx, tx.db.statuses, id, models.OrderStatusChange{
+		ID:        id,
+		OrderID:   orderID,
+		Status:    status,
+		Accrual:   accrual,
+		CreatedAt: time.Now(),
+	})
+	tx.logger.Debugf("Create order status, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetOrderStatusHistory(
+	_ context.Context,
+	orderID int64,
+) (history []models.OrderStatusChange, err error) {
+	for _, h := range rows(tx.db.statuses) {
+		if h.OrderID == orderID {
+			history = append(history, h)
+		}
+	}
+	return history, nil
+}
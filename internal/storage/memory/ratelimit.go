@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+type rateLimit struct {
+	start time.Time
+	end   time.Time
+	count int64
+}
+
+// TakeRateLimit counts a request of the key in the current window, windows are aligned to the epoch.
+func (tx *Tx) TakeRateLimit(
+	_ context.Context,
+	key string,
+	window time.Duration,
+) (count int64, reset time.Time, err error) {
+	start := time.Now().Truncate(window)
+
+	r, ok := tx.db.rateLimits[key]
+	if !ok || !r.start.Equal(start) {
+		r = rateLimit{start: start, end: start.Add(window)}
+	}
+	r.count++
+	put(tx, tx.db.rateLimits, key, r)
+	return r.count, r.end, nil
+}
+
+func (tx *Tx) DeleteExpiredRateLimits(_ context.Context) (err error) {
+	now := time.Now()
+	for key, r := range tx.db.rateLimits {
+		if r.end.Before(now) {
+			remove(tx, tx.db.rateLimits, key)
+		}
+	}
+	return
+}
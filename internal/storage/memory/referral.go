@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateReferral(_ context.Context, referrerID, refereeID int64) (err error) {
+	if referrerID == refereeID {
+		return fmt.Errorf("CreateReferral failed, %w", errCheck)
+	}
+	if err = tx.checkUser(referrerID); err != nil {
+		return fmt.Errorf("CreateReferral failed, %w", err)
+	}
+	if err = tx.checkUser(refereeID); err != nil {
+		return fmt.Errorf("CreateReferral failed, %w", err)
+	}
+	for _, r := range tx.db.referrals {
+		if r.RefereeID == refereeID {
+			return fmt.Errorf("CreateReferral failed, %w", errUnique)
+		}
+	}
+
+	id := tx.nextID("referral")
+	put(tx, tx.db.referrals, id, models.Referral{
+		ID:         id,
+		ReferrerID: referrerID,
+		RefereeID:  refereeID,
+		Status:     models.PENDING.String(),
+		CreatedAt:  time.Now(),
+	})
+	tx.logger.Debugf("Create referral, id, %v", id)
+	return
+}
+
+func (tx *Tx) CountReferrals(_ context.Context, referrerID int64) (count int64, err error) {
+	for _, r := range tx.db.referrals {
+		if r.ReferrerID == referrerID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (tx *Tx) GetReferralByReferee(_ context.Context, refereeID int64, _ bool) (r models.Referral, err error) {
+	for _, row := range tx.db.referrals {
+		if row.RefereeID == refereeID {
+			return row, nil
+		}
+	}
+	return r, customerrors.ErrNotFound
+}
+
+func (tx *Tx) GetReferrals(_ context.Context, referrerID int64) (referrals []models.ReferralInfo, err error) {
+	for _, r := range rows(tx.db.referrals) {
+		if r.ReferrerID != referrerID {
+			continue
+		}
+		referrals = append(referrals, models.ReferralInfo{
+			Referral:     r,
+			RefereeLogin: tx.db.users[r.RefereeID].Login,
+		})
+	}
+	sort.SliceStable(referrals, func(i, j int) bool {
+		return referrals[i].CreatedAt.Before(referrals[j].CreatedAt)
+	})
+	return referrals, nil
+}
+
+func (tx *Tx) RewardReferral(_ context.Context, referralID int64) (err error) {
+	r, ok := tx.db.referrals[referralID]
+	if !ok {
+		return fmt.Errorf("RewardReferral failed, %w", errNoRows)
+	}
+	r.Status = models.REWARDED.String()
+	r.RewardedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	put(tx, tx.db.referrals, referralID, r)
+	tx.logger.Debugf("Reward referral, id, %v", referralID)
+	return
+}
@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+const defaultTier = "BRONZE"
+
+type user struct {
+	models.User
+	version   int64
+	updatedAt time.Time
+}
+
+type balancePayload struct {
+	Current   float64 `json:"current"`
+	Withdrawn float64 `json:"withdrawn"`
+	Held      float64 `json:"held"`
+	Available float64 `json:"available"`
+}
+
+func (tx *Tx) CreateUser(_ context.Context, login, password, referralCode string) (id int64, err error) {
+	for _, u := range tx.db.users {
+		if u.Login == login || u.ReferralCode == referralCode {
+			return id, fmt.Errorf("CreateUser failed, %w", errUnique)
+		}
+	}
+
+	now := time.Now()
+	id = tx.nextID("user")
+	put(tx, tx.db.users, id, user{
+		User: models.User{
+			ID:           id,
+			Login:        login,
+			Password:     password,
+			Tier:         defaultTier,
+			ReferralCode: referralCode,
+			CreatedAt:    now,
+		},
+		updatedAt: now,
+	})
+	tx.logger.Debugf("Create user, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetUserByLogin(_ context.Context, login string) (u models.User, err error) {
+	for _, row := range tx.db.users {
+		if row.Login == login {
+			return row.User, nil
+		}
+	}
+	return u, customerrors.ErrNotFound
+}
+
+func (tx *Tx) GetUserByID(_ context.Context, id int64, _ bool) (u models.User, err error) {
+	row, ok := tx.db.users[id]
+	if !ok {
+		return u, customerrors.ErrNotFound
+	}
+	return row.User, nil
+}
+
+func (tx *Tx) GetUserByReferralCode(_ context.Context, code string) (u models.User, err error) {
+	for _, row := range tx.db.users {
+		if row.ReferralCode == code {
+			return row.User, nil
+		}
+	}
+	return u, customerrors.ErrNotFound
+}
+
+func (tx *Tx) GetUserVersion(_ context.Context, userID int64) (version int64, err error) {
+	row, ok := tx.db.users[userID]
+	if !ok {
+		return version, customerrors.ErrNotFound
+	}
+	return row.version, nil
+}
+
+func (tx *Tx) UpdateUserBalance(_ context.Context, userID int64, balance, withdrawn float64) (err error) {
+	row, ok := tx.db.users[userID]
+	if !ok {
+		return fmt.Errorf("UpdateUserBalance failed, %w", errNoRows)
+	}
+	row.Balance, row.Withdrawn = balance, withdrawn
+	if err = tx.updateUser(row); err != nil {
+		return fmt.Errorf("UpdateUserBalance failed, %w", err)
+	}
+	tx.logger.Debugf("Update user balance, id, %v", userID)
+	return
+}
+
+func (tx *Tx) UpdateUserHeld(_ context.Context, userID int64, held float64) (err error) {
+	row, ok := tx.db.users[userID]
+	if !ok {
+		return fmt.Errorf("UpdateUserHeld failed, %w", errNoRows)
+	}
+	row.Held = held
+	if err = tx.updateUser(row); err != nil {
+		return fmt.Errorf("UpdateUserHeld failed, %w", err)
+	}
+	tx.logger.Debugf("Update user held, id, %v", userID)
+	return
+}
+
+func (tx *Tx) UpdateUserTier(_ context.Context, userID int64, tier string) (err error) {
+	row, ok := tx.db.users[userID]
+	if !ok {
+		return fmt.Errorf("UpdateUserTier failed, %w", errNoRows)
+	}
+	if _, ok = tx.db.tiers[tier]; !ok {
+		return fmt.Errorf("UpdateUserTier failed, %w", errForeignKey)
+	}
+	row.Tier = tier
+	if err = tx.updateUser(row); err != nil {
+		return fmt.Errorf("UpdateUserTier failed, %w", err)
+	}
+	tx.logger.Debugf("Update user tier, id, %v", userID)
+	return
+}
+
+// updateUser stores the user row doing the work of the user_version and balance_user_event triggers.
+func (tx *Tx) updateUser(row user) error {
+	old := tx.db.users[row.ID]
+	balanceChanged := old.Balance != row.Balance || old.Withdrawn != row.Withdrawn || old.Held != row.Held
+	if balanceChanged || old.Tier != row.Tier {
+		row.version++
+	}
+	row.updatedAt = time.Now()
+	put(tx, tx.db.users, row.ID, row)
+
+	if !balanceChanged {
+		return nil
+	}
+	return tx.createUserEvent(row.ID, "balance", balancePayload{
+		Current:   row.Balance,
+		Withdrawn: row.Withdrawn,
+		Held:      row.Held,
+		Available: row.Balance - row.Held,
+	})
+}
+
+// bumpVersion does the work of the order_user_version triggers.
+func (tx *Tx) bumpVersion(userID int64) {
+	row, ok := tx.db.users[userID]
+	if !ok {
+		return
+	}
+	row.version++
+	put(tx, tx.db.users, userID, row)
+}
+
+// createUserEvent inserts the event and notifies its user like the user_event_notify trigger.
+func (tx *Tx) createUserEvent(userID int64, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user event, %w", err)
+	}
+
+	id := tx.nextID("user_event")
+	put(tx, tx.db.userEvents, id, models.UserEvent{
+		ID:        id,
+		UserID:    userID,
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	})
+	tx.notifications = append(tx.notifications, notification{
+		channel: userEventChannel,
+		payload: strconv.FormatInt(userID, 10),
+	})
+	return nil
+}
+
+// checkUser fails like the foreign keys to the user table.
+func (tx *Tx) checkUser(userID int64) error {
+	if _, ok := tx.db.users[userID]; !ok {
+		return errForeignKey
+	}
+	return nil
+}
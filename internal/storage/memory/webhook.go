@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateWebhook(
+	_ context.Context,
+	userID int64,
+	url, secret string,
+	events []string,
+) (w models.Webhook, err error) {
+	if err = tx.checkUser(userID); err != nil {
+		return w, fmt.Errorf("CreateWebhook failed, %w", err)
+	}
+
+	w = models.Webhook{
+		ID:        tx.nextID("webhook"),
+		UserID:    userID,
+		URL:       url,
+		Secret:    secret,
+		Events:    slices.Clone(events),
+		Active:    true,
+		CreatedAt: time.Now(),
+	}
+	put(tx, tx.db.webhooks, w.ID, w)
+	tx.logger.Debugf("Create webhook, id, %v", w.ID)
+
+	w.Events = slices.Clone(w.Events)
+	return w, nil
+}
+
+func (tx *Tx) GetWebhook(_ context.Context, webhookID int64) (w models.Webhook, err error) {
+	w, ok := tx.db.webhooks[webhookID]
+	if !ok {
+		return w, customerrors.ErrNotFound
+	}
+	w.Events = slices.Clone(w.Events)
+	return w, nil
+}
+
+func (tx *Tx) GetWebhooks(_ context.Context, userID int64) (webhooks []models.Webhook, err error) {
+	for _, w := range rows(tx.db.webhooks) {
+		if w.UserID == userID && w.Active {
+			w.Events = slices.Clone(w.Events)
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (tx *Tx) DisableWebhook(_ context.Context, webhookID int64) (err error) {
+	w, ok := tx.db.webhooks[webhookID]
+	if !ok {
+		return fmt.Errorf("DisableWebhook failed, %w", errNoRows)
+	}
+	w.Active = false
+	put(tx, tx.db.webhooks, webhookID, w)
+	tx.logger.Debugf("Disable webhook, id, %v", webhookID)
+	return
+}
+
+// CreateWebhookDeliveries queues the event for every active webhook of the user subscribed to it.
+func (tx *Tx) CreateWebhookDeliveries(_ context.Context, userID int64, event string, payload []byte) (err error) {
+	var count int
+	now := time.Now()
+	for _, w := range rows(tx.db.webhooks) {
+		if w.UserID != userID || !w.Active || !slices.Contains(w.Events, event) {
+			continue
+		}
+		id := tx.nextID("webhook_delivery")
+		put(tx, tx.db.deliveries, id, models.WebhookDelivery{
+			ID:            id,
+			WebhookID:     w.ID,
+			Event:         event,
+			Payload:       slices.Clone(payload),
+			Status:        models.QUEUED.String(),
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		count++
+	}
+	tx.logger.Debugf("Create webhook deliveries, event %s, count, %v", event, count)
+	return
+}
+
+func (tx *Tx) GetWebhookDeliveries(
+	_ context.Context,
+	webhookID, limit int64,
+) (deliveries []models.WebhookDelivery, err error) {
+	all := rows(tx.db.deliveries)
+	for i := len(all) - 1; i >= 0 && int64(len(deliveries)) < limit; i-- {
+		if all[i].WebhookID == webhookID {
+			d := all[i]
+			d.Payload = slices.Clone(d.Payload)
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// RequeueWebhookDelivery schedules the delivery of the webhook for an immediate new round of attempts.
+func (tx *Tx) RequeueWebhookDelivery(_ context.Context, webhookID, deliveryID int64) (err error) {
+	d, ok := tx.db.deliveries[deliveryID]
+	if !ok || d.WebhookID != webhookID {
+		return customerrors.ErrNotFound
+	}
+	d.Status = models.QUEUED.String()
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	put(tx, tx.db.deliveries, deliveryID, d)
+	tx.logger.Debugf("Requeue webhook delivery, id, %v", deliveryID)
+	return
+}
+
+// ClaimDueWebhookDeliveries leases queued deliveries until leaseUntil,
+// so the next claims skip them while they are being sent.
+func (tx *Tx) ClaimDueWebhookDeliveries(
+	_ context.Context,
+	now, leaseUntil time.Time,
+	limit int64,
+) (deliveries []models.DueDelivery, err error) {
+	var due []models.WebhookDelivery
+	for _, d := range rows(tx.db.deliveries) {
+		if d.Status == models.QUEUED.String() && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if int64(len(due)) > limit {
+		due = due[:limit]
+	}
+
+	for _, d := range due {
+		d.NextAttemptAt = leaseUntil
+		put(tx, tx.db.deliveries, d.ID, d)
+
+		w := tx.db.webhooks[d.WebhookID]
+		d.Payload = slices.Clone(d.Payload)
+		deliveries = append(deliveries, models.DueDelivery{
+			WebhookDelivery: d,
+			URL:             w.URL,
+			Secret:          w.Secret,
+			Active:          w.Active,
+		})
+	}
+	return deliveries, nil
+}
+
+func (tx *Tx) UpdateWebhookDelivery(_ context.Context, d models.WebhookDelivery) (err error) {
+	row, ok := tx.db.deliveries[d.ID]
+	if !ok {
+		return fmt.Errorf("UpdateWebhookDelivery failed, %w", errNoRows)
+	}
+	row.Status = d.Status
+	row.Attempts = d.Attempts
+	row.NextAttemptAt = d.NextAttemptAt
+	row.LastStatusCode = d.LastStatusCode
+	row.LastError = d.LastError
+	row.DeliveredAt = d.DeliveredAt
+	put(tx, tx.db.deliveries, d.ID, row)
+	tx.logger.Debugf("Update webhook delivery, id, %v", d.ID)
+	return
+}
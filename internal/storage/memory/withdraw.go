@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/customerrors"
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+func (tx *Tx) CreateWithdraw(_ context.Context, userID, orderID int64, sum float64) (err error) {
+	if err = tx.checkUser(userID); err != nil {
+		return fmt.Errorf("CreateWithdraw failed, %w", err)
+	}
+
+	id := tx.nextID("withdraw")
+	put(tx, tx.db.withdrawals, id, models.Withdraw{
+		ID:        id,
+		OrderID:   orderID,
+		UserID:    userID,
+		Sum:       sum,
+		CreatedAt: time.Now(),
+	})
+	tx.logger.Debugf("Create withdraw, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetWithdrawals(
+	_ context.Context,
+	userID int64,
+	filter models.ListFilter,
+) (withdrawals []models.Withdraw, err error) {
+	for _, w := range rows(tx.db.withdrawals) {
+		if w.UserID != userID || !between(w.CreatedAt, filter.From, filter.To) {
+			continue
+		}
+		if filter.MinAmount != nil && w.Sum < *filter.MinAmount ||
+			filter.MaxAmount != nil && w.Sum > *filter.MaxAmount {
+			continue
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	sort.SliceStable(withdrawals, func(i, j int) bool {
+		if filter.Desc {
+			return withdrawals[i].CreatedAt.After(withdrawals[j].CreatedAt)
+		}
+		return withdrawals[i].CreatedAt.Before(withdrawals[j].CreatedAt)
+	})
+	return withdrawals, nil
+}
+
+// GetWithdrawStat counts the withdrawals and the active holds.
+func (tx *Tx) GetWithdrawStat(
+	_ context.Context,
+	userID int64,
+	hourFrom, dayFrom, monthFrom time.Time,
+) (stat models.WithdrawStat, err error) {
+	add := func(createdAt time.Time, sum float64) {
+		if !createdAt.Before(hourFrom) {
+			stat.HourCount++
+		}
+		if !createdAt.Before(dayFrom) {
+			stat.DaySum += sum
+		}
+		if !createdAt.Before(monthFrom) {
+			stat.MonthSum += sum
+		}
+	}
+
+	for _, w := range tx.db.withdrawals {
+		if w.UserID == userID {
+			add(w.CreatedAt, w.Sum)
+		}
+	}
+	now := time.Now()
+	for _, h := range tx.db.holds {
+		if h.UserID == userID && h.Status == models.HELD.String() && h.ExpiresAt.After(now) {
+			add(h.CreatedAt, h.Sum)
+		}
+	}
+	return stat, nil
+}
+
+func (tx *Tx) CreateHold(
+	_ context.Context,
+	userID, orderID int64,
+	sum float64,
+	expiresAt time.Time,
+) (id int64, err error) {
+	if sum <= 0 {
+		return id, fmt.Errorf("CreateHold failed, %w", errCheck)
+	}
+	if err = tx.checkUser(userID); err != nil {
+		return id, fmt.Errorf("CreateHold failed, %w", err)
+	}
+
+	now := time.Now()
+	id = tx.nextID("withdraw_hold")
+	put(tx, tx.db.holds, id, models.Hold{
+		ID:        id,
+		UserID:    userID,
+		OrderID:   orderID,
+		Sum:       sum,
+		Status:    models.HELD.String(),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	tx.logger.Debugf("Create hold, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetHold(_ context.Context, holdID int64, _ bool) (h models.Hold, err error) {
+	h, ok := tx.db.holds[holdID]
+	if !ok {
+		return h, customerrors.ErrNotFound
+	}
+	return h, nil
+}
+
+func (tx *Tx) GetExpiredHolds(_ context.Context, now time.Time, limit int64) (holds []models.Hold, err error) {
+	for _, h := range rows(tx.db.holds) {
+		if h.Status == models.HELD.String() && !h.ExpiresAt.After(now) {
+			holds = append(holds, h)
+		}
+	}
+	sort.SliceStable(holds, func(i, j int) bool {
+		return holds[i].ExpiresAt.Before(holds[j].ExpiresAt)
+	})
+	if int64(len(holds)) > limit {
+		holds = holds[:limit]
+	}
+	return holds, nil
+}
+
+func (tx *Tx) UpdateHoldStatus(_ context.Context, holdID int64, status string) (err error) {
+	h, ok := tx.db.holds[holdID]
+	if !ok {
+		return fmt.Errorf("UpdateHoldStatus failed, %w", errNoRows)
+	}
+	h.Status = status
+	h.UpdatedAt = time.Now()
+	put(tx, tx.db.holds, holdID, h)
+	tx.logger.Debugf("Update hold status, id, %v", holdID)
+	return
+}
+
+func (tx *Tx) CreateTransfer(_ context.Context, senderID, recipientID int64, sum float64) (err error) {
+	if sum <= 0 || senderID == recipientID {
+		return fmt.Errorf("CreateTransfer failed, %w", errCheck)
+	}
+	if err = tx.checkUser(senderID); err != nil {
+		return fmt.Errorf("CreateTransfer failed, %w", err)
+	}
+	if err = tx.checkUser(recipientID); err != nil {
+		return fmt.Errorf("CreateTransfer failed, %w", err)
+	}
+
+	id := tx.nextID("transfer")
+	put(tx, tx.db.transfers, id, models.Transfer{
+		ID:          id,
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Sum:         sum,
+		CreatedAt:   time.Now(),
+	})
+	tx.logger.Debugf("Create transfer, id, %v", id)
+	return
+}
+
+func (tx *Tx) GetSentTransfersStat(
+	_ context.Context,
+	senderID int64,
+	since time.Time,
+) (count int64, sum float64, err error) {
+	for _, t := range tx.db.transfers {
+		if t.SenderID == senderID && !t.CreatedAt.Before(since) {
+			count++
+			sum += t.Sum
+		}
+	}
+	return count, sum, nil
+}
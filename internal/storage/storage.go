@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/NStegura/gophermart/internal/repo/models"
+)
+
+// Storage runs units of work. The changes made by fn are committed if it returns nil
+// and rolled back otherwise, its error is returned as is.
+type Storage interface {
+	Ping(ctx context.Context) error
+	WithTx(ctx context.Context, fn func(s Store) error) error
+	// Listen calls notify with the payload of every notification of the channel until ctx is done,
+	// notifications are sent by committed units of work only.
+	Listen(ctx context.Context, channel string, notify func(payload string)) error
+	Shutdown(ctx context.Context)
+}
+
+// Store is the data access of one unit of work. Reads with forUpdate lock the row
+// until the unit of work ends, missing rows are customerrors.ErrNotFound.
+type Store interface {
+	UserStore
+	OrderStore
+	WithdrawStore
+	LoyaltyStore
+	ReferralStore
+	CampaignStore
+	WebhookStore
+	EventStore
+	LedgerStore
+	RateLimitStore
+}
+
+type UserStore interface {
+	CreateUser(ctx context.Context, login, password, referralCode string) (id int64, err error)
+	GetUserByLogin(ctx context.Context, login string) (u models.User, err error)
+	GetUserByID(ctx context.Context, id int64, forUpdate bool) (u models.User, err error)
+	GetUserByReferralCode(ctx context.Context, code string) (u models.User, err error)
+	GetUserVersion(ctx context.Context, userID int64) (version int64, err error)
+	UpdateUserBalance(ctx context.Context, userID int64, balance, withdrawn float64) (err error)
+	UpdateUserHeld(ctx context.Context, userID int64, held float64) (err error)
+	UpdateUserTier(ctx context.Context, userID int64, tier string) (err error)
+}
+
+type OrderStore interface {
+	CreateOrder(ctx context.Context, userID, orderID int64) (err error)
+	CreateOrders(ctx context.Context, userID int64, orderIDs []int64) (created []int64, err error)
+	GetOrder(ctx context.Context, orderID int64, forUpdate bool) (o models.Order, err error)
+	GetOrders(ctx context.Context, userID int64, filter models.ListFilter) (orders []models.Order, err error)
+	GetOrderOwners(ctx context.Context, orderIDs []int64) (owners map[int64]int64, err error)
+	GetNotProcessedOrders(ctx context.Context) (orders []models.Order, err error)
+	UpdateOrder(ctx context.Context, orderID int64, accrual float64, status string) (err error)
+	RequeueOrders(ctx context.Context, statuses []string, updatedBefore time.Time) (orders []models.Order, err error)
+	CreateOrderStatus(ctx context.Context, orderID int64, status string, accrual float64) (err error)
+	GetOrderStatusHistory(ctx context.Context, orderID int64) (history []models.OrderStatusChange, err error)
+}
+
+type WithdrawStore interface {
+	CreateWithdraw(ctx context.Context, userID, orderID int64, sum float64) (err error)
+	GetWithdrawals(ctx context.Context, userID int64, filter models.ListFilter) (withdrawals []models.Withdraw, err error)
+	GetWithdrawStat(
+		ctx context.Context, userID int64, hourFrom, dayFrom, monthFrom time.Time,
+	) (stat models.WithdrawStat, err error)
+	CreateHold(ctx context.Context, userID, orderID int64, sum float64, expiresAt time.Time) (id int64, err error)
+	GetHold(ctx context.Context, holdID int64, forUpdate bool) (h models.Hold, err error)
+	GetExpiredHolds(ctx context.Context, now time.Time, limit int64) (holds []models.Hold, err error)
+	UpdateHoldStatus(ctx context.Context, holdID int64, status string) (err error)
+	CreateTransfer(ctx context.Context, senderID, recipientID int64, sum float64) (err error)
+	GetSentTransfersStat(ctx context.Context, senderID int64, since time.Time) (count int64, sum float64, err error)
+}
+
+type LoyaltyStore interface {
+	GetLoyaltyTiers(ctx context.Context) (tiers []models.LoyaltyTier, err error)
+	GetLoyaltyTier(ctx context.Context, name string) (t models.LoyaltyTier, err error)
+	GetUserAccrualVolume(ctx context.Context, userID int64, since time.Time) (volume float64, err error)
+	GetAccrualVolumes(ctx context.Context, since time.Time) (volumes []models.UserVolume, err error)
+	CreateTierHistory(ctx context.Context, userID int64, oldTier, newTier string, volume float64) (err error)
+	CreateBonus(ctx context.Context, userID, orderID int64, source string, amount float64) (err error)
+}
+
+type ReferralStore interface {
+	CreateReferral(ctx context.Context, referrerID, refereeID int64) (err error)
+	CountReferrals(ctx context.Context, referrerID int64) (count int64, err error)
+	GetReferralByReferee(ctx context.Context, refereeID int64, forUpdate bool) (r models.Referral, err error)
+	GetReferrals(ctx context.Context, referrerID int64) (referrals []models.ReferralInfo, err error)
+	RewardReferral(ctx context.Context, referralID int64) (err error)
+}
+
+type CampaignStore interface {
+	CreateCampaign(ctx context.Context, c models.Campaign) (id int64, err error)
+	GetCampaign(ctx context.Context, campaignID int64, forUpdate bool) (c models.Campaign, err error)
+	GetCampaigns(ctx context.Context) (campaigns []models.Campaign, err error)
+	GetActiveCampaigns(ctx context.Context, at time.Time) (campaigns []models.Campaign, err error)
+	EndCampaign(ctx context.Context, campaignID int64, endsAt time.Time) (err error)
+}
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, userID int64, url, secret string, events []string) (w models.Webhook, err error)
+	GetWebhook(ctx context.Context, webhookID int64) (w models.Webhook, err error)
+	GetWebhooks(ctx context.Context, userID int64) (webhooks []models.Webhook, err error)
+	DisableWebhook(ctx context.Context, webhookID int64) (err error)
+	CreateWebhookDeliveries(ctx context.Context, userID int64, event string, payload []byte) (err error)
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit int64) (deliveries []models.WebhookDelivery, err error)
+	RequeueWebhookDelivery(ctx context.Context, webhookID, deliveryID int64) (err error)
+	ClaimDueWebhookDeliveries(
+		ctx context.Context, now, leaseUntil time.Time, limit int64,
+	) (deliveries []models.DueDelivery, err error)
+	UpdateWebhookDelivery(ctx context.Context, d models.WebhookDelivery) (err error)
+}
+
+type EventStore interface {
+	GetUserEvents(
+		ctx context.Context, userID, afterID int64, since time.Time, limit int64,
+	) (events []models.UserEvent, err error)
+	GetLastUserEventID(ctx context.Context, userID int64) (id int64, err error)
+	DeleteUserEvents(ctx context.Context, before time.Time) (err error)
+	CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) (err error)
+	ClaimPendingOutboxEvents(
+		ctx context.Context, now, leaseUntil time.Time, limit int64,
+	) (events []models.OutboxEvent, err error)
+	MarkOutboxEventsDispatched(ctx context.Context, ids []int64, at time.Time) (err error)
+	DeleteDispatchedOutboxEvents(ctx context.Context, before time.Time) (err error)
+}
+
+type LedgerStore interface {
+	GetStatement(
+		ctx context.Context,
+		userID int64,
+		from, to time.Time,
+		after models.StatementCursor,
+		limit int64,
+		fn func(e models.StatementEntry) error,
+	) (err error)
+	CreateBalanceAdjustment(ctx context.Context, userID int64, amount float64, reason string) (id int64, err error)
+	GetBalanceMismatches(ctx context.Context, tolerance float64) (mismatches []models.BalanceMismatch, err error)
+	GetUserLedger(ctx context.Context, userID int64) (m models.BalanceMismatch, err error)
+	CreateBalanceCorrection(
+		ctx context.Context, userID int64, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter float64,
+	) (err error)
+}
+
+type RateLimitStore interface {
+	TakeRateLimit(ctx context.Context, key string, window time.Duration) (count int64, reset time.Time, err error)
+	DeleteExpiredRateLimits(ctx context.Context) (err error)
+}
@@ -48,18 +48,18 @@ func (mr *MockAuthMockRecorder) CheckPasswordHash(password, hash interface{}) *g
 }
 
 // GeneratePasswordHash mocks base method.
-func (m *MockAuth) GeneratePasswordHash(password string, complexity int) (string, error) {
+func (m *MockAuth) GeneratePasswordHash(password string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GeneratePasswordHash", password, complexity)
+	ret := m.ctrl.Call(m, "GeneratePasswordHash", password)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GeneratePasswordHash indicates an expected call of GeneratePasswordHash.
-func (mr *MockAuthMockRecorder) GeneratePasswordHash(password, complexity interface{}) *gomock.Call {
+func (mr *MockAuthMockRecorder) GeneratePasswordHash(password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePasswordHash", reflect.TypeOf((*MockAuth)(nil).GeneratePasswordHash), password, complexity)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GeneratePasswordHash", reflect.TypeOf((*MockAuth)(nil).GeneratePasswordHash), password)
 }
 
 // GenerateToken mocks base method.
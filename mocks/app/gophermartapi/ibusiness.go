@@ -7,6 +7,7 @@ package mock_gophermartapi
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/NStegura/gophermart/internal/services/business/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,50 @@ func (m *MockBusiness) EXPECT() *MockBusinessMockRecorder {
 	return m.recorder
 }
 
+// CaptureHold mocks base method.
+func (m *MockBusiness) CaptureHold(ctx context.Context, userID, holdID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockBusinessMockRecorder) CaptureHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockBusiness)(nil).CaptureHold), ctx, userID, holdID)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockBusiness) CreateCampaign(ctx context.Context, campaign models.Campaign) (models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, campaign)
+	ret0, _ := ret[0].(models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockBusinessMockRecorder) CreateCampaign(ctx, campaign interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockBusiness)(nil).CreateCampaign), ctx, campaign)
+}
+
+// CreateHold mocks base method.
+func (m *MockBusiness) CreateHold(ctx context.Context, userID, orderID int64, sum float64) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, userID, orderID, sum)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockBusinessMockRecorder) CreateHold(ctx, userID, orderID, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockBusiness)(nil).CreateHold), ctx, userID, orderID, sum)
+}
+
 // CreateOrder mocks base method.
 func (m *MockBusiness) CreateOrder(ctx context.Context, userID, orderID int64) error {
 	m.ctrl.T.Helper()
@@ -49,19 +94,63 @@ func (mr *MockBusinessMockRecorder) CreateOrder(ctx, userID, orderID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockBusiness)(nil).CreateOrder), ctx, userID, orderID)
 }
 
+// CreateOrders mocks base method.
+func (m *MockBusiness) CreateOrders(ctx context.Context, userID int64, orderIDs []int64) ([]models.OrderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrders", ctx, userID, orderIDs)
+	ret0, _ := ret[0].([]models.OrderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrders indicates an expected call of CreateOrders.
+func (mr *MockBusinessMockRecorder) CreateOrders(ctx, userID, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrders", reflect.TypeOf((*MockBusiness)(nil).CreateOrders), ctx, userID, orderIDs)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockBusiness) CreateTransfer(ctx context.Context, senderID int64, recipientLogin string, sum float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, senderID, recipientLogin, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockBusinessMockRecorder) CreateTransfer(ctx, senderID, recipientLogin, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockBusiness)(nil).CreateTransfer), ctx, senderID, recipientLogin, sum)
+}
+
 // CreateUser mocks base method.
-func (m *MockBusiness) CreateUser(ctx context.Context, login, password string) (int64, error) {
+func (m *MockBusiness) CreateUser(ctx context.Context, login, password, referralCode string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, login, password)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, login, password, referralCode)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockBusinessMockRecorder) CreateUser(ctx, login, password interface{}) *gomock.Call {
+func (mr *MockBusinessMockRecorder) CreateUser(ctx, login, password, referralCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockBusiness)(nil).CreateUser), ctx, login, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockBusiness)(nil).CreateUser), ctx, login, password, referralCode)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockBusiness) CreateWebhook(ctx context.Context, userID int64, url string, events []string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, events)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockBusinessMockRecorder) CreateWebhook(ctx, userID, url, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockBusiness)(nil).CreateWebhook), ctx, userID, url, events)
 }
 
 // CreateWithdraw mocks base method.
@@ -78,19 +167,107 @@ func (mr *MockBusinessMockRecorder) CreateWithdraw(ctx, userID, orderID, sum int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockBusiness)(nil).CreateWithdraw), ctx, userID, orderID, sum)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockBusiness) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, userID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockBusinessMockRecorder) DeleteWebhook(ctx, userID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockBusiness)(nil).DeleteWebhook), ctx, userID, webhookID)
+}
+
+// EndCampaign mocks base method.
+func (m *MockBusiness) EndCampaign(ctx context.Context, campaignID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndCampaign", ctx, campaignID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndCampaign indicates an expected call of EndCampaign.
+func (mr *MockBusinessMockRecorder) EndCampaign(ctx, campaignID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndCampaign", reflect.TypeOf((*MockBusiness)(nil).EndCampaign), ctx, campaignID)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockBusiness) GetCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockBusinessMockRecorder) GetCampaigns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockBusiness)(nil).GetCampaigns), ctx)
+}
+
+// GetLastUserEventID mocks base method.
+func (m *MockBusiness) GetLastUserEventID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastUserEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastUserEventID indicates an expected call of GetLastUserEventID.
+func (mr *MockBusinessMockRecorder) GetLastUserEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUserEventID", reflect.TypeOf((*MockBusiness)(nil).GetLastUserEventID), ctx, userID)
+}
+
+// GetOrder mocks base method.
+func (m *MockBusiness) GetOrder(ctx context.Context, userID, orderID int64) (models.OrderDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, userID, orderID)
+	ret0, _ := ret[0].(models.OrderDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockBusinessMockRecorder) GetOrder(ctx, userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockBusiness)(nil).GetOrder), ctx, userID, orderID)
+}
+
 // GetOrders mocks base method.
-func (m *MockBusiness) GetOrders(ctx context.Context, userID int64) ([]models.Order, error) {
+func (m *MockBusiness) GetOrders(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrders", ctx, userID)
+	ret := m.ctrl.Call(m, "GetOrders", ctx, userID, filter)
 	ret0, _ := ret[0].([]models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrders indicates an expected call of GetOrders.
-func (mr *MockBusinessMockRecorder) GetOrders(ctx, userID interface{}) *gomock.Call {
+func (mr *MockBusinessMockRecorder) GetOrders(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockBusiness)(nil).GetOrders), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockBusiness)(nil).GetOrders), ctx, userID, filter)
+}
+
+// GetReferrals mocks base method.
+func (m *MockBusiness) GetReferrals(ctx context.Context, userID int64) (models.Referrals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, userID)
+	ret0, _ := ret[0].(models.Referrals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockBusinessMockRecorder) GetReferrals(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockBusiness)(nil).GetReferrals), ctx, userID)
 }
 
 // GetUserByID mocks base method.
@@ -123,19 +300,94 @@ func (mr *MockBusinessMockRecorder) GetUserByLogin(ctx, login interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockBusiness)(nil).GetUserByLogin), ctx, login)
 }
 
+// GetUserEvents mocks base method.
+func (m *MockBusiness) GetUserEvents(ctx context.Context, userID, afterID int64, since time.Time, limit int64) ([]models.UserEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID, afterID, since, limit)
+	ret0, _ := ret[0].([]models.UserEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockBusinessMockRecorder) GetUserEvents(ctx, userID, afterID, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockBusiness)(nil).GetUserEvents), ctx, userID, afterID, since, limit)
+}
+
+// GetUserTier mocks base method.
+func (m *MockBusiness) GetUserTier(ctx context.Context, userID int64) (models.Tier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTier", ctx, userID)
+	ret0, _ := ret[0].(models.Tier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTier indicates an expected call of GetUserTier.
+func (mr *MockBusinessMockRecorder) GetUserTier(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTier", reflect.TypeOf((*MockBusiness)(nil).GetUserTier), ctx, userID)
+}
+
+// GetUserVersion mocks base method.
+func (m *MockBusiness) GetUserVersion(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserVersion", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVersion indicates an expected call of GetUserVersion.
+func (mr *MockBusinessMockRecorder) GetUserVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVersion", reflect.TypeOf((*MockBusiness)(nil).GetUserVersion), ctx, userID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockBusiness) GetWebhookDeliveries(ctx context.Context, userID, webhookID int64) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, userID, webhookID)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockBusinessMockRecorder) GetWebhookDeliveries(ctx, userID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockBusiness)(nil).GetWebhookDeliveries), ctx, userID, webhookID)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockBusiness) GetWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockBusinessMockRecorder) GetWebhooks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockBusiness)(nil).GetWebhooks), ctx, userID)
+}
+
 // GetWithdrawals mocks base method.
-func (m *MockBusiness) GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdraw, error) {
+func (m *MockBusiness) GetWithdrawals(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Withdraw, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID)
+	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID, filter)
 	ret0, _ := ret[0].([]models.Withdraw)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWithdrawals indicates an expected call of GetWithdrawals.
-func (mr *MockBusinessMockRecorder) GetWithdrawals(ctx, userID interface{}) *gomock.Call {
+func (mr *MockBusinessMockRecorder) GetWithdrawals(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockBusiness)(nil).GetWithdrawals), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockBusiness)(nil).GetWithdrawals), ctx, userID, filter)
 }
 
 // Ping mocks base method.
@@ -151,3 +403,45 @@ func (mr *MockBusinessMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockBusiness)(nil).Ping), ctx)
 }
+
+// RedeliverWebhook mocks base method.
+func (m *MockBusiness) RedeliverWebhook(ctx context.Context, userID, webhookID, deliveryID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverWebhook", ctx, userID, webhookID, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeliverWebhook indicates an expected call of RedeliverWebhook.
+func (mr *MockBusinessMockRecorder) RedeliverWebhook(ctx, userID, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverWebhook", reflect.TypeOf((*MockBusiness)(nil).RedeliverWebhook), ctx, userID, webhookID, deliveryID)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockBusiness) ReleaseHold(ctx context.Context, userID, holdID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, userID, holdID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockBusinessMockRecorder) ReleaseHold(ctx, userID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockBusiness)(nil).ReleaseHold), ctx, userID, holdID)
+}
+
+// WriteStatement mocks base method.
+func (m *MockBusiness) WriteStatement(ctx context.Context, userID int64, from, to time.Time, w models.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteStatement", ctx, userID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteStatement indicates an expected call of WriteStatement.
+func (mr *MockBusinessMockRecorder) WriteStatement(ctx, userID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStatement", reflect.TypeOf((*MockBusiness)(nil).WriteStatement), ctx, userID, from, to, w)
+}
@@ -8,9 +8,8 @@ import (
 	context "context"
 	reflect "reflect"
 
-	models "github.com/NStegura/gophermart/internal/repo/models"
+	storage "github.com/NStegura/gophermart/internal/storage"
 	gomock "github.com/golang/mock/gomock"
-	pgx "github.com/jackc/pgx/v5"
 )
 
 // MockRepository is a mock of Repository interface.
@@ -36,153 +35,6 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// Commit mocks base method.
-func (m *MockRepository) Commit(ctx context.Context, tx pgx.Tx) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Commit", ctx, tx)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Commit indicates an expected call of Commit.
-func (mr *MockRepositoryMockRecorder) Commit(ctx, tx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockRepository)(nil).Commit), ctx, tx)
-}
-
-// CreateOrder mocks base method.
-func (m *MockRepository) CreateOrder(ctx context.Context, tx pgx.Tx, userID, orderID int64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOrder", ctx, tx, userID, orderID)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// CreateOrder indicates an expected call of CreateOrder.
-func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, tx, userID, orderID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), ctx, tx, userID, orderID)
-}
-
-// CreateUser mocks base method.
-func (m *MockRepository) CreateUser(ctx context.Context, tx pgx.Tx, login, password string) (int64, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, tx, login, password)
-	ret0, _ := ret[0].(int64)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockRepositoryMockRecorder) CreateUser(ctx, tx, login, password interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, tx, login, password)
-}
-
-// CreateWithdraw mocks base method.
-func (m *MockRepository) CreateWithdraw(ctx context.Context, tx pgx.Tx, userID, orderID int64, sum float64) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWithdraw", ctx, tx, userID, orderID, sum)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// CreateWithdraw indicates an expected call of CreateWithdraw.
-func (mr *MockRepositoryMockRecorder) CreateWithdraw(ctx, tx, userID, orderID, sum interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockRepository)(nil).CreateWithdraw), ctx, tx, userID, orderID, sum)
-}
-
-// GetOrder mocks base method.
-func (m *MockRepository) GetOrder(ctx context.Context, tx pgx.Tx, orderID int64, forUpdate bool) (models.Order, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrder", ctx, tx, orderID, forUpdate)
-	ret0, _ := ret[0].(models.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetOrder indicates an expected call of GetOrder.
-func (mr *MockRepositoryMockRecorder) GetOrder(ctx, tx, orderID, forUpdate interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockRepository)(nil).GetOrder), ctx, tx, orderID, forUpdate)
-}
-
-// GetOrders mocks base method.
-func (m *MockRepository) GetOrders(ctx context.Context, tx pgx.Tx, userID int64) ([]models.Order, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrders", ctx, tx, userID)
-	ret0, _ := ret[0].([]models.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetOrders indicates an expected call of GetOrders.
-func (mr *MockRepositoryMockRecorder) GetOrders(ctx, tx, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockRepository)(nil).GetOrders), ctx, tx, userID)
-}
-
-// GetUserByID mocks base method.
-func (m *MockRepository) GetUserByID(ctx context.Context, tx pgx.Tx, ID int64, forUpdate bool) (models.User, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", ctx, tx, ID, forUpdate)
-	ret0, _ := ret[0].(models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockRepositoryMockRecorder) GetUserByID(ctx, tx, ID, forUpdate interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), ctx, tx, ID, forUpdate)
-}
-
-// GetUserByLogin mocks base method.
-func (m *MockRepository) GetUserByLogin(ctx context.Context, tx pgx.Tx, login string) (models.User, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, tx, login)
-	ret0, _ := ret[0].(models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetUserByLogin indicates an expected call of GetUserByLogin.
-func (mr *MockRepositoryMockRecorder) GetUserByLogin(ctx, tx, login interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockRepository)(nil).GetUserByLogin), ctx, tx, login)
-}
-
-// GetWithdrawals mocks base method.
-func (m *MockRepository) GetWithdrawals(ctx context.Context, tx pgx.Tx, userID int64) ([]models.Withdraw, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, tx, userID)
-	ret0, _ := ret[0].([]models.Withdraw)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// GetWithdrawals indicates an expected call of GetWithdrawals.
-func (mr *MockRepositoryMockRecorder) GetWithdrawals(ctx, tx, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockRepository)(nil).GetWithdrawals), ctx, tx, userID)
-}
-
-// OpenTransaction mocks base method.
-func (m *MockRepository) OpenTransaction(ctx context.Context) (pgx.Tx, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenTransaction", ctx)
-	ret0, _ := ret[0].(pgx.Tx)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// OpenTransaction indicates an expected call of OpenTransaction.
-func (mr *MockRepositoryMockRecorder) OpenTransaction(ctx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenTransaction", reflect.TypeOf((*MockRepository)(nil).OpenTransaction), ctx)
-}
-
 // Ping mocks base method.
 func (m *MockRepository) Ping(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -197,30 +49,16 @@ func (mr *MockRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
 }
 
-// Rollback mocks base method.
-func (m *MockRepository) Rollback(ctx context.Context, tx pgx.Tx) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rollback", ctx, tx)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Rollback indicates an expected call of Rollback.
-func (mr *MockRepositoryMockRecorder) Rollback(ctx, tx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockRepository)(nil).Rollback), ctx, tx)
-}
-
-// UpdateUserBalance mocks base method.
-func (m *MockRepository) UpdateUserBalance(ctx context.Context, tx pgx.Tx, userID int64, balance, withdrawn float64) error {
+// WithTx mocks base method.
+func (m *MockRepository) WithTx(ctx context.Context, fn func(storage.Store) error) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserBalance", ctx, tx, userID, balance, withdrawn)
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateUserBalance indicates an expected call of UpdateUserBalance.
-func (mr *MockRepositoryMockRecorder) UpdateUserBalance(ctx, tx, userID, balance, withdrawn interface{}) *gomock.Call {
+// WithTx indicates an expected call of WithTx.
+func (mr *MockRepositoryMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockRepository)(nil).UpdateUserBalance), ctx, tx, userID, balance, withdrawn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockRepository)(nil).WithTx), ctx, fn)
 }
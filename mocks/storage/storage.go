@@ -0,0 +1,2279 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/storage/storage.go
+
+// Package mock_storage is a generated GoMock package.
+package mock_storage
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/NStegura/gophermart/internal/repo/models"
+	storage "github.com/NStegura/gophermart/internal/storage"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockStorage) Listen(ctx context.Context, channel string, notify func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx, channel, notify)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockStorageMockRecorder) Listen(ctx, channel, notify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockStorage)(nil).Listen), ctx, channel, notify)
+}
+
+// Ping mocks base method.
+func (m *MockStorage) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStorageMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorage)(nil).Ping), ctx)
+}
+
+// Shutdown mocks base method.
+func (m *MockStorage) Shutdown(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Shutdown", ctx)
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockStorageMockRecorder) Shutdown(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockStorage)(nil).Shutdown), ctx)
+}
+
+// WithTx mocks base method.
+func (m *MockStorage) WithTx(ctx context.Context, fn func(storage.Store) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockStorageMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockStorage)(nil).WithTx), ctx, fn)
+}
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueWebhookDeliveries mocks base method.
+func (m *MockStore) ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]models.DueDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebhookDeliveries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]models.DueDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebhookDeliveries indicates an expected call of ClaimDueWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ClaimDueWebhookDeliveries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimDueWebhookDeliveries), ctx, now, leaseUntil, limit)
+}
+
+// ClaimPendingOutboxEvents mocks base method.
+func (m *MockStore) ClaimPendingOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingOutboxEvents", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingOutboxEvents indicates an expected call of ClaimPendingOutboxEvents.
+func (mr *MockStoreMockRecorder) ClaimPendingOutboxEvents(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingOutboxEvents", reflect.TypeOf((*MockStore)(nil).ClaimPendingOutboxEvents), ctx, now, leaseUntil, limit)
+}
+
+// CountReferrals mocks base method.
+func (m *MockStore) CountReferrals(ctx context.Context, referrerID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReferrals", ctx, referrerID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReferrals indicates an expected call of CountReferrals.
+func (mr *MockStoreMockRecorder) CountReferrals(ctx, referrerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReferrals", reflect.TypeOf((*MockStore)(nil).CountReferrals), ctx, referrerID)
+}
+
+// CreateBalanceAdjustment mocks base method.
+func (m *MockStore) CreateBalanceAdjustment(ctx context.Context, userID int64, amount float64, reason string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceAdjustment", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceAdjustment indicates an expected call of CreateBalanceAdjustment.
+func (mr *MockStoreMockRecorder) CreateBalanceAdjustment(ctx, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceAdjustment", reflect.TypeOf((*MockStore)(nil).CreateBalanceAdjustment), ctx, userID, amount, reason)
+}
+
+// CreateBalanceCorrection mocks base method.
+func (m *MockStore) CreateBalanceCorrection(ctx context.Context, userID int64, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceCorrection", ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBalanceCorrection indicates an expected call of CreateBalanceCorrection.
+func (mr *MockStoreMockRecorder) CreateBalanceCorrection(ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceCorrection", reflect.TypeOf((*MockStore)(nil).CreateBalanceCorrection), ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter)
+}
+
+// CreateBonus mocks base method.
+func (m *MockStore) CreateBonus(ctx context.Context, userID, orderID int64, source string, amount float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBonus", ctx, userID, orderID, source, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBonus indicates an expected call of CreateBonus.
+func (mr *MockStoreMockRecorder) CreateBonus(ctx, userID, orderID, source, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBonus", reflect.TypeOf((*MockStore)(nil).CreateBonus), ctx, userID, orderID, source, amount)
+}
+
+// CreateCampaign mocks base method.
+func (m *MockStore) CreateCampaign(ctx context.Context, c models.Campaign) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockStoreMockRecorder) CreateCampaign(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockStore)(nil).CreateCampaign), ctx, c)
+}
+
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(ctx context.Context, userID, orderID int64, sum float64, expiresAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, userID, orderID, sum, expiresAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(ctx, userID, orderID, sum, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), ctx, userID, orderID, sum, expiresAt)
+}
+
+// CreateOrder mocks base method.
+func (m *MockStore) CreateOrder(ctx context.Context, userID, orderID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, userID, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockStoreMockRecorder) CreateOrder(ctx, userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockStore)(nil).CreateOrder), ctx, userID, orderID)
+}
+
+// CreateOrderStatus mocks base method.
+func (m *MockStore) CreateOrderStatus(ctx context.Context, orderID int64, status string, accrual float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrderStatus", ctx, orderID, status, accrual)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrderStatus indicates an expected call of CreateOrderStatus.
+func (mr *MockStoreMockRecorder) CreateOrderStatus(ctx, orderID, status, accrual interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderStatus", reflect.TypeOf((*MockStore)(nil).CreateOrderStatus), ctx, orderID, status, accrual)
+}
+
+// CreateOrders mocks base method.
+func (m *MockStore) CreateOrders(ctx context.Context, userID int64, orderIDs []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrders", ctx, userID, orderIDs)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrders indicates an expected call of CreateOrders.
+func (mr *MockStoreMockRecorder) CreateOrders(ctx, userID, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrders", reflect.TypeOf((*MockStore)(nil).CreateOrders), ctx, userID, orderIDs)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockStore) CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockStoreMockRecorder) CreateOutboxEvent(ctx, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockStore)(nil).CreateOutboxEvent), ctx, eventType, payload)
+}
+
+// CreateReferral mocks base method.
+func (m *MockStore) CreateReferral(ctx context.Context, referrerID, refereeID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferral", ctx, referrerID, refereeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReferral indicates an expected call of CreateReferral.
+func (mr *MockStoreMockRecorder) CreateReferral(ctx, referrerID, refereeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferral", reflect.TypeOf((*MockStore)(nil).CreateReferral), ctx, referrerID, refereeID)
+}
+
+// CreateTierHistory mocks base method.
+func (m *MockStore) CreateTierHistory(ctx context.Context, userID int64, oldTier, newTier string, volume float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTierHistory", ctx, userID, oldTier, newTier, volume)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTierHistory indicates an expected call of CreateTierHistory.
+func (mr *MockStoreMockRecorder) CreateTierHistory(ctx, userID, oldTier, newTier, volume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTierHistory", reflect.TypeOf((*MockStore)(nil).CreateTierHistory), ctx, userID, oldTier, newTier, volume)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(ctx context.Context, senderID, recipientID int64, sum float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, senderID, recipientID, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(ctx, senderID, recipientID, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), ctx, senderID, recipientID, sum)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(ctx context.Context, login, password, referralCode string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, login, password, referralCode)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(ctx, login, password, referralCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), ctx, login, password, referralCode)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockStore) CreateWebhook(ctx context.Context, userID int64, url, secret string, events []string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, secret, events)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockStoreMockRecorder) CreateWebhook(ctx, userID, url, secret, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockStore)(nil).CreateWebhook), ctx, userID, url, secret, events)
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockStore) CreateWebhookDeliveries(ctx context.Context, userID int64, event string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ctx, userID, event, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockStoreMockRecorder) CreateWebhookDeliveries(ctx, userID, event, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).CreateWebhookDeliveries), ctx, userID, event, payload)
+}
+
+// CreateWithdraw mocks base method.
+func (m *MockStore) CreateWithdraw(ctx context.Context, userID, orderID int64, sum float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdraw", ctx, userID, orderID, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithdraw indicates an expected call of CreateWithdraw.
+func (mr *MockStoreMockRecorder) CreateWithdraw(ctx, userID, orderID, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockStore)(nil).CreateWithdraw), ctx, userID, orderID, sum)
+}
+
+// DeleteDispatchedOutboxEvents mocks base method.
+func (m *MockStore) DeleteDispatchedOutboxEvents(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDispatchedOutboxEvents", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDispatchedOutboxEvents indicates an expected call of DeleteDispatchedOutboxEvents.
+func (mr *MockStoreMockRecorder) DeleteDispatchedOutboxEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDispatchedOutboxEvents", reflect.TypeOf((*MockStore)(nil).DeleteDispatchedOutboxEvents), ctx, before)
+}
+
+// DeleteExpiredRateLimits mocks base method.
+func (m *MockStore) DeleteExpiredRateLimits(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRateLimits", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRateLimits indicates an expected call of DeleteExpiredRateLimits.
+func (mr *MockStoreMockRecorder) DeleteExpiredRateLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRateLimits", reflect.TypeOf((*MockStore)(nil).DeleteExpiredRateLimits), ctx)
+}
+
+// DeleteUserEvents mocks base method.
+func (m *MockStore) DeleteUserEvents(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserEvents", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserEvents indicates an expected call of DeleteUserEvents.
+func (mr *MockStoreMockRecorder) DeleteUserEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEvents", reflect.TypeOf((*MockStore)(nil).DeleteUserEvents), ctx, before)
+}
+
+// DisableWebhook mocks base method.
+func (m *MockStore) DisableWebhook(ctx context.Context, webhookID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableWebhook indicates an expected call of DisableWebhook.
+func (mr *MockStoreMockRecorder) DisableWebhook(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableWebhook", reflect.TypeOf((*MockStore)(nil).DisableWebhook), ctx, webhookID)
+}
+
+// EndCampaign mocks base method.
+func (m *MockStore) EndCampaign(ctx context.Context, campaignID int64, endsAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndCampaign", ctx, campaignID, endsAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndCampaign indicates an expected call of EndCampaign.
+func (mr *MockStoreMockRecorder) EndCampaign(ctx, campaignID, endsAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndCampaign", reflect.TypeOf((*MockStore)(nil).EndCampaign), ctx, campaignID, endsAt)
+}
+
+// GetAccrualVolumes mocks base method.
+func (m *MockStore) GetAccrualVolumes(ctx context.Context, since time.Time) ([]models.UserVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccrualVolumes", ctx, since)
+	ret0, _ := ret[0].([]models.UserVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccrualVolumes indicates an expected call of GetAccrualVolumes.
+func (mr *MockStoreMockRecorder) GetAccrualVolumes(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccrualVolumes", reflect.TypeOf((*MockStore)(nil).GetAccrualVolumes), ctx, since)
+}
+
+// GetActiveCampaigns mocks base method.
+func (m *MockStore) GetActiveCampaigns(ctx context.Context, at time.Time) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveCampaigns", ctx, at)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveCampaigns indicates an expected call of GetActiveCampaigns.
+func (mr *MockStoreMockRecorder) GetActiveCampaigns(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCampaigns", reflect.TypeOf((*MockStore)(nil).GetActiveCampaigns), ctx, at)
+}
+
+// GetBalanceMismatches mocks base method.
+func (m *MockStore) GetBalanceMismatches(ctx context.Context, tolerance float64) ([]models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceMismatches", ctx, tolerance)
+	ret0, _ := ret[0].([]models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceMismatches indicates an expected call of GetBalanceMismatches.
+func (mr *MockStoreMockRecorder) GetBalanceMismatches(ctx, tolerance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceMismatches", reflect.TypeOf((*MockStore)(nil).GetBalanceMismatches), ctx, tolerance)
+}
+
+// GetCampaign mocks base method.
+func (m *MockStore) GetCampaign(ctx context.Context, campaignID int64, forUpdate bool) (models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaign", ctx, campaignID, forUpdate)
+	ret0, _ := ret[0].(models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaign indicates an expected call of GetCampaign.
+func (mr *MockStoreMockRecorder) GetCampaign(ctx, campaignID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaign", reflect.TypeOf((*MockStore)(nil).GetCampaign), ctx, campaignID, forUpdate)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockStore) GetCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockStoreMockRecorder) GetCampaigns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockStore)(nil).GetCampaigns), ctx)
+}
+
+// GetExpiredHolds mocks base method.
+func (m *MockStore) GetExpiredHolds(ctx context.Context, now time.Time, limit int64) ([]models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHolds", ctx, now, limit)
+	ret0, _ := ret[0].([]models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHolds indicates an expected call of GetExpiredHolds.
+func (mr *MockStoreMockRecorder) GetExpiredHolds(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHolds", reflect.TypeOf((*MockStore)(nil).GetExpiredHolds), ctx, now, limit)
+}
+
+// GetHold mocks base method.
+func (m *MockStore) GetHold(ctx context.Context, holdID int64, forUpdate bool) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID, forUpdate)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockStoreMockRecorder) GetHold(ctx, holdID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockStore)(nil).GetHold), ctx, holdID, forUpdate)
+}
+
+// GetLastUserEventID mocks base method.
+func (m *MockStore) GetLastUserEventID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastUserEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastUserEventID indicates an expected call of GetLastUserEventID.
+func (mr *MockStoreMockRecorder) GetLastUserEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUserEventID", reflect.TypeOf((*MockStore)(nil).GetLastUserEventID), ctx, userID)
+}
+
+// GetLoyaltyTier mocks base method.
+func (m *MockStore) GetLoyaltyTier(ctx context.Context, name string) (models.LoyaltyTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyaltyTier", ctx, name)
+	ret0, _ := ret[0].(models.LoyaltyTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoyaltyTier indicates an expected call of GetLoyaltyTier.
+func (mr *MockStoreMockRecorder) GetLoyaltyTier(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyaltyTier", reflect.TypeOf((*MockStore)(nil).GetLoyaltyTier), ctx, name)
+}
+
+// GetLoyaltyTiers mocks base method.
+func (m *MockStore) GetLoyaltyTiers(ctx context.Context) ([]models.LoyaltyTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyaltyTiers", ctx)
+	ret0, _ := ret[0].([]models.LoyaltyTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoyaltyTiers indicates an expected call of GetLoyaltyTiers.
+func (mr *MockStoreMockRecorder) GetLoyaltyTiers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyaltyTiers", reflect.TypeOf((*MockStore)(nil).GetLoyaltyTiers), ctx)
+}
+
+// GetNotProcessedOrders mocks base method.
+func (m *MockStore) GetNotProcessedOrders(ctx context.Context) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotProcessedOrders", ctx)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotProcessedOrders indicates an expected call of GetNotProcessedOrders.
+func (mr *MockStoreMockRecorder) GetNotProcessedOrders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotProcessedOrders", reflect.TypeOf((*MockStore)(nil).GetNotProcessedOrders), ctx)
+}
+
+// GetOrder mocks base method.
+func (m *MockStore) GetOrder(ctx context.Context, orderID int64, forUpdate bool) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, orderID, forUpdate)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockStoreMockRecorder) GetOrder(ctx, orderID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockStore)(nil).GetOrder), ctx, orderID, forUpdate)
+}
+
+// GetOrderOwners mocks base method.
+func (m *MockStore) GetOrderOwners(ctx context.Context, orderIDs []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderOwners", ctx, orderIDs)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderOwners indicates an expected call of GetOrderOwners.
+func (mr *MockStoreMockRecorder) GetOrderOwners(ctx, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderOwners", reflect.TypeOf((*MockStore)(nil).GetOrderOwners), ctx, orderIDs)
+}
+
+// GetOrderStatusHistory mocks base method.
+func (m *MockStore) GetOrderStatusHistory(ctx context.Context, orderID int64) ([]models.OrderStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatusHistory", ctx, orderID)
+	ret0, _ := ret[0].([]models.OrderStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatusHistory indicates an expected call of GetOrderStatusHistory.
+func (mr *MockStoreMockRecorder) GetOrderStatusHistory(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatusHistory", reflect.TypeOf((*MockStore)(nil).GetOrderStatusHistory), ctx, orderID)
+}
+
+// GetOrders mocks base method.
+func (m *MockStore) GetOrders(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrders", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrders indicates an expected call of GetOrders.
+func (mr *MockStoreMockRecorder) GetOrders(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockStore)(nil).GetOrders), ctx, userID, filter)
+}
+
+// GetReferralByReferee mocks base method.
+func (m *MockStore) GetReferralByReferee(ctx context.Context, refereeID int64, forUpdate bool) (models.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralByReferee", ctx, refereeID, forUpdate)
+	ret0, _ := ret[0].(models.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralByReferee indicates an expected call of GetReferralByReferee.
+func (mr *MockStoreMockRecorder) GetReferralByReferee(ctx, refereeID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralByReferee", reflect.TypeOf((*MockStore)(nil).GetReferralByReferee), ctx, refereeID, forUpdate)
+}
+
+// GetReferrals mocks base method.
+func (m *MockStore) GetReferrals(ctx context.Context, referrerID int64) ([]models.ReferralInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, referrerID)
+	ret0, _ := ret[0].([]models.ReferralInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockStoreMockRecorder) GetReferrals(ctx, referrerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockStore)(nil).GetReferrals), ctx, referrerID)
+}
+
+// GetSentTransfersStat mocks base method.
+func (m *MockStore) GetSentTransfersStat(ctx context.Context, senderID int64, since time.Time) (int64, float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentTransfersStat", ctx, senderID, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSentTransfersStat indicates an expected call of GetSentTransfersStat.
+func (mr *MockStoreMockRecorder) GetSentTransfersStat(ctx, senderID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTransfersStat", reflect.TypeOf((*MockStore)(nil).GetSentTransfersStat), ctx, senderID, since)
+}
+
+// GetStatement mocks base method.
+func (m *MockStore) GetStatement(ctx context.Context, userID int64, from, to time.Time, after models.StatementCursor, limit int64, fn func(models.StatementEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, userID, from, to, after, limit, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockStoreMockRecorder) GetStatement(ctx, userID, from, to, after, limit, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockStore)(nil).GetStatement), ctx, userID, from, to, after, limit, fn)
+}
+
+// GetUserAccrualVolume mocks base method.
+func (m *MockStore) GetUserAccrualVolume(ctx context.Context, userID int64, since time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccrualVolume", ctx, userID, since)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccrualVolume indicates an expected call of GetUserAccrualVolume.
+func (mr *MockStoreMockRecorder) GetUserAccrualVolume(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccrualVolume", reflect.TypeOf((*MockStore)(nil).GetUserAccrualVolume), ctx, userID, since)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(ctx context.Context, id int64, forUpdate bool) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id, forUpdate)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(ctx, id, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), ctx, id, forUpdate)
+}
+
+// GetUserByLogin mocks base method.
+func (m *MockStore) GetUserByLogin(ctx context.Context, login string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, login)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockStoreMockRecorder) GetUserByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockStore)(nil).GetUserByLogin), ctx, login)
+}
+
+// GetUserByReferralCode mocks base method.
+func (m *MockStore) GetUserByReferralCode(ctx context.Context, code string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByReferralCode", ctx, code)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByReferralCode indicates an expected call of GetUserByReferralCode.
+func (mr *MockStoreMockRecorder) GetUserByReferralCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByReferralCode", reflect.TypeOf((*MockStore)(nil).GetUserByReferralCode), ctx, code)
+}
+
+// GetUserEvents mocks base method.
+func (m *MockStore) GetUserEvents(ctx context.Context, userID, afterID int64, since time.Time, limit int64) ([]models.UserEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID, afterID, since, limit)
+	ret0, _ := ret[0].([]models.UserEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockStoreMockRecorder) GetUserEvents(ctx, userID, afterID, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockStore)(nil).GetUserEvents), ctx, userID, afterID, since, limit)
+}
+
+// GetUserLedger mocks base method.
+func (m *MockStore) GetUserLedger(ctx context.Context, userID int64) (models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLedger", ctx, userID)
+	ret0, _ := ret[0].(models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLedger indicates an expected call of GetUserLedger.
+func (mr *MockStoreMockRecorder) GetUserLedger(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLedger", reflect.TypeOf((*MockStore)(nil).GetUserLedger), ctx, userID)
+}
+
+// GetUserVersion mocks base method.
+func (m *MockStore) GetUserVersion(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserVersion", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVersion indicates an expected call of GetUserVersion.
+func (mr *MockStoreMockRecorder) GetUserVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVersion", reflect.TypeOf((*MockStore)(nil).GetUserVersion), ctx, userID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockStore) GetWebhook(ctx context.Context, webhookID int64) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockStoreMockRecorder) GetWebhook(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockStore)(nil).GetWebhook), ctx, webhookID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockStore) GetWebhookDeliveries(ctx context.Context, webhookID, limit int64) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveries(ctx, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveries), ctx, webhookID, limit)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockStore) GetWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockStoreMockRecorder) GetWebhooks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockStore)(nil).GetWebhooks), ctx, userID)
+}
+
+// GetWithdrawStat mocks base method.
+func (m *MockStore) GetWithdrawStat(ctx context.Context, userID int64, hourFrom, dayFrom, monthFrom time.Time) (models.WithdrawStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawStat", ctx, userID, hourFrom, dayFrom, monthFrom)
+	ret0, _ := ret[0].(models.WithdrawStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawStat indicates an expected call of GetWithdrawStat.
+func (mr *MockStoreMockRecorder) GetWithdrawStat(ctx, userID, hourFrom, dayFrom, monthFrom interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawStat", reflect.TypeOf((*MockStore)(nil).GetWithdrawStat), ctx, userID, hourFrom, dayFrom, monthFrom)
+}
+
+// GetWithdrawals mocks base method.
+func (m *MockStore) GetWithdrawals(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Withdraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.Withdraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawals indicates an expected call of GetWithdrawals.
+func (mr *MockStoreMockRecorder) GetWithdrawals(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockStore)(nil).GetWithdrawals), ctx, userID, filter)
+}
+
+// MarkOutboxEventsDispatched mocks base method.
+func (m *MockStore) MarkOutboxEventsDispatched(ctx context.Context, ids []int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventsDispatched", ctx, ids, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventsDispatched indicates an expected call of MarkOutboxEventsDispatched.
+func (mr *MockStoreMockRecorder) MarkOutboxEventsDispatched(ctx, ids, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventsDispatched", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventsDispatched), ctx, ids, at)
+}
+
+// RequeueOrders mocks base method.
+func (m *MockStore) RequeueOrders(ctx context.Context, statuses []string, updatedBefore time.Time) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueOrders", ctx, statuses, updatedBefore)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueOrders indicates an expected call of RequeueOrders.
+func (mr *MockStoreMockRecorder) RequeueOrders(ctx, statuses, updatedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueOrders", reflect.TypeOf((*MockStore)(nil).RequeueOrders), ctx, statuses, updatedBefore)
+}
+
+// RequeueWebhookDelivery mocks base method.
+func (m *MockStore) RequeueWebhookDelivery(ctx context.Context, webhookID, deliveryID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueWebhookDelivery indicates an expected call of RequeueWebhookDelivery.
+func (mr *MockStoreMockRecorder) RequeueWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueWebhookDelivery", reflect.TypeOf((*MockStore)(nil).RequeueWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// RewardReferral mocks base method.
+func (m *MockStore) RewardReferral(ctx context.Context, referralID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewardReferral", ctx, referralID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewardReferral indicates an expected call of RewardReferral.
+func (mr *MockStoreMockRecorder) RewardReferral(ctx, referralID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewardReferral", reflect.TypeOf((*MockStore)(nil).RewardReferral), ctx, referralID)
+}
+
+// TakeRateLimit mocks base method.
+func (m *MockStore) TakeRateLimit(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeRateLimit", ctx, key, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TakeRateLimit indicates an expected call of TakeRateLimit.
+func (mr *MockStoreMockRecorder) TakeRateLimit(ctx, key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeRateLimit", reflect.TypeOf((*MockStore)(nil).TakeRateLimit), ctx, key, window)
+}
+
+// UpdateHoldStatus mocks base method.
+func (m *MockStore) UpdateHoldStatus(ctx context.Context, holdID int64, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHoldStatus", ctx, holdID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHoldStatus indicates an expected call of UpdateHoldStatus.
+func (mr *MockStoreMockRecorder) UpdateHoldStatus(ctx, holdID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHoldStatus", reflect.TypeOf((*MockStore)(nil).UpdateHoldStatus), ctx, holdID, status)
+}
+
+// UpdateOrder mocks base method.
+func (m *MockStore) UpdateOrder(ctx context.Context, orderID int64, accrual float64, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrder", ctx, orderID, accrual, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrder indicates an expected call of UpdateOrder.
+func (mr *MockStoreMockRecorder) UpdateOrder(ctx, orderID, accrual, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockStore)(nil).UpdateOrder), ctx, orderID, accrual, status)
+}
+
+// UpdateUserBalance mocks base method.
+func (m *MockStore) UpdateUserBalance(ctx context.Context, userID int64, balance, withdrawn float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserBalance", ctx, userID, balance, withdrawn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserBalance indicates an expected call of UpdateUserBalance.
+func (mr *MockStoreMockRecorder) UpdateUserBalance(ctx, userID, balance, withdrawn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockStore)(nil).UpdateUserBalance), ctx, userID, balance, withdrawn)
+}
+
+// UpdateUserHeld mocks base method.
+func (m *MockStore) UpdateUserHeld(ctx context.Context, userID int64, held float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserHeld", ctx, userID, held)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserHeld indicates an expected call of UpdateUserHeld.
+func (mr *MockStoreMockRecorder) UpdateUserHeld(ctx, userID, held interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserHeld", reflect.TypeOf((*MockStore)(nil).UpdateUserHeld), ctx, userID, held)
+}
+
+// UpdateUserTier mocks base method.
+func (m *MockStore) UpdateUserTier(ctx context.Context, userID int64, tier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTier", ctx, userID, tier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserTier indicates an expected call of UpdateUserTier.
+func (mr *MockStoreMockRecorder) UpdateUserTier(ctx, userID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTier", reflect.TypeOf((*MockStore)(nil).UpdateUserTier), ctx, userID, tier)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockStore) UpdateWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockStoreMockRecorder) UpdateWebhookDelivery(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).UpdateWebhookDelivery), ctx, d)
+}
+
+// MockUserStore is a mock of UserStore interface.
+type MockUserStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserStoreMockRecorder
+}
+
+// MockUserStoreMockRecorder is the mock recorder for MockUserStore.
+type MockUserStoreMockRecorder struct {
+	mock *MockUserStore
+}
+
+// NewMockUserStore creates a new mock instance.
+func NewMockUserStore(ctrl *gomock.Controller) *MockUserStore {
+	mock := &MockUserStore{ctrl: ctrl}
+	mock.recorder = &MockUserStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserStore) EXPECT() *MockUserStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method.
+func (m *MockUserStore) CreateUser(ctx context.Context, login, password, referralCode string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, login, password, referralCode)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockUserStoreMockRecorder) CreateUser(ctx, login, password, referralCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserStore)(nil).CreateUser), ctx, login, password, referralCode)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserStore) GetUserByID(ctx context.Context, id int64, forUpdate bool) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id, forUpdate)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserStoreMockRecorder) GetUserByID(ctx, id, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserStore)(nil).GetUserByID), ctx, id, forUpdate)
+}
+
+// GetUserByLogin mocks base method.
+func (m *MockUserStore) GetUserByLogin(ctx context.Context, login string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, login)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockUserStoreMockRecorder) GetUserByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserStore)(nil).GetUserByLogin), ctx, login)
+}
+
+// GetUserByReferralCode mocks base method.
+func (m *MockUserStore) GetUserByReferralCode(ctx context.Context, code string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByReferralCode", ctx, code)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByReferralCode indicates an expected call of GetUserByReferralCode.
+func (mr *MockUserStoreMockRecorder) GetUserByReferralCode(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByReferralCode", reflect.TypeOf((*MockUserStore)(nil).GetUserByReferralCode), ctx, code)
+}
+
+// GetUserVersion mocks base method.
+func (m *MockUserStore) GetUserVersion(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserVersion", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVersion indicates an expected call of GetUserVersion.
+func (mr *MockUserStoreMockRecorder) GetUserVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVersion", reflect.TypeOf((*MockUserStore)(nil).GetUserVersion), ctx, userID)
+}
+
+// UpdateUserBalance mocks base method.
+func (m *MockUserStore) UpdateUserBalance(ctx context.Context, userID int64, balance, withdrawn float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserBalance", ctx, userID, balance, withdrawn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserBalance indicates an expected call of UpdateUserBalance.
+func (mr *MockUserStoreMockRecorder) UpdateUserBalance(ctx, userID, balance, withdrawn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockUserStore)(nil).UpdateUserBalance), ctx, userID, balance, withdrawn)
+}
+
+// UpdateUserHeld mocks base method.
+func (m *MockUserStore) UpdateUserHeld(ctx context.Context, userID int64, held float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserHeld", ctx, userID, held)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserHeld indicates an expected call of UpdateUserHeld.
+func (mr *MockUserStoreMockRecorder) UpdateUserHeld(ctx, userID, held interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserHeld", reflect.TypeOf((*MockUserStore)(nil).UpdateUserHeld), ctx, userID, held)
+}
+
+// UpdateUserTier mocks base method.
+func (m *MockUserStore) UpdateUserTier(ctx context.Context, userID int64, tier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTier", ctx, userID, tier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserTier indicates an expected call of UpdateUserTier.
+func (mr *MockUserStoreMockRecorder) UpdateUserTier(ctx, userID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTier", reflect.TypeOf((*MockUserStore)(nil).UpdateUserTier), ctx, userID, tier)
+}
+
+// MockOrderStore is a mock of OrderStore interface.
+type MockOrderStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderStoreMockRecorder
+}
+
+// MockOrderStoreMockRecorder is the mock recorder for MockOrderStore.
+type MockOrderStoreMockRecorder struct {
+	mock *MockOrderStore
+}
+
+// NewMockOrderStore creates a new mock instance.
+func NewMockOrderStore(ctrl *gomock.Controller) *MockOrderStore {
+	mock := &MockOrderStore{ctrl: ctrl}
+	mock.recorder = &MockOrderStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderStore) EXPECT() *MockOrderStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateOrder mocks base method.
+func (m *MockOrderStore) CreateOrder(ctx context.Context, userID, orderID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, userID, orderID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockOrderStoreMockRecorder) CreateOrder(ctx, userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderStore)(nil).CreateOrder), ctx, userID, orderID)
+}
+
+// CreateOrderStatus mocks base method.
+func (m *MockOrderStore) CreateOrderStatus(ctx context.Context, orderID int64, status string, accrual float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrderStatus", ctx, orderID, status, accrual)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrderStatus indicates an expected call of CreateOrderStatus.
+func (mr *MockOrderStoreMockRecorder) CreateOrderStatus(ctx, orderID, status, accrual interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderStatus", reflect.TypeOf((*MockOrderStore)(nil).CreateOrderStatus), ctx, orderID, status, accrual)
+}
+
+// CreateOrders mocks base method.
+func (m *MockOrderStore) CreateOrders(ctx context.Context, userID int64, orderIDs []int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrders", ctx, userID, orderIDs)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrders indicates an expected call of CreateOrders.
+func (mr *MockOrderStoreMockRecorder) CreateOrders(ctx, userID, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrders", reflect.TypeOf((*MockOrderStore)(nil).CreateOrders), ctx, userID, orderIDs)
+}
+
+// GetNotProcessedOrders mocks base method.
+func (m *MockOrderStore) GetNotProcessedOrders(ctx context.Context) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotProcessedOrders", ctx)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotProcessedOrders indicates an expected call of GetNotProcessedOrders.
+func (mr *MockOrderStoreMockRecorder) GetNotProcessedOrders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotProcessedOrders", reflect.TypeOf((*MockOrderStore)(nil).GetNotProcessedOrders), ctx)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderStore) GetOrder(ctx context.Context, orderID int64, forUpdate bool) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, orderID, forUpdate)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderStoreMockRecorder) GetOrder(ctx, orderID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderStore)(nil).GetOrder), ctx, orderID, forUpdate)
+}
+
+// GetOrderOwners mocks base method.
+func (m *MockOrderStore) GetOrderOwners(ctx context.Context, orderIDs []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderOwners", ctx, orderIDs)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderOwners indicates an expected call of GetOrderOwners.
+func (mr *MockOrderStoreMockRecorder) GetOrderOwners(ctx, orderIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderOwners", reflect.TypeOf((*MockOrderStore)(nil).GetOrderOwners), ctx, orderIDs)
+}
+
+// GetOrderStatusHistory mocks base method.
+func (m *MockOrderStore) GetOrderStatusHistory(ctx context.Context, orderID int64) ([]models.OrderStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatusHistory", ctx, orderID)
+	ret0, _ := ret[0].([]models.OrderStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatusHistory indicates an expected call of GetOrderStatusHistory.
+func (mr *MockOrderStoreMockRecorder) GetOrderStatusHistory(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatusHistory", reflect.TypeOf((*MockOrderStore)(nil).GetOrderStatusHistory), ctx, orderID)
+}
+
+// GetOrders mocks base method.
+func (m *MockOrderStore) GetOrders(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrders", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrders indicates an expected call of GetOrders.
+func (mr *MockOrderStoreMockRecorder) GetOrders(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockOrderStore)(nil).GetOrders), ctx, userID, filter)
+}
+
+// RequeueOrders mocks base method.
+func (m *MockOrderStore) RequeueOrders(ctx context.Context, statuses []string, updatedBefore time.Time) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueOrders", ctx, statuses, updatedBefore)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueOrders indicates an expected call of RequeueOrders.
+func (mr *MockOrderStoreMockRecorder) RequeueOrders(ctx, statuses, updatedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueOrders", reflect.TypeOf((*MockOrderStore)(nil).RequeueOrders), ctx, statuses, updatedBefore)
+}
+
+// UpdateOrder mocks base method.
+func (m *MockOrderStore) UpdateOrder(ctx context.Context, orderID int64, accrual float64, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrder", ctx, orderID, accrual, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrder indicates an expected call of UpdateOrder.
+func (mr *MockOrderStoreMockRecorder) UpdateOrder(ctx, orderID, accrual, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderStore)(nil).UpdateOrder), ctx, orderID, accrual, status)
+}
+
+// MockWithdrawStore is a mock of WithdrawStore interface.
+type MockWithdrawStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWithdrawStoreMockRecorder
+}
+
+// MockWithdrawStoreMockRecorder is the mock recorder for MockWithdrawStore.
+type MockWithdrawStoreMockRecorder struct {
+	mock *MockWithdrawStore
+}
+
+// NewMockWithdrawStore creates a new mock instance.
+func NewMockWithdrawStore(ctrl *gomock.Controller) *MockWithdrawStore {
+	mock := &MockWithdrawStore{ctrl: ctrl}
+	mock.recorder = &MockWithdrawStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWithdrawStore) EXPECT() *MockWithdrawStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateHold mocks base method.
+func (m *MockWithdrawStore) CreateHold(ctx context.Context, userID, orderID int64, sum float64, expiresAt time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, userID, orderID, sum, expiresAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockWithdrawStoreMockRecorder) CreateHold(ctx, userID, orderID, sum, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockWithdrawStore)(nil).CreateHold), ctx, userID, orderID, sum, expiresAt)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockWithdrawStore) CreateTransfer(ctx context.Context, senderID, recipientID int64, sum float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", ctx, senderID, recipientID, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockWithdrawStoreMockRecorder) CreateTransfer(ctx, senderID, recipientID, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockWithdrawStore)(nil).CreateTransfer), ctx, senderID, recipientID, sum)
+}
+
+// CreateWithdraw mocks base method.
+func (m *MockWithdrawStore) CreateWithdraw(ctx context.Context, userID, orderID int64, sum float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithdraw", ctx, userID, orderID, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithdraw indicates an expected call of CreateWithdraw.
+func (mr *MockWithdrawStoreMockRecorder) CreateWithdraw(ctx, userID, orderID, sum interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithdraw", reflect.TypeOf((*MockWithdrawStore)(nil).CreateWithdraw), ctx, userID, orderID, sum)
+}
+
+// GetExpiredHolds mocks base method.
+func (m *MockWithdrawStore) GetExpiredHolds(ctx context.Context, now time.Time, limit int64) ([]models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHolds", ctx, now, limit)
+	ret0, _ := ret[0].([]models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHolds indicates an expected call of GetExpiredHolds.
+func (mr *MockWithdrawStoreMockRecorder) GetExpiredHolds(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHolds", reflect.TypeOf((*MockWithdrawStore)(nil).GetExpiredHolds), ctx, now, limit)
+}
+
+// GetHold mocks base method.
+func (m *MockWithdrawStore) GetHold(ctx context.Context, holdID int64, forUpdate bool) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID, forUpdate)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockWithdrawStoreMockRecorder) GetHold(ctx, holdID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockWithdrawStore)(nil).GetHold), ctx, holdID, forUpdate)
+}
+
+// GetSentTransfersStat mocks base method.
+func (m *MockWithdrawStore) GetSentTransfersStat(ctx context.Context, senderID int64, since time.Time) (int64, float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentTransfersStat", ctx, senderID, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(float64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSentTransfersStat indicates an expected call of GetSentTransfersStat.
+func (mr *MockWithdrawStoreMockRecorder) GetSentTransfersStat(ctx, senderID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentTransfersStat", reflect.TypeOf((*MockWithdrawStore)(nil).GetSentTransfersStat), ctx, senderID, since)
+}
+
+// GetWithdrawStat mocks base method.
+func (m *MockWithdrawStore) GetWithdrawStat(ctx context.Context, userID int64, hourFrom, dayFrom, monthFrom time.Time) (models.WithdrawStat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawStat", ctx, userID, hourFrom, dayFrom, monthFrom)
+	ret0, _ := ret[0].(models.WithdrawStat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawStat indicates an expected call of GetWithdrawStat.
+func (mr *MockWithdrawStoreMockRecorder) GetWithdrawStat(ctx, userID, hourFrom, dayFrom, monthFrom interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawStat", reflect.TypeOf((*MockWithdrawStore)(nil).GetWithdrawStat), ctx, userID, hourFrom, dayFrom, monthFrom)
+}
+
+// GetWithdrawals mocks base method.
+func (m *MockWithdrawStore) GetWithdrawals(ctx context.Context, userID int64, filter models.ListFilter) ([]models.Withdraw, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawals", ctx, userID, filter)
+	ret0, _ := ret[0].([]models.Withdraw)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawals indicates an expected call of GetWithdrawals.
+func (mr *MockWithdrawStoreMockRecorder) GetWithdrawals(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockWithdrawStore)(nil).GetWithdrawals), ctx, userID, filter)
+}
+
+// UpdateHoldStatus mocks base method.
+func (m *MockWithdrawStore) UpdateHoldStatus(ctx context.Context, holdID int64, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHoldStatus", ctx, holdID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHoldStatus indicates an expected call of UpdateHoldStatus.
+func (mr *MockWithdrawStoreMockRecorder) UpdateHoldStatus(ctx, holdID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHoldStatus", reflect.TypeOf((*MockWithdrawStore)(nil).UpdateHoldStatus), ctx, holdID, status)
+}
+
+// MockLoyaltyStore is a mock of LoyaltyStore interface.
+type MockLoyaltyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoyaltyStoreMockRecorder
+}
+
+// MockLoyaltyStoreMockRecorder is the mock recorder for MockLoyaltyStore.
+type MockLoyaltyStoreMockRecorder struct {
+	mock *MockLoyaltyStore
+}
+
+// NewMockLoyaltyStore creates a new mock instance.
+func NewMockLoyaltyStore(ctrl *gomock.Controller) *MockLoyaltyStore {
+	mock := &MockLoyaltyStore{ctrl: ctrl}
+	mock.recorder = &MockLoyaltyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoyaltyStore) EXPECT() *MockLoyaltyStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateBonus mocks base method.
+func (m *MockLoyaltyStore) CreateBonus(ctx context.Context, userID, orderID int64, source string, amount float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBonus", ctx, userID, orderID, source, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBonus indicates an expected call of CreateBonus.
+func (mr *MockLoyaltyStoreMockRecorder) CreateBonus(ctx, userID, orderID, source, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBonus", reflect.TypeOf((*MockLoyaltyStore)(nil).CreateBonus), ctx, userID, orderID, source, amount)
+}
+
+// CreateTierHistory mocks base method.
+func (m *MockLoyaltyStore) CreateTierHistory(ctx context.Context, userID int64, oldTier, newTier string, volume float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTierHistory", ctx, userID, oldTier, newTier, volume)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTierHistory indicates an expected call of CreateTierHistory.
+func (mr *MockLoyaltyStoreMockRecorder) CreateTierHistory(ctx, userID, oldTier, newTier, volume interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTierHistory", reflect.TypeOf((*MockLoyaltyStore)(nil).CreateTierHistory), ctx, userID, oldTier, newTier, volume)
+}
+
+// GetAccrualVolumes mocks base method.
+func (m *MockLoyaltyStore) GetAccrualVolumes(ctx context.Context, since time.Time) ([]models.UserVolume, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccrualVolumes", ctx, since)
+	ret0, _ := ret[0].([]models.UserVolume)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccrualVolumes indicates an expected call of GetAccrualVolumes.
+func (mr *MockLoyaltyStoreMockRecorder) GetAccrualVolumes(ctx, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccrualVolumes", reflect.TypeOf((*MockLoyaltyStore)(nil).GetAccrualVolumes), ctx, since)
+}
+
+// GetLoyaltyTier mocks base method.
+func (m *MockLoyaltyStore) GetLoyaltyTier(ctx context.Context, name string) (models.LoyaltyTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyaltyTier", ctx, name)
+	ret0, _ := ret[0].(models.LoyaltyTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoyaltyTier indicates an expected call of GetLoyaltyTier.
+func (mr *MockLoyaltyStoreMockRecorder) GetLoyaltyTier(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyaltyTier", reflect.TypeOf((*MockLoyaltyStore)(nil).GetLoyaltyTier), ctx, name)
+}
+
+// GetLoyaltyTiers mocks base method.
+func (m *MockLoyaltyStore) GetLoyaltyTiers(ctx context.Context) ([]models.LoyaltyTier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyaltyTiers", ctx)
+	ret0, _ := ret[0].([]models.LoyaltyTier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoyaltyTiers indicates an expected call of GetLoyaltyTiers.
+func (mr *MockLoyaltyStoreMockRecorder) GetLoyaltyTiers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyaltyTiers", reflect.TypeOf((*MockLoyaltyStore)(nil).GetLoyaltyTiers), ctx)
+}
+
+// GetUserAccrualVolume mocks base method.
+func (m *MockLoyaltyStore) GetUserAccrualVolume(ctx context.Context, userID int64, since time.Time) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccrualVolume", ctx, userID, since)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccrualVolume indicates an expected call of GetUserAccrualVolume.
+func (mr *MockLoyaltyStoreMockRecorder) GetUserAccrualVolume(ctx, userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccrualVolume", reflect.TypeOf((*MockLoyaltyStore)(nil).GetUserAccrualVolume), ctx, userID, since)
+}
+
+// MockReferralStore is a mock of ReferralStore interface.
+type MockReferralStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReferralStoreMockRecorder
+}
+
+// MockReferralStoreMockRecorder is the mock recorder for MockReferralStore.
+type MockReferralStoreMockRecorder struct {
+	mock *MockReferralStore
+}
+
+// NewMockReferralStore creates a new mock instance.
+func NewMockReferralStore(ctrl *gomock.Controller) *MockReferralStore {
+	mock := &MockReferralStore{ctrl: ctrl}
+	mock.recorder = &MockReferralStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReferralStore) EXPECT() *MockReferralStoreMockRecorder {
+	return m.recorder
+}
+
+// CountReferrals mocks base method.
+func (m *MockReferralStore) CountReferrals(ctx context.Context, referrerID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReferrals", ctx, referrerID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReferrals indicates an expected call of CountReferrals.
+func (mr *MockReferralStoreMockRecorder) CountReferrals(ctx, referrerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReferrals", reflect.TypeOf((*MockReferralStore)(nil).CountReferrals), ctx, referrerID)
+}
+
+// CreateReferral mocks base method.
+func (m *MockReferralStore) CreateReferral(ctx context.Context, referrerID, refereeID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferral", ctx, referrerID, refereeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateReferral indicates an expected call of CreateReferral.
+func (mr *MockReferralStoreMockRecorder) CreateReferral(ctx, referrerID, refereeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferral", reflect.TypeOf((*MockReferralStore)(nil).CreateReferral), ctx, referrerID, refereeID)
+}
+
+// GetReferralByReferee mocks base method.
+func (m *MockReferralStore) GetReferralByReferee(ctx context.Context, refereeID int64, forUpdate bool) (models.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralByReferee", ctx, refereeID, forUpdate)
+	ret0, _ := ret[0].(models.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralByReferee indicates an expected call of GetReferralByReferee.
+func (mr *MockReferralStoreMockRecorder) GetReferralByReferee(ctx, refereeID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralByReferee", reflect.TypeOf((*MockReferralStore)(nil).GetReferralByReferee), ctx, refereeID, forUpdate)
+}
+
+// GetReferrals mocks base method.
+func (m *MockReferralStore) GetReferrals(ctx context.Context, referrerID int64) ([]models.ReferralInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, referrerID)
+	ret0, _ := ret[0].([]models.ReferralInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockReferralStoreMockRecorder) GetReferrals(ctx, referrerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockReferralStore)(nil).GetReferrals), ctx, referrerID)
+}
+
+// RewardReferral mocks base method.
+func (m *MockReferralStore) RewardReferral(ctx context.Context, referralID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewardReferral", ctx, referralID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewardReferral indicates an expected call of RewardReferral.
+func (mr *MockReferralStoreMockRecorder) RewardReferral(ctx, referralID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewardReferral", reflect.TypeOf((*MockReferralStore)(nil).RewardReferral), ctx, referralID)
+}
+
+// MockCampaignStore is a mock of CampaignStore interface.
+type MockCampaignStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockCampaignStoreMockRecorder
+}
+
+// MockCampaignStoreMockRecorder is the mock recorder for MockCampaignStore.
+type MockCampaignStoreMockRecorder struct {
+	mock *MockCampaignStore
+}
+
+// NewMockCampaignStore creates a new mock instance.
+func NewMockCampaignStore(ctrl *gomock.Controller) *MockCampaignStore {
+	mock := &MockCampaignStore{ctrl: ctrl}
+	mock.recorder = &MockCampaignStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCampaignStore) EXPECT() *MockCampaignStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateCampaign mocks base method.
+func (m *MockCampaignStore) CreateCampaign(ctx context.Context, c models.Campaign) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCampaign", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCampaign indicates an expected call of CreateCampaign.
+func (mr *MockCampaignStoreMockRecorder) CreateCampaign(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCampaign", reflect.TypeOf((*MockCampaignStore)(nil).CreateCampaign), ctx, c)
+}
+
+// EndCampaign mocks base method.
+func (m *MockCampaignStore) EndCampaign(ctx context.Context, campaignID int64, endsAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndCampaign", ctx, campaignID, endsAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndCampaign indicates an expected call of EndCampaign.
+func (mr *MockCampaignStoreMockRecorder) EndCampaign(ctx, campaignID, endsAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndCampaign", reflect.TypeOf((*MockCampaignStore)(nil).EndCampaign), ctx, campaignID, endsAt)
+}
+
+// GetActiveCampaigns mocks base method.
+func (m *MockCampaignStore) GetActiveCampaigns(ctx context.Context, at time.Time) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveCampaigns", ctx, at)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveCampaigns indicates an expected call of GetActiveCampaigns.
+func (mr *MockCampaignStoreMockRecorder) GetActiveCampaigns(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveCampaigns", reflect.TypeOf((*MockCampaignStore)(nil).GetActiveCampaigns), ctx, at)
+}
+
+// GetCampaign mocks base method.
+func (m *MockCampaignStore) GetCampaign(ctx context.Context, campaignID int64, forUpdate bool) (models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaign", ctx, campaignID, forUpdate)
+	ret0, _ := ret[0].(models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaign indicates an expected call of GetCampaign.
+func (mr *MockCampaignStoreMockRecorder) GetCampaign(ctx, campaignID, forUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaign", reflect.TypeOf((*MockCampaignStore)(nil).GetCampaign), ctx, campaignID, forUpdate)
+}
+
+// GetCampaigns mocks base method.
+func (m *MockCampaignStore) GetCampaigns(ctx context.Context) ([]models.Campaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCampaigns", ctx)
+	ret0, _ := ret[0].([]models.Campaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCampaigns indicates an expected call of GetCampaigns.
+func (mr *MockCampaignStoreMockRecorder) GetCampaigns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCampaigns", reflect.TypeOf((*MockCampaignStore)(nil).GetCampaigns), ctx)
+}
+
+// MockWebhookStore is a mock of WebhookStore interface.
+type MockWebhookStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookStoreMockRecorder
+}
+
+// MockWebhookStoreMockRecorder is the mock recorder for MockWebhookStore.
+type MockWebhookStoreMockRecorder struct {
+	mock *MockWebhookStore
+}
+
+// NewMockWebhookStore creates a new mock instance.
+func NewMockWebhookStore(ctrl *gomock.Controller) *MockWebhookStore {
+	mock := &MockWebhookStore{ctrl: ctrl}
+	mock.recorder = &MockWebhookStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookStore) EXPECT() *MockWebhookStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueWebhookDeliveries mocks base method.
+func (m *MockWebhookStore) ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]models.DueDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueWebhookDeliveries", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]models.DueDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueWebhookDeliveries indicates an expected call of ClaimDueWebhookDeliveries.
+func (mr *MockWebhookStoreMockRecorder) ClaimDueWebhookDeliveries(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueWebhookDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).ClaimDueWebhookDeliveries), ctx, now, leaseUntil, limit)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookStore) CreateWebhook(ctx context.Context, userID int64, url, secret string, events []string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, secret, events)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookStoreMockRecorder) CreateWebhook(ctx, userID, url, secret, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookStore)(nil).CreateWebhook), ctx, userID, url, secret, events)
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockWebhookStore) CreateWebhookDeliveries(ctx context.Context, userID int64, event string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ctx, userID, event, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockWebhookStoreMockRecorder) CreateWebhookDeliveries(ctx, userID, event, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).CreateWebhookDeliveries), ctx, userID, event, payload)
+}
+
+// DisableWebhook mocks base method.
+func (m *MockWebhookStore) DisableWebhook(ctx context.Context, webhookID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableWebhook indicates an expected call of DisableWebhook.
+func (mr *MockWebhookStoreMockRecorder) DisableWebhook(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableWebhook", reflect.TypeOf((*MockWebhookStore)(nil).DisableWebhook), ctx, webhookID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookStore) GetWebhook(ctx context.Context, webhookID int64) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookStoreMockRecorder) GetWebhook(ctx, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookStore)(nil).GetWebhook), ctx, webhookID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockWebhookStore) GetWebhookDeliveries(ctx context.Context, webhookID, limit int64) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockWebhookStoreMockRecorder) GetWebhookDeliveries(ctx, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).GetWebhookDeliveries), ctx, webhookID, limit)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookStore) GetWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookStoreMockRecorder) GetWebhooks(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookStore)(nil).GetWebhooks), ctx, userID)
+}
+
+// RequeueWebhookDelivery mocks base method.
+func (m *MockWebhookStore) RequeueWebhookDelivery(ctx context.Context, webhookID, deliveryID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueWebhookDelivery indicates an expected call of RequeueWebhookDelivery.
+func (mr *MockWebhookStoreMockRecorder) RequeueWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueWebhookDelivery", reflect.TypeOf((*MockWebhookStore)(nil).RequeueWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockWebhookStore) UpdateWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockWebhookStoreMockRecorder) UpdateWebhookDelivery(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockWebhookStore)(nil).UpdateWebhookDelivery), ctx, d)
+}
+
+// MockEventStore is a mock of EventStore interface.
+type MockEventStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventStoreMockRecorder
+}
+
+// MockEventStoreMockRecorder is the mock recorder for MockEventStore.
+type MockEventStoreMockRecorder struct {
+	mock *MockEventStore
+}
+
+// NewMockEventStore creates a new mock instance.
+func NewMockEventStore(ctrl *gomock.Controller) *MockEventStore {
+	mock := &MockEventStore{ctrl: ctrl}
+	mock.recorder = &MockEventStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventStore) EXPECT() *MockEventStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingOutboxEvents mocks base method.
+func (m *MockEventStore) ClaimPendingOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int64) ([]models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingOutboxEvents", ctx, now, leaseUntil, limit)
+	ret0, _ := ret[0].([]models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingOutboxEvents indicates an expected call of ClaimPendingOutboxEvents.
+func (mr *MockEventStoreMockRecorder) ClaimPendingOutboxEvents(ctx, now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingOutboxEvents", reflect.TypeOf((*MockEventStore)(nil).ClaimPendingOutboxEvents), ctx, now, leaseUntil, limit)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockEventStore) CreateOutboxEvent(ctx context.Context, eventType string, payload []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, eventType, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockEventStoreMockRecorder) CreateOutboxEvent(ctx, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockEventStore)(nil).CreateOutboxEvent), ctx, eventType, payload)
+}
+
+// DeleteDispatchedOutboxEvents mocks base method.
+func (m *MockEventStore) DeleteDispatchedOutboxEvents(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDispatchedOutboxEvents", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDispatchedOutboxEvents indicates an expected call of DeleteDispatchedOutboxEvents.
+func (mr *MockEventStoreMockRecorder) DeleteDispatchedOutboxEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDispatchedOutboxEvents", reflect.TypeOf((*MockEventStore)(nil).DeleteDispatchedOutboxEvents), ctx, before)
+}
+
+// DeleteUserEvents mocks base method.
+func (m *MockEventStore) DeleteUserEvents(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserEvents", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserEvents indicates an expected call of DeleteUserEvents.
+func (mr *MockEventStoreMockRecorder) DeleteUserEvents(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserEvents", reflect.TypeOf((*MockEventStore)(nil).DeleteUserEvents), ctx, before)
+}
+
+// GetLastUserEventID mocks base method.
+func (m *MockEventStore) GetLastUserEventID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastUserEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastUserEventID indicates an expected call of GetLastUserEventID.
+func (mr *MockEventStoreMockRecorder) GetLastUserEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUserEventID", reflect.TypeOf((*MockEventStore)(nil).GetLastUserEventID), ctx, userID)
+}
+
+// GetUserEvents mocks base method.
+func (m *MockEventStore) GetUserEvents(ctx context.Context, userID, afterID int64, since time.Time, limit int64) ([]models.UserEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID, afterID, since, limit)
+	ret0, _ := ret[0].([]models.UserEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockEventStoreMockRecorder) GetUserEvents(ctx, userID, afterID, since, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockEventStore)(nil).GetUserEvents), ctx, userID, afterID, since, limit)
+}
+
+// MarkOutboxEventsDispatched mocks base method.
+func (m *MockEventStore) MarkOutboxEventsDispatched(ctx context.Context, ids []int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventsDispatched", ctx, ids, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventsDispatched indicates an expected call of MarkOutboxEventsDispatched.
+func (mr *MockEventStoreMockRecorder) MarkOutboxEventsDispatched(ctx, ids, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventsDispatched", reflect.TypeOf((*MockEventStore)(nil).MarkOutboxEventsDispatched), ctx, ids, at)
+}
+
+// MockLedgerStore is a mock of LedgerStore interface.
+type MockLedgerStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerStoreMockRecorder
+}
+
+// MockLedgerStoreMockRecorder is the mock recorder for MockLedgerStore.
+type MockLedgerStoreMockRecorder struct {
+	mock *MockLedgerStore
+}
+
+// NewMockLedgerStore creates a new mock instance.
+func NewMockLedgerStore(ctrl *gomock.Controller) *MockLedgerStore {
+	mock := &MockLedgerStore{ctrl: ctrl}
+	mock.recorder = &MockLedgerStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerStore) EXPECT() *MockLedgerStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateBalanceAdjustment mocks base method.
+func (m *MockLedgerStore) CreateBalanceAdjustment(ctx context.Context, userID int64, amount float64, reason string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceAdjustment", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceAdjustment indicates an expected call of CreateBalanceAdjustment.
+func (mr *MockLedgerStoreMockRecorder) CreateBalanceAdjustment(ctx, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceAdjustment", reflect.TypeOf((*MockLedgerStore)(nil).CreateBalanceAdjustment), ctx, userID, amount, reason)
+}
+
+// CreateBalanceCorrection mocks base method.
+func (m *MockLedgerStore) CreateBalanceCorrection(ctx context.Context, userID int64, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceCorrection", ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBalanceCorrection indicates an expected call of CreateBalanceCorrection.
+func (mr *MockLedgerStoreMockRecorder) CreateBalanceCorrection(ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceCorrection", reflect.TypeOf((*MockLedgerStore)(nil).CreateBalanceCorrection), ctx, userID, balanceBefore, balanceAfter, withdrawnBefore, withdrawnAfter)
+}
+
+// GetBalanceMismatches mocks base method.
+func (m *MockLedgerStore) GetBalanceMismatches(ctx context.Context, tolerance float64) ([]models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceMismatches", ctx, tolerance)
+	ret0, _ := ret[0].([]models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceMismatches indicates an expected call of GetBalanceMismatches.
+func (mr *MockLedgerStoreMockRecorder) GetBalanceMismatches(ctx, tolerance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceMismatches", reflect.TypeOf((*MockLedgerStore)(nil).GetBalanceMismatches), ctx, tolerance)
+}
+
+// GetStatement mocks base method.
+func (m *MockLedgerStore) GetStatement(ctx context.Context, userID int64, from, to time.Time, after models.StatementCursor, limit int64, fn func(models.StatementEntry) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatement", ctx, userID, from, to, after, limit, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetStatement indicates an expected call of GetStatement.
+func (mr *MockLedgerStoreMockRecorder) GetStatement(ctx, userID, from, to, after, limit, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatement", reflect.TypeOf((*MockLedgerStore)(nil).GetStatement), ctx, userID, from, to, after, limit, fn)
+}
+
+// GetUserLedger mocks base method.
+func (m *MockLedgerStore) GetUserLedger(ctx context.Context, userID int64) (models.BalanceMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserLedger", ctx, userID)
+	ret0, _ := ret[0].(models.BalanceMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserLedger indicates an expected call of GetUserLedger.
+func (mr *MockLedgerStoreMockRecorder) GetUserLedger(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLedger", reflect.TypeOf((*MockLedgerStore)(nil).GetUserLedger), ctx, userID)
+}
+
+// MockRateLimitStore is a mock of RateLimitStore interface.
+type MockRateLimitStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitStoreMockRecorder
+}
+
+// MockRateLimitStoreMockRecorder is the mock recorder for MockRateLimitStore.
+type MockRateLimitStoreMockRecorder struct {
+	mock *MockRateLimitStore
+}
+
+// NewMockRateLimitStore creates a new mock instance.
+func NewMockRateLimitStore(ctrl *gomock.Controller) *MockRateLimitStore {
+	mock := &MockRateLimitStore{ctrl: ctrl}
+	mock.recorder = &MockRateLimitStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimitStore) EXPECT() *MockRateLimitStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredRateLimits mocks base method.
+func (m *MockRateLimitStore) DeleteExpiredRateLimits(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRateLimits", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredRateLimits indicates an expected call of DeleteExpiredRateLimits.
+func (mr *MockRateLimitStoreMockRecorder) DeleteExpiredRateLimits(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRateLimits", reflect.TypeOf((*MockRateLimitStore)(nil).DeleteExpiredRateLimits), ctx)
+}
+
+// TakeRateLimit mocks base method.
+func (m *MockRateLimitStore) TakeRateLimit(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeRateLimit", ctx, key, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TakeRateLimit indicates an expected call of TakeRateLimit.
+func (mr *MockRateLimitStoreMockRecorder) TakeRateLimit(ctx, key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeRateLimit", reflect.TypeOf((*MockRateLimitStore)(nil).TakeRateLimit), ctx, key, window)
+}